@@ -0,0 +1,32 @@
+// Package main provides the commit-msg-lint-commitmsg binary, which runs commit-msg-lint
+// explicitly as a git commit-msg hook, bypassing the auto-detection in the main binary.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	app "github.com/breml/githooks/internal/hooks/commitmsg"
+)
+
+const (
+	// exitViolation is the exit code for commit messages violating the rules.
+	exitViolation = 1
+	// exitConfig is the exit code for invalid configuration or setup.
+	exitConfig = 2
+)
+
+func main() {
+	err := app.RunCommitMsgHook(os.Args)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+
+		var violationErr *app.ViolationError
+		if errors.As(err, &violationErr) {
+			os.Exit(exitViolation)
+		}
+
+		os.Exit(exitConfig)
+	}
+}
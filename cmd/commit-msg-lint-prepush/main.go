@@ -3,16 +3,30 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	app "github.com/breml/githooks/internal/hooks/commitmsg"
 )
 
+const (
+	// exitViolation is the exit code for commit messages violating the rules.
+	exitViolation = 1
+	// exitConfig is the exit code for invalid configuration or setup.
+	exitConfig = 2
+)
+
 func main() {
 	err := app.RunPrePushHook(os.Stdin, os.Args)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+
+		var violationErr *app.ViolationError
+		if errors.As(err, &violationErr) {
+			os.Exit(exitViolation)
+		}
+
+		os.Exit(exitConfig)
 	}
 }
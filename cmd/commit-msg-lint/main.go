@@ -2,16 +2,33 @@
 package main
 
 import (
-	"fmt"
+	"errors"
 	"os"
 
 	app "github.com/breml/githooks/internal/hooks/commitmsg"
 )
 
+const (
+	// exitViolation is the exit code for commit messages violating the rules.
+	exitViolation = 1
+	// exitConfig is the exit code for invalid arguments, configuration, or setup.
+	exitConfig = 2
+)
+
 func main() {
-	err := app.Run(os.Stdin, os.Args)
+	err := app.RunWith(app.RunOptions{
+		Args:        os.Args,
+		Stdin:       os.Stdin,
+		Stdout:      os.Stdout,
+		Stderr:      os.Stderr,
+		ErrorPrefix: "Error: ",
+	})
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		var violationErr *app.ViolationError
+		if errors.As(err, &violationErr) {
+			os.Exit(exitViolation)
+		}
+
+		os.Exit(exitConfig)
 	}
 }
@@ -0,0 +1,546 @@
+package commitmsg
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// causedByRegex matches a "Caused-by: <hash>" trailer and captures the hash.
+var causedByRegex = regexp.MustCompile(`(?im)^Caused-by:\s*([0-9a-f]{7,40})\s*$`)
+
+// checkFixReferencesCause checks that a fix commit (case-insensitive, like
+// applies_to) references the commit that introduced the bug in a "Caused-by"
+// footer trailer. If the rule has verify set
+// and a repository is available, the referenced commit must exist.
+// Returns a description of the violation or an empty string.
+func checkFixReferencesCause(rule Rule, message ParsedCommitMessage, ctx ruleContext) string {
+	if !strings.EqualFold(message.CCType, "fix") {
+		return ""
+	}
+
+	match := causedByRegex.FindStringSubmatch(message.Footer)
+	if match == nil {
+		return "Fix commit has no \"Caused-by: <hash>\" trailer in footer"
+	}
+
+	if rule.Verify && ctx.repo != nil {
+		_, err := resolveRefOrSHA(ctx.repo, match[1])
+		if err != nil {
+			return fmt.Sprintf("Commit %s referenced by Caused-by trailer does not exist", match[1])
+		}
+	}
+
+	return ""
+}
+
+// checkAuthorEmailAllowlist checks that the commit author email is in the
+// allowlist of the rule. The check is skipped if no commit is available
+// (e.g. commit-msg hook mode, where the author is not yet known).
+// Returns a description of the violation or an empty string.
+func checkAuthorEmailAllowlist(rule Rule, ctx ruleContext) string {
+	if ctx.commit == nil {
+		return ""
+	}
+
+	email := ctx.commit.Author.Email
+	if _, ok := rule.allowlist[strings.ToLower(email)]; ok {
+		return ""
+	}
+
+	return fmt.Sprintf("Author email %q is not in the allowlist", email)
+}
+
+// signedOffByTrailerKey is the trailer key of the Developer Certificate of Origin
+// sign-off checked by dco rules.
+const signedOffByTrailerKey = "Signed-off-by"
+
+// checkDCO checks that a Signed-off-by trailer names the commit author or
+// committer, according to the signer of the rule, with the same name and email.
+// Only the email is compared case-insensitively, the name must match exactly like
+// in the sign-off added by git commit -s. The rule is skipped without a commit,
+// e.g. in commit-msg hook mode. Returns a description with the expected and the
+// found sign-offs or an empty string.
+func checkDCO(rule Rule, message ParsedCommitMessage, ctx ruleContext) string {
+	if ctx.commit == nil {
+		return ""
+	}
+
+	signature := ctx.commit.Author
+	if rule.Signer == signerCommitter {
+		signature = ctx.commit.Committer
+	}
+
+	expected := fmt.Sprintf("%s <%s>", signature.Name, signature.Email)
+
+	signOffs := message.TrailerValues(signedOffByTrailerKey)
+	if len(signOffs) == 0 {
+		return fmt.Sprintf("No %s trailer found, expected %q", signedOffByTrailerKey, expected)
+	}
+
+	for _, signOff := range signOffs {
+		name, email, ok := parseIdentity(signOff)
+		if ok && name == signature.Name && strings.EqualFold(email, signature.Email) {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("%s %q does not match the commit %s, expected %q",
+		signedOffByTrailerKey, strings.Join(signOffs, ", "), rule.Signer, expected)
+}
+
+// parseIdentity splits an identity in the form "Name <email>" into its name and
+// email. It reports false if the identity has no email in angle brackets.
+func parseIdentity(identity string) (string, string, bool) {
+	start := strings.LastIndex(identity, "<")
+	end := strings.LastIndex(identity, ">")
+
+	if start < 0 || end < start {
+		return "", "", false
+	}
+
+	return strings.TrimSpace(identity[:start]), strings.TrimSpace(identity[start+1 : end]), true
+}
+
+// checkNoMergeCommits checks that the commit has at most one parent. The rule is
+// skipped without a commit, e.g. in commit-msg hook mode. Returns a description
+// of the merge commit or an empty string.
+func checkNoMergeCommits(ctx ruleContext) string {
+	if ctx.commit == nil || ctx.commit.NumParents() <= 1 {
+		return ""
+	}
+
+	return fmt.Sprintf("Commit is a merge commit with %d parents", ctx.commit.NumParents())
+}
+
+// checkCCLowercase checks that the Conventional Commits type of the title has no
+// uppercase letters. Titles that are not Conventional Commits shaped are not
+// checked. Returns a description of the offending type or an empty string.
+func checkCCLowercase(message ParsedCommitMessage) string {
+	lower := strings.ToLower(message.CCType)
+	if message.CCType == lower {
+		return ""
+	}
+
+	return fmt.Sprintf("Type %q must be lowercase, e.g. %q", message.CCType, lower)
+}
+
+// checkNoTrailingWhitespace checks that no line of the raw message ends with
+// whitespace, or starts with it if leading whitespace is forbidden by the rule.
+// The raw lines are checked, as the parser trims the sections.
+// Returns a description of the first offending line or an empty string.
+func checkNoTrailingWhitespace(rule Rule, message ParsedCommitMessage) string {
+	lineNum := 0
+	for line := range strings.SplitSeq(message.Raw, "\n") {
+		lineNum++
+
+		if strings.TrimRightFunc(line, unicode.IsSpace) != line {
+			return fmt.Sprintf("Line %d ends with whitespace: %q", lineNum, line)
+		}
+
+		if rule.ForbidLeadingWhitespace && strings.TrimLeftFunc(line, unicode.IsSpace) != line {
+			return fmt.Sprintf("Line %d starts with whitespace: %q", lineNum, line)
+		}
+	}
+
+	return ""
+}
+
+// checkNoRepeatedWords checks that no word in the scope of the rule is
+// immediately followed by the same word (case-insensitive), e.g. "fix fix bug".
+// Punctuation around words is ignored, but a word followed by punctuation ending a
+// sentence or clause (e.g. "done. Done") is not considered repeated.
+// Returns a description of the violation or an empty string.
+func checkNoRepeatedWords(rule Rule, message ParsedCommitMessage) string {
+	previous := ""
+
+	for field := range strings.FieldsSeq(getTextForScope(rule.Scope, message)) {
+		word := strings.TrimFunc(field, isNotWordChar)
+		if !strings.ContainsFunc(word, unicode.IsLetter) {
+			// Numbers and punctuation only are not considered words
+			previous = ""
+			continue
+		}
+
+		if previous != "" && strings.EqualFold(word, previous) {
+			return fmt.Sprintf("Word %q is repeated in %s", word, rule.Scope)
+		}
+
+		previous = word
+
+		trailing := field[len(strings.TrimRightFunc(field, isNotWordChar)):]
+		if strings.ContainsAny(trailing, ".,;:!?") {
+			previous = ""
+		}
+	}
+
+	return ""
+}
+
+// isNotWordChar reports whether r is neither a letter nor a digit.
+func isNotWordChar(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// checkRevertRequiresApproval checks that a revert commit (title starting with
+// `Revert "`, as generated by git revert) pushed to one of the protected branches
+// of the rule has a non-empty approval trailer in the footer.
+// Returns a description of the violation or an empty string.
+func checkRevertRequiresApproval(rule Rule, message ParsedCommitMessage, ctx ruleContext) string {
+	if !strings.HasPrefix(message.Title, `Revert "`) {
+		return ""
+	}
+
+	protected := false
+	for _, branch := range rule.ProtectedBranches {
+		if refMatches(branch, ctx.targetBranch) {
+			protected = true
+			break
+		}
+	}
+
+	if !protected || hasTrailer(message, rule.TrailerKey) {
+		return ""
+	}
+
+	return fmt.Sprintf("Revert on protected branch %s has no %q trailer in footer", ctx.targetBranch, rule.TrailerKey)
+}
+
+// checkRequireTrailer checks that a commit whose title matches the title pattern
+// of the rule, if set, has a trailer with the key of the rule (case-insensitive)
+// and that each of its values matches the value pattern, if set.
+// Returns a description of the violation or an empty string.
+func checkRequireTrailer(rule Rule, message ParsedCommitMessage) string {
+	if rule.regex != nil && !rule.regex.MatchString(message.Title) {
+		return ""
+	}
+
+	if !hasTrailer(message, rule.TrailerKey) {
+		return fmt.Sprintf("No %q trailer found", rule.TrailerKey)
+	}
+
+	if rule.valueRegex == nil {
+		return ""
+	}
+
+	for _, value := range message.TrailerValues(rule.TrailerKey) {
+		if !rule.valueRegex.MatchString(value) {
+			return fmt.Sprintf("%s trailer %q does not match value_pattern %q", rule.TrailerKey, value, rule.ValuePattern)
+		}
+	}
+
+	return ""
+}
+
+// checkMaxTrailerRepeats checks that no trailer key (case-insensitive) occurs
+// more often than the limit of the rule.
+// Returns a description of the violation or an empty string.
+func checkMaxTrailerRepeats(rule Rule, message ParsedCommitMessage) string {
+	counts := make(map[string]int)
+
+	// Keys are reported as written in their first occurrence
+	var keys []string
+
+	for _, trailer := range message.Trailers {
+		key := strings.ToLower(trailer.Key)
+		if counts[key] == 0 {
+			keys = append(keys, trailer.Key)
+		}
+
+		counts[key]++
+	}
+
+	for _, key := range keys {
+		count := counts[strings.ToLower(key)]
+		if count > rule.Limit {
+			return fmt.Sprintf("Trailer %q occurs %d times (at most %d allowed)", key, count, rule.Limit)
+		}
+	}
+
+	return ""
+}
+
+// hasTrailer reports whether the message has a trailer with the given key
+// (case-insensitive) and a non-empty value.
+func hasTrailer(message ParsedCommitMessage, key string) bool {
+	for _, value := range message.TrailerValues(key) {
+		if value != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkNoQuestionSubject checks that the title is not phrased as a question,
+// i.e. does not match the pattern of the rule (by default ending with "?").
+// Returns a description of the violation or an empty string.
+func checkNoQuestionSubject(rule Rule, message ParsedCommitMessage) string {
+	if !rule.regex.MatchString(message.Title) {
+		return ""
+	}
+
+	return fmt.Sprintf("Title %q is phrased as a question", message.Title)
+}
+
+// checkSubjectNotBranchName checks that the title does not merely restate the
+// name of the branch the commit is validated for. The title (or Conventional
+// Commits description) and the descriptive part of the branch name, i.e. the part
+// after the last "/", are compared as lowercase words. A title starting with the
+// full branch name, like "feature/login: login", is compared without it.
+// Returns a description of the violation or an empty string.
+func checkSubjectNotBranchName(rule Rule, message ParsedCommitMessage, ctx ruleContext) string {
+	if ctx.branch == "" {
+		return ""
+	}
+
+	branchWords := normalizedWords(ctx.branch)
+	descriptive := ctx.branch[strings.LastIndex(ctx.branch, "/")+1:]
+	descriptiveWords := normalizedWords(descriptive)
+	if len(descriptiveWords) == 0 {
+		return ""
+	}
+
+	subject := cmp.Or(message.CCDescription, message.Title)
+	subjectWords := normalizedWords(subject)
+
+	restated := slices.Equal(subjectWords, branchWords)
+
+	// Compare without the branch name restated as a prefix
+	remaining := subjectWords
+	if len(subjectWords) > len(branchWords) && slices.Equal(subjectWords[:len(branchWords)], branchWords) {
+		remaining = subjectWords[len(branchWords):]
+	}
+
+	switch rule.Strictness {
+	case strictnessContains:
+		restated = restated || containsWords(remaining, descriptiveWords)
+
+	default:
+		restated = restated || slices.Equal(remaining, descriptiveWords)
+	}
+
+	if !restated {
+		return ""
+	}
+
+	return fmt.Sprintf("Title %q restates branch name %q", message.Title, ctx.branch)
+}
+
+// normalizedWords splits s into lowercase words of letters and digits.
+func normalizedWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), isNotWordChar)
+}
+
+// containsWords reports whether words contains sub as a contiguous sequence.
+func containsWords(words []string, sub []string) bool {
+	for i := 0; i+len(sub) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(sub)], sub) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkMinLength checks that the text of the scope of the rule has at least
+// limit characters. Empty text has length zero and fails.
+// Returns a description of the violation or an empty string.
+func checkMinLength(rule Rule, message ParsedCommitMessage) string {
+	length := utf8.RuneCountInString(getTextForScope(rule.Scope, message))
+	if length >= rule.Limit {
+		return ""
+	}
+
+	return fmt.Sprintf("Length of %s is %d characters, minimum is %d", rule.Scope, length, rule.Limit)
+}
+
+// checkMaxWords checks that the text of the scope of the rule has at most limit
+// words, i.e. whitespace-separated fields.
+// Returns a description of the violation or an empty string.
+func checkMaxWords(rule Rule, message ParsedCommitMessage) string {
+	words := len(strings.Fields(getTextForScope(rule.Scope, message)))
+	if words <= rule.Limit {
+		return ""
+	}
+
+	return fmt.Sprintf("Word count of %s is %d, maximum is %d", rule.Scope, words, rule.Limit)
+}
+
+// checkMinWords checks that the text of the scope of the rule has at least limit
+// words, i.e. whitespace-separated fields. Empty text is not checked, e.g. a
+// missing body.
+// Returns a description of the violation or an empty string.
+func checkMinWords(rule Rule, message ParsedCommitMessage) string {
+	words := len(strings.Fields(getTextForScope(rule.Scope, message)))
+	if words == 0 || words >= rule.Limit {
+		return ""
+	}
+
+	return fmt.Sprintf("Word count of %s is %d, minimum is %d", rule.Scope, words, rule.Limit)
+}
+
+// checkMaxBodyLines checks that the body has at most limit non-empty lines, so
+// the blank lines separating its paragraphs are not counted.
+// Returns a description of the violation or an empty string.
+func checkMaxBodyLines(rule Rule, message ParsedCommitMessage) string {
+	lines := 0
+	for line := range strings.SplitSeq(message.Body, "\n") {
+		if !isEmptyLine(line) {
+			lines++
+		}
+	}
+
+	if lines <= rule.Limit {
+		return ""
+	}
+
+	return fmt.Sprintf("Body has %d non-empty lines, maximum is %d", lines, rule.Limit)
+}
+
+// checkRequireBodyWhen checks that a commit whose title matches the title
+// pattern of the rule has a body. As the last paragraph of a message is parsed as
+// the footer, a footer without any trailers counts as body as well.
+// Returns a description of the violation or an empty string.
+func checkRequireBodyWhen(rule Rule, message ParsedCommitMessage) string {
+	if !rule.regex.MatchString(message.Title) {
+		return ""
+	}
+
+	if strings.TrimSpace(message.Body) != "" || (message.Footer != "" && len(message.Trailers) == 0) {
+		return ""
+	}
+
+	return fmt.Sprintf("Title %q matches title_pattern %q, but the body is empty", message.Title, rule.TitlePattern)
+}
+
+// checkImperativeSubject checks that the first word of the title (or of the
+// Conventional Commits description) is not a past tense or gerund verb, i.e. does
+// not end with "ed" or "ing". Common imperative verbs with these endings and the
+// words of the allow list of the rule are accepted.
+// Returns a description of the violation or an empty string.
+func checkImperativeSubject(rule Rule, message ParsedCommitMessage) string {
+	text := message.Title
+	if message.CCType != "" {
+		text = message.CCDescription
+	}
+
+	words := strings.FieldsFunc(text, isNotWordChar)
+	if len(words) == 0 {
+		return ""
+	}
+
+	word := words[0]
+	lower := strings.ToLower(word)
+
+	if _, ok := rule.allowedWords[lower]; ok || isImperativeVerb(lower) {
+		return ""
+	}
+
+	if !strings.HasSuffix(lower, "ed") && !strings.HasSuffix(lower, "ing") {
+		return ""
+	}
+
+	return fmt.Sprintf("First word %q of the title is not in imperative mood", word)
+}
+
+// isImperativeVerb reports whether the lowercased word is a common imperative
+// verb ending with "ed" or "ing".
+func isImperativeVerb(word string) bool {
+	switch word {
+	case "bring", "embed", "exceed", "feed", "need", "proceed", "seed", "shed", "speed", "succeed":
+		return true
+
+	default:
+		return false
+	}
+}
+
+// checkNoTrailingPunctuation checks that the title does not end with one of the
+// punctuation characters of the rule. Trailing whitespace is ignored.
+// Returns a description of the violation or an empty string.
+func checkNoTrailingPunctuation(rule Rule, message ParsedCommitMessage) string {
+	title := strings.TrimRightFunc(message.Title, unicode.IsSpace)
+	if title == "" {
+		return ""
+	}
+
+	last, _ := utf8.DecodeLastRuneInString(title)
+	if !strings.ContainsRune(rule.Punctuation, last) {
+		return ""
+	}
+
+	return fmt.Sprintf("Title ends with %q", last)
+}
+
+// checkRequireIssueRef checks that the text of the scope of the rule contains an
+// issue reference matching the prefix pattern of the rule as a whole word.
+// Returns a description of the violation or an empty string.
+func checkRequireIssueRef(rule Rule, message ParsedCommitMessage) string {
+	if rule.regex.MatchString(getTextForScope(rule.Scope, message)) {
+		return ""
+	}
+
+	return fmt.Sprintf("No issue reference matching %q was found in %s", rule.PrefixPattern, rule.Scope)
+}
+
+// checkCharset checks that every character of the text of the scope of the rule
+// matches one of its allowed character classes. Line breaks are always allowed.
+// Returns a description of the first disallowed character or an empty string.
+func checkCharset(rule Rule, message ParsedCommitMessage) string {
+	for offset, r := range getTextForScope(rule.Scope, message) {
+		if r == '\n' || rule.regex.MatchString(string(r)) {
+			continue
+		}
+
+		return fmt.Sprintf("Character %q (U+%04X) at offset %d of %s is not allowed", r, r, offset, rule.Scope)
+	}
+
+	return ""
+}
+
+// checkBodyWrap checks that no line of the body is longer than the limit of the
+// rule, except for the lines matching its ignore pattern. The footer, including
+// its trailers, is not checked. Returns a description of the first over-long line
+// with its line number in the message or an empty string.
+func checkBodyWrap(rule Rule, message ParsedCommitMessage) string {
+	starts := sectionStartLines(message.Raw)
+
+	for i, section := range message.BodySections {
+		// The body sections follow the title section
+		start := starts[i+1]
+
+		for offset, line := range strings.Split(section, "\n") {
+			length := utf8.RuneCountInString(line)
+			if length <= rule.Limit || (rule.regex != nil && rule.regex.MatchString(line)) {
+				continue
+			}
+
+			return fmt.Sprintf("Line %d has %d characters, maximum is %d", start+offset, length, rule.Limit)
+		}
+	}
+
+	return ""
+}
+
+// checkBlankLineAfterTitle checks that the second line of the raw message is
+// empty if the message has more than one line. The parser splits sections at
+// empty lines, so a body directly following the title would be part of the title
+// section. Returns a description of the offending line or an empty string.
+func checkBlankLineAfterTitle(message ParsedCommitMessage) string {
+	_, rest, found := strings.Cut(message.Raw, "\n")
+	if !found {
+		return ""
+	}
+
+	secondLine, _, _ := strings.Cut(rest, "\n")
+	if isEmptyLine(secondLine) {
+		return ""
+	}
+
+	return fmt.Sprintf("Line 2 must be empty, got %q", secondLine)
+}
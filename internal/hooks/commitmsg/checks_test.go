@@ -0,0 +1,477 @@
+package commitmsg_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/breml/githooks/internal/hooks/commitmsg"
+)
+
+// addCommit is a test helper that commits a change to a new file in the
+// repository at dir with the given message and returns the commit hash.
+func addCommit(t *testing.T, dir string, repo *git.Repository, message string) plumbing.Hash {
+	t.Helper()
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	filename := fmt.Sprintf("file-%d.txt", time.Now().UnixNano())
+	err = os.WriteFile(filepath.Join(dir, filename), []byte(message), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	_, err = worktree.Add(filename)
+	if err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "Test User",
+			Email: "test@example.com",
+			When:  time.Now(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	return hash
+}
+
+func TestFixReferencesCause(t *testing.T) {
+	const config = `rules:
+  - name: fix-cause
+    type: fix_references_cause
+    verify: true
+`
+
+	tests := []struct {
+		name    string
+		message func(cause plumbing.Hash) string
+		wantErr bool
+	}{
+		{
+			name: "fix with valid Caused-by trailer",
+			message: func(cause plumbing.Hash) string {
+				return "fix: handle nil config\n\nCaused-by: " + cause.String()[:12]
+			},
+			wantErr: false,
+		},
+		{
+			name: "fix with scope and full hash",
+			message: func(cause plumbing.Hash) string {
+				return "fix(parser): handle nil config\n\nCaused-by: " + cause.String()
+			},
+			wantErr: false,
+		},
+		{
+			name: "fix without Caused-by trailer",
+			message: func(_ plumbing.Hash) string {
+				return "fix: handle nil config\n\nSigned-off-by: Dev <dev@example.com>"
+			},
+			wantErr: true,
+		},
+		{
+			name: "fix referencing unknown commit",
+			message: func(_ plumbing.Hash) string {
+				return "fix: handle nil config\n\nCaused-by: deadbeefdeadbeef"
+			},
+			wantErr: true,
+		},
+		{
+			name: "capitalized fix type without Caused-by trailer",
+			message: func(_ plumbing.Hash) string {
+				return "Fix: handle nil config"
+			},
+			wantErr: true,
+		},
+		{
+			name: "non-fix commit is not checked",
+			message: func(_ plumbing.Hash) string {
+				return "feat: add config"
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, repo, hashes := createTestRepo(t, []commit{
+				{message: "feat: add config loading", files: map[string]string{"file1.txt": "content1"}},
+			})
+			writeConfigFile(t, tmpDir, config)
+			t.Chdir(tmpDir)
+
+			fixHash := addCommit(t, tmpDir, repo, testCase.message(hashes[0]))
+
+			input := fmt.Sprintf("refs/heads/feature %s refs/heads/feature %s\n", fixHash.String(), gitZeroHash)
+
+			err := commitmsg.Run(strings.NewReader(input), nil)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), "[fix-cause]") {
+				t.Errorf("Run() error = %q, want it to mention rule fix-cause", err.Error())
+			}
+		})
+	}
+}
+
+func TestAuthorEmailAllowlist(t *testing.T) {
+	const config = `rules:
+  - name: allowed-authors
+    type: author_email_allowlist
+    allowlist_file: allowed-emails.txt
+`
+
+	tests := []struct {
+		name      string
+		allowlist string
+		wantErr   bool
+	}{
+		{
+			name:      "allowlisted author email",
+			allowlist: "# Permitted authors\nalice@example.com\n\nTEST@example.com\n",
+			wantErr:   false,
+		},
+		{
+			name:      "author email not allowlisted",
+			allowlist: "alice@example.com\nbob@example.com\n",
+			wantErr:   true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, _, hashes := createTestRepo(t, []commit{
+				{message: "feat: add feature", files: map[string]string{"file1.txt": "content1"}},
+			})
+			writeConfigFile(t, tmpDir, config)
+
+			err := os.WriteFile(filepath.Join(tmpDir, "allowed-emails.txt"), []byte(testCase.allowlist), 0o644)
+			if err != nil {
+				t.Fatalf("failed to write allowlist: %v", err)
+			}
+
+			t.Chdir(tmpDir)
+
+			input := fmt.Sprintf("refs/heads/feature %s refs/heads/feature %s\n", hashes[0].String(), gitZeroHash)
+
+			err = commitmsg.Run(strings.NewReader(input), nil)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), `"test@example.com" is not in the allowlist`) {
+				t.Errorf("Run() error = %q, want it to name the author email", err.Error())
+			}
+		})
+	}
+}
+
+func TestDCO(t *testing.T) {
+	tests := []struct {
+		name        string
+		signer      string
+		message     string
+		errContains string
+	}{
+		{
+			name:    "sign-off of the author",
+			signer:  "",
+			message: "feat: add feature\n\nSigned-off-by: Test User <test@example.com>",
+		},
+		{
+			name:    "email is compared case-insensitively",
+			signer:  "",
+			message: "feat: add feature\n\nSigned-off-by: Test User <Test@Example.com>",
+		},
+		{
+			name:        "name is compared case-sensitively",
+			signer:      "",
+			message:     "feat: add feature\n\nSigned-off-by: test user <test@example.com>",
+			errContains: `Signed-off-by "test user <test@example.com>" does not match the commit author`,
+		},
+		{
+			name:    "one of several sign-offs matches",
+			signer:  "committer",
+			message: "feat: add feature\n\nSigned-off-by: Alice <alice@example.com>\nSigned-off-by: Test User <test@example.com>",
+		},
+		{
+			name:        "sign-off of someone else",
+			signer:      "",
+			message:     "feat: add feature\n\nSigned-off-by: Alice <alice@example.com>",
+			errContains: `Signed-off-by "Alice <alice@example.com>" does not match the commit author, expected "Test User <test@example.com>"`,
+		},
+		{
+			name:        "missing sign-off",
+			signer:      "",
+			message:     "feat: add feature",
+			errContains: `No Signed-off-by trailer found, expected "Test User <test@example.com>"`,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, repo, _ := createTestRepo(t, nil)
+			writeConfigFile(t, tmpDir, "rules:\n  - name: dco\n    type: dco\n    signer: '"+testCase.signer+"'\n")
+			t.Chdir(tmpDir)
+
+			hash := addCommit(t, tmpDir, repo, testCase.message)
+
+			input := fmt.Sprintf("refs/heads/feature %s refs/heads/feature %s\n", hash.String(), gitZeroHash)
+
+			err := commitmsg.Run(strings.NewReader(input), nil)
+			if (err != nil) != (testCase.errContains != "") {
+				t.Fatalf("Run() error = %v, want error %q", err, testCase.errContains)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), testCase.errContains) {
+				t.Errorf("Run() error = %q, want it to contain %q", err.Error(), testCase.errContains)
+			}
+		})
+	}
+}
+
+func TestNoMergeCommits(t *testing.T) {
+	const rule = `  - name: linear-history
+    type: no_merge_commits
+`
+
+	// Only the main branch requires a linear history, all branches Conventional Commits titles
+	const overrideConfig = `rules:
+  - name: cc-title
+    type: require
+    scope: title
+    pattern: '^(feat|fix)'
+overrides:
+  - refs: [main]
+    rules:
+      - name: linear-history
+        type: no_merge_commits
+`
+
+	tests := []struct {
+		name        string
+		config      string
+		ref         string
+		errContains string
+	}{
+		{
+			name:        "merge commit is rejected",
+			config:      defaultWIPConfig + rule,
+			ref:         "refs/heads/feature",
+			errContains: "Commit is a merge commit with 2 parents",
+		},
+		{
+			name:        "merge commit is skipped without the rule",
+			config:      defaultWIPConfig,
+			ref:         "refs/heads/feature",
+			errContains: "",
+		},
+		{
+			name:        "merge commit is rejected with skip_merge_commits",
+			config:      defaultWIPConfig + rule + "settings:\n  skip_merge_commits: true\n",
+			ref:         "refs/heads/feature",
+			errContains: "Commit is a merge commit with 2 parents",
+		},
+		{
+			name:        "other rules skip merge commits on refs without the override",
+			config:      overrideConfig,
+			ref:         "refs/heads/feature",
+			errContains: "",
+		},
+		{
+			name:        "only the override rejects merge commits on its refs",
+			config:      overrideConfig,
+			ref:         "refs/heads/main",
+			errContains: "[linear-history]",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, repo, hashes := createTestRepo(t, []commit{
+				{message: "feat: add feature", files: map[string]string{"file1.txt": "content1"}},
+				{message: "feat: add another feature", files: map[string]string{"file2.txt": "content2"}},
+			})
+			writeConfigFile(t, tmpDir, testCase.config)
+			t.Chdir(tmpDir)
+
+			worktree, err := repo.Worktree()
+			if err != nil {
+				t.Fatalf("failed to get worktree: %v", err)
+			}
+
+			mergeHash, err := worktree.Commit("Merge branch 'feature'", &git.CommitOptions{
+				AllowEmptyCommits: true,
+				Author:            &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+				Parents:           []plumbing.Hash{hashes[1], hashes[0]},
+			})
+			if err != nil {
+				t.Fatalf("failed to create merge commit: %v", err)
+			}
+
+			// The remote ref is at the first parent, a new main ref would not be validated
+			input := fmt.Sprintf("%s %s %s %s\n", testCase.ref, mergeHash.String(), testCase.ref, hashes[1].String())
+
+			err = commitmsg.Run(strings.NewReader(input), nil)
+			if (err != nil) != (testCase.errContains != "") {
+				t.Fatalf("Run() error = %v, want error %q", err, testCase.errContains)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), testCase.errContains) {
+				t.Errorf("Run() error = %q, want it to contain %q", err.Error(), testCase.errContains)
+			}
+
+			if err != nil && strings.Contains(err.Error(), "[cc-title]") {
+				t.Errorf("Run() error = %q, want the merge commit to be skipped by cc-title", err.Error())
+			}
+		})
+	}
+}
+
+func TestRevertRequiresApproval(t *testing.T) {
+	const config = `rules:
+  - name: revert-approval
+    type: revert_requires_approval
+    protected_branches: [main, refs/heads/release]
+`
+
+	tests := []struct {
+		name      string
+		message   string
+		remoteRef string
+		wantErr   bool
+	}{
+		{
+			name:      "revert to main without approval",
+			message:   "Revert \"feat: add feature\"\n\nThis reverts commit 0123456789abcdef0123456789abcdef01234567.",
+			remoteRef: "refs/heads/main",
+			wantErr:   true,
+		},
+		{
+			name: "revert to main with approval",
+			message: "Revert \"feat: add feature\"\n\nThis reverts commit 0123456789abcdef0123456789abcdef01234567.\n\n" +
+				"Approved-by: Jane Doe <jane@example.com>",
+			remoteRef: "refs/heads/main",
+			wantErr:   false,
+		},
+		{
+			name:      "revert to release without approval",
+			message:   "Revert \"feat: add feature\"",
+			remoteRef: "refs/heads/release",
+			wantErr:   true,
+		},
+		{
+			name:      "revert on feature branch is exempt",
+			message:   "Revert \"feat: add feature\"",
+			remoteRef: "refs/heads/feature",
+			wantErr:   false,
+		},
+		{
+			name:      "non-revert commit to main",
+			message:   "feat: add feature",
+			remoteRef: "refs/heads/main",
+			wantErr:   false,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, _, hashes := createTestRepo(t, []commit{
+				{message: testCase.message, files: map[string]string{"file1.txt": "content1"}},
+			})
+			writeConfigFile(t, tmpDir, config)
+			t.Chdir(tmpDir)
+
+			input := fmt.Sprintf("refs/heads/local %s %s %s\n", hashes[0].String(), testCase.remoteRef, gitZeroHash)
+
+			err := commitmsg.Run(strings.NewReader(input), nil)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), `no "Approved-by" trailer`) {
+				t.Errorf("Run() error = %q, want it to name the missing trailer", err.Error())
+			}
+		})
+	}
+}
+
+func TestSubjectNotBranchName(t *testing.T) {
+	tests := []struct {
+		name       string
+		strictness string
+		branch     string
+		message    string
+		wantErr    bool
+	}{
+		{
+			name:       "subject echoing branch name",
+			strictness: "exact",
+			branch:     "feature/login",
+			message:    "feature/login: login",
+			wantErr:    true,
+		},
+		{
+			name:       "conventional commit echoing descriptive part",
+			strictness: "exact",
+			branch:     "feature/add-login",
+			message:    "feat: Add login",
+			wantErr:    true,
+		},
+		{
+			name:       "descriptive subject",
+			strictness: "exact",
+			branch:     "feature/login",
+			message:    "feat: add login form with remember me option",
+			wantErr:    false,
+		},
+		{
+			name:       "contains descriptive part",
+			strictness: "contains",
+			branch:     "feature/login",
+			message:    "feat: add login form with remember me option",
+			wantErr:    true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, _, hashes := createTestRepo(t, []commit{
+				{message: testCase.message, files: map[string]string{"file1.txt": "content1"}},
+			})
+			writeConfigFile(t, tmpDir, `rules:
+  - name: subject-not-branch
+    type: subject_not_branch_name
+    strictness: `+testCase.strictness+"\n")
+			t.Chdir(tmpDir)
+
+			input := fmt.Sprintf("refs/heads/%s %s refs/heads/%s %s\n",
+				testCase.branch, hashes[0].String(), testCase.branch, gitZeroHash)
+
+			err := commitmsg.Run(strings.NewReader(input), nil)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), "restates branch name") {
+				t.Errorf("Run() error = %q, want it to name the restated branch", err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package commitmsg
+
+import (
+	"io"
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// noColorEnvVar disables colorized output if set to a non-empty value, see
+// https://no-color.org.
+const noColorEnvVar = "NO_COLOR"
+
+// ANSI escape sequences of the colors used in the text reports.
+const (
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiReset  = "\x1b[0m"
+)
+
+// palette colorizes parts of the text reports, red for errors and yellow for
+// warnings. The zero value leaves the text unchanged.
+type palette struct {
+	enabled bool
+}
+
+// newPalette returns the palette for reports written to w. Colors are only
+// enabled if w is a terminal and neither --no-color nor NO_COLOR disable them.
+func newPalette(w io.Writer, noColor bool) palette {
+	return palette{enabled: !noColor && os.Getenv(noColorEnvVar) == "" && isTerminal(w)}
+}
+
+// isTerminal reports whether stream, e.g. stdin or stderr, is a file descriptor
+// of a terminal.
+func isTerminal(stream any) bool {
+	f, ok := stream.(*os.File)
+	if !ok {
+		return false
+	}
+
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// severity colorizes s in the color of the given severity.
+func (p palette) severity(severity Severity, s string) string {
+	if severity == SeverityError {
+		return p.wrap(ansiRed, s)
+	}
+
+	return p.wrap(ansiYellow, s)
+}
+
+// report colorizes s in the color of a report with the given violations, i.e.
+// red if any of them is an error and yellow otherwise.
+func (p palette) report(violations []RuleViolation, s string) string {
+	if hasErrors(violations) {
+		return p.wrap(ansiRed, s)
+	}
+
+	return p.wrap(ansiYellow, s)
+}
+
+func (p palette) wrap(color string, s string) string {
+	if !p.enabled {
+		return s
+	}
+
+	return color + s + ansiReset
+}
@@ -0,0 +1,267 @@
+package commitmsg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// commitlintConfig is the subset of a commitlint JSON config (.commitlintrc.json)
+// that is imported.
+type commitlintConfig struct {
+	Rules map[string][]json.RawMessage `json:"rules"`
+}
+
+// commitlintRule is a commitlint rule setting: [level, applicable, value].
+type commitlintRule struct {
+	// level is 0 (disabled), 1 (warning), or 2 (error).
+	level int
+	// never inverts the rule (applicable "never" instead of "always").
+	never bool
+	value json.RawMessage
+}
+
+// maxImportedLength is the largest length limit of imported rules that can be
+// expressed as a pattern (limited by the repetition count of regexp).
+const maxImportedLength = 999
+
+// ImportCommitlintConfig reads a commitlint JSON config and maps the supported
+// commitlint rules (type-enum, subject-case, header-max-length) onto a validated
+// Config. The names of the ignored, unsupported rules are returned as well.
+func ImportCommitlintConfig(path string) (*Config, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read commitlint config: %w", err)
+	}
+
+	var clConfig commitlintConfig
+	err = json.Unmarshal(data, &clConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse commitlint config JSON: %w", err)
+	}
+
+	config := &Config{Includes: nil, Extends: nil, Rules: nil, Overrides: nil, Settings: Settings{}}
+	var ignored []string
+
+	// Sorted for a deterministic order of rules
+	names := make([]string, 0, len(clConfig.Rules))
+	for name := range clConfig.Rules {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		clRule, parseErr := parseCommitlintRule(clConfig.Rules[name])
+		if parseErr != nil {
+			return nil, nil, fmt.Errorf("commitlint rule %q: %w", name, parseErr)
+		}
+
+		if clRule.level == 0 {
+			continue
+		}
+
+		rule, supported, convertErr := convertCommitlintRule(name, clRule)
+		if convertErr != nil {
+			return nil, nil, fmt.Errorf("commitlint rule %q: %w", name, convertErr)
+		}
+
+		if !supported {
+			ignored = append(ignored, name)
+			continue
+		}
+
+		config.Rules = append(config.Rules, rule)
+	}
+
+	err = validateConfig(config, filepath.Dir(path))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid imported commitlint config: %w", err)
+	}
+
+	return config, ignored, nil
+}
+
+// parseCommitlintRule parses a commitlint rule setting of the form
+// [level, applicable, value]. The applicable defaults to "always".
+func parseCommitlintRule(setting []json.RawMessage) (commitlintRule, error) {
+	rule := commitlintRule{level: 0, never: false, value: nil}
+
+	if len(setting) == 0 {
+		return rule, errors.New("level is required")
+	}
+
+	err := json.Unmarshal(setting[0], &rule.level)
+	if err != nil || rule.level < 0 || rule.level > 2 {
+		return rule, fmt.Errorf("level must be 0, 1, or 2, got %s", setting[0])
+	}
+
+	if len(setting) > 1 {
+		var applicable string
+		err = json.Unmarshal(setting[1], &applicable)
+		if err != nil || (applicable != "always" && applicable != "never") {
+			return rule, fmt.Errorf("applicable must be 'always' or 'never', got %s", setting[1])
+		}
+
+		rule.never = applicable == "never"
+	}
+
+	if len(setting) > 2 {
+		rule.value = setting[2]
+	}
+
+	return rule, nil
+}
+
+// convertCommitlintRule converts an enabled commitlint rule into a rule and
+// reports whether the commitlint rule is supported.
+func convertCommitlintRule(name string, clRule commitlintRule) (Rule, bool, error) {
+	var rule Rule
+	rule.Name = "commitlint-" + name
+	rule.Type = RuleTypeRequire
+	rule.Severity = SeverityError
+
+	if clRule.level == 1 {
+		rule.Severity = SeverityWarning
+	}
+
+	if clRule.never {
+		rule.Type = RuleTypeDeny
+	}
+
+	switch name {
+	case "type-enum":
+		var types []string
+		err := json.Unmarshal(clRule.value, &types)
+		if err != nil || len(types) == 0 {
+			return Rule{}, false, errors.New("value must be a non-empty list of types")
+		}
+
+		quoted := make([]string, 0, len(types))
+		for _, ccType := range types {
+			quoted = append(quoted, regexp.QuoteMeta(ccType))
+		}
+
+		rule.Scope = ScopeCCType
+		rule.Pattern = "^(?:" + strings.Join(quoted, "|") + ")$"
+
+		// Like commitlint, a message without a type is not checked
+		if rule.Type == RuleTypeRequire {
+			rule.Pattern = "^(?:|" + strings.Join(quoted, "|") + ")$"
+		}
+
+		rule.Message = fmt.Sprintf("type must %sbe one of [%s]", neverPrefix(clRule.never), strings.Join(types, ", "))
+
+	case "subject-case":
+		cases, err := commitlintCases(clRule.value)
+		if err != nil {
+			return Rule{}, false, err
+		}
+
+		patterns := make([]string, 0, len(cases))
+		for _, c := range cases {
+			pattern, ok := commitlintCasePattern(c)
+			if !ok {
+				return Rule{}, false, fmt.Errorf("unsupported case %q", c)
+			}
+
+			patterns = append(patterns, "(?:"+pattern+")")
+		}
+
+		rule.Scope = ScopeCCDescription
+		rule.Pattern = strings.Join(patterns, "|")
+
+		// Like commitlint, an empty subject is not checked
+		if rule.Type == RuleTypeRequire {
+			rule.Pattern = "^$|" + rule.Pattern
+		}
+
+		rule.Message = fmt.Sprintf("subject must %sbe %s", neverPrefix(clRule.never), strings.Join(cases, ", "))
+
+	case "header-max-length":
+		var limit int
+		err := json.Unmarshal(clRule.value, &limit)
+		if err != nil || limit <= 0 || limit > maxImportedLength {
+			return Rule{}, false, fmt.Errorf("value must be a length between 1 and %d", maxImportedLength)
+		}
+
+		if clRule.never {
+			return Rule{}, false, nil
+		}
+
+		rule.Type = RuleTypeDeny
+		rule.Scope = ScopeTitle
+		rule.Pattern = fmt.Sprintf("^.{%d,}", limit+1)
+		rule.Message = fmt.Sprintf("header must not be longer than %d characters", limit)
+
+	default:
+		return Rule{}, false, nil
+	}
+
+	return rule, true, nil
+}
+
+// commitlintCases parses the value of a subject-case rule, a single case or a
+// list of cases.
+func commitlintCases(value json.RawMessage) ([]string, error) {
+	var single string
+	err := json.Unmarshal(value, &single)
+	if err == nil {
+		return []string{single}, nil
+	}
+
+	var cases []string
+	err = json.Unmarshal(value, &cases)
+	if err != nil || len(cases) == 0 {
+		return nil, errors.New("value must be a case or a non-empty list of cases")
+	}
+
+	return cases, nil
+}
+
+// commitlintCasePattern returns the pattern matching non-empty text in the given
+// commitlint case.
+func commitlintCasePattern(name string) (string, bool) {
+	switch name {
+	case "lower-case", "lowercase":
+		return `^[^\p{Lu}]+$`, true
+
+	case "upper-case", "uppercase":
+		return `^[^\p{Ll}]+$`, true
+
+	case "sentence-case", "sentencecase":
+		return `^[^\p{Ll}][^\p{Lu}]*$`, true
+
+	case "start-case":
+		return `^[^\p{Ll}]\S*(?:\s+[^\p{Ll}]\S*)*$`, true
+
+	case "pascal-case":
+		return `^\p{Lu}[\p{L}\p{N}]*$`, true
+
+	case "camel-case":
+		return `^\p{Ll}[\p{L}\p{N}]*$`, true
+
+	case "kebab-case":
+		return `^[\p{Ll}\p{N}]+(?:-[\p{Ll}\p{N}]+)*$`, true
+
+	case "snake-case":
+		return `^[\p{Ll}\p{N}]+(?:_[\p{Ll}\p{N}]+)*$`, true
+
+	default:
+		return "", false
+	}
+}
+
+// neverPrefix returns the negation for messages of rules with applicable "never".
+func neverPrefix(never bool) string {
+	if never {
+		return "not "
+	}
+
+	return ""
+}
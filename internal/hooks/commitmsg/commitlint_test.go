@@ -0,0 +1,146 @@
+package commitmsg_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/breml/githooks/internal/hooks/commitmsg"
+)
+
+const testCommitlintConfig = `{
+  "extends": ["@commitlint/config-conventional"],
+  "rules": {
+    "type-enum": [2, "always", ["feat", "fix", "docs"]],
+    "subject-case": [1, "never", ["sentence-case", "upper-case"]],
+    "header-max-length": [2, "always", 72],
+    "body-leading-blank": [1, "always"],
+    "scope-empty": [0, "never"]
+  }
+}
+`
+
+func writeCommitlintConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), ".commitlintrc.json")
+	err := os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write commitlint config: %v", err)
+	}
+
+	return path
+}
+
+func TestImportCommitlintConfig(t *testing.T) {
+	config, ignored, err := commitmsg.ImportCommitlintConfig(writeCommitlintConfig(t, testCommitlintConfig))
+	if err != nil {
+		t.Fatalf("ImportCommitlintConfig() returned unexpected error: %v", err)
+	}
+
+	if !slices.Equal(ignored, []string{"body-leading-blank"}) {
+		t.Errorf("ignored = %v, want [body-leading-blank]", ignored)
+	}
+
+	want := []struct {
+		name     string
+		ruleType commitmsg.RuleType
+		scope    commitmsg.Scope
+		severity commitmsg.Severity
+	}{
+		{"commitlint-header-max-length", commitmsg.RuleTypeDeny, commitmsg.ScopeTitle, commitmsg.SeverityError},
+		{"commitlint-subject-case", commitmsg.RuleTypeDeny, commitmsg.ScopeCCDescription, commitmsg.SeverityWarning},
+		{"commitlint-type-enum", commitmsg.RuleTypeRequire, commitmsg.ScopeCCType, commitmsg.SeverityError},
+	}
+
+	if len(config.Rules) != len(want) {
+		t.Fatalf("got %d rules, want %d", len(config.Rules), len(want))
+	}
+
+	for i, w := range want {
+		rule := config.Rules[i]
+		if rule.Name != w.name || rule.Type != w.ruleType || rule.Scope != w.scope || rule.Severity != w.severity {
+			t.Errorf("rule %d = %s/%s/%s/%s, want %s/%s/%s/%s", i,
+				rule.Name, rule.Type, rule.Scope, rule.Severity, w.name, w.ruleType, w.scope, w.severity)
+		}
+	}
+
+	tests := []struct {
+		message   string
+		wantRules []string
+	}{
+		{message: "feat: add login form", wantRules: nil},
+		{message: "chore: update deps", wantRules: []string{"commitlint-type-enum"}},
+		{message: "fix: Fix the crash", wantRules: []string{"commitlint-subject-case"}},
+		{message: "docs: " + strings.Repeat("a", 70), wantRules: []string{"commitlint-header-max-length"}},
+		{message: "Update readme", wantRules: nil},
+	}
+
+	for _, testCase := range tests {
+		var got []string
+		for _, v := range commitmsg.Lint(config, testCase.message) {
+			got = append(got, v.Rule.Name)
+		}
+
+		if !slices.Equal(got, testCase.wantRules) {
+			t.Errorf("Lint(%q) violated %v, want %v", testCase.message, got, testCase.wantRules)
+		}
+	}
+}
+
+func TestImportCommitlintConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		errContains string
+	}{
+		{
+			name:        "invalid JSON",
+			content:     `{"rules": `,
+			errContains: "failed to parse commitlint config JSON",
+		},
+		{
+			name:        "invalid level",
+			content:     `{"rules": {"type-enum": [3, "always", ["feat"]]}}`,
+			errContains: "level must be 0, 1, or 2",
+		},
+		{
+			name:        "unsupported case",
+			content:     `{"rules": {"subject-case": [2, "always", "title-case"]}}`,
+			errContains: `unsupported case "title-case"`,
+		},
+		{
+			name:        "no supported rules",
+			content:     `{"rules": {"body-leading-blank": [2, "always"]}}`,
+			errContains: "no rules defined",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, _, err := commitmsg.ImportCommitlintConfig(writeCommitlintConfig(t, testCase.content))
+			if err == nil || !strings.Contains(err.Error(), testCase.errContains) {
+				t.Errorf("ImportCommitlintConfig() error = %v, want it to contain %q", err, testCase.errContains)
+			}
+		})
+	}
+}
+
+func TestRunImportCommitlint(t *testing.T) {
+	path := writeCommitlintConfig(t, testCommitlintConfig)
+
+	// No .commit-msg-lint.yml is required
+	t.Chdir(t.TempDir())
+
+	err := commitmsg.Run(nil, []string{"commit-msg-lint", "--import-commitlint", path, "--text", "feat: add login"})
+	if err != nil {
+		t.Errorf("Run() returned unexpected error: %v", err)
+	}
+
+	err = commitmsg.Run(nil, []string{"commit-msg-lint", "--import-commitlint", path, "--text", "chore: update"})
+	if err == nil || !strings.Contains(err.Error(), "type must be one of [feat, fix, docs]") {
+		t.Errorf("Run() error = %v, want type-enum violation", err)
+	}
+}
@@ -2,13 +2,18 @@ package commitmsg
 
 import (
 	"bufio"
+	"cmp"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -23,6 +28,28 @@ const (
 	gitZeroHash    = "0000000000000000000000000000000000000000"
 	defaultMainRef = "main"
 	currentDir     = "."
+	// upstreamRevision selects the upstream tracking branch of the head branch as
+	// base ref, like in git rev-parse.
+	upstreamRevision = "@{upstream}"
+	// upstreamRevisionShort is the short form of upstreamRevision.
+	upstreamRevisionShort = "@{u}"
+	// mainRefEnvVar overrides the main_ref setting, e.g. in CI environments with
+	// another default branch.
+	mainRefEnvVar = "COMMIT_MSG_LINT_MAIN_REF"
+	// configEnvVar overrides the path of the default config file, e.g. in CI
+	// environments where passing --config to the hook is awkward.
+	configEnvVar = "COMMIT_MSG_LINT_CONFIG"
+	// disableEnvVar disables linting like the disabled setting, e.g. to push in
+	// an emergency without editing the config.
+	disableEnvVar = "COMMIT_MSG_LINT_DISABLE"
+
+	// skipMergeCommitsReason is the reason of commits skipped by skip_merge_commits.
+	skipMergeCommitsReason = "skip_merge_commits"
+
+	defaultCommentChar = "#"
+	// scissorsMarker follows the comment char on the line above which git
+	// places the diff in verbose mode.
+	scissorsMarker = " ------------------------ >8 ------------------------"
 )
 
 // isKnownCommitMsgBasename reports whether name is one of the filenames git
@@ -39,42 +66,470 @@ func isKnownCommitMsgBasename(name string) bool {
 	}
 }
 
-// parseArgs parses command-line arguments and returns base and head refs.
-// Returns empty strings if no flags are provided (stdin mode).
-func parseArgs(config *Config, args []string) (baseRef string, headRef string, err error) {
+// options holds the settings parsed from the command-line arguments.
+type options struct {
+	configPath string
+	baseRef    string
+	headRef    string
+	asRef      string
+	format     outputFormat
+
+	// importCommitlint is the path of a commitlint JSON config to import instead
+	// of loading the configuration file.
+	importCommitlint string
+	// importGitlint is the path of a gitlint INI config to import instead of
+	// loading the configuration file.
+	importGitlint string
+
+	// messageFile is the commit message file to validate (commit-msg hook mode).
+	messageFile string
+	// text is a commit message to validate, passed directly on the command line
+	// with --text or its alias --message.
+	text string
+	// textSource names the flag text was passed with as the origin of the commit
+	// message in reports.
+	textSource string
+	// verbose prints each ref range and commit being validated to stderr.
+	verbose bool
+	// debug writes troubleshooting logs, e.g. the resolved config path and commit
+	// ranges, to stderr.
+	debug bool
+	// dryRun reports violations without failing.
+	dryRun bool
+	// failOn is the minimum severity of a violation failing the run.
+	failOn Severity
+	// noColor disables colorized text reports.
+	noColor bool
+	// quiet suppresses all output, the result is only reported by the exit code.
+	quiet bool
+	// since validates the commits of HEAD authored at or after this time instead
+	// of a commit range (zero if unset).
+	since time.Time
+	// checkConfig only loads and validates the configuration, without opening a
+	// repository or validating any commit message.
+	checkConfig bool
+	// listRules prints the configured rules instead of validating any commit
+	// message.
+	listRules bool
+	// branch validates the commits of the current branch that are not on the
+	// main ref, i.e. main..HEAD.
+	branch bool
+	// testFile is the fixture file of the test subcommand, whose sample messages
+	// are validated against the config instead of any commit.
+	testFile string
+	// output is the file the report is written to instead of stderr, or stdout in
+	// the machine-readable format modes.
+	output string
+
+	// positional holds the arguments remaining after flag parsing
+	// (e.g. the commit message file path in commit-msg hook mode).
+	positional []string
+}
+
+// runner holds the state shared by the validation modes of a single invocation.
+type runner struct {
+	config *Config
+	repo   *git.Repository
+	format outputFormat
+
+	// branch is the short name of the branch the commits are validated for
+	// (empty if unknown).
+	branch string
+	// targetBranch is the short name of the branch the commits are pushed to or,
+	// with --as-ref, evaluated as. If empty, branch is the target.
+	targetBranch string
+
+	// verbose enables a progress line on stderr per validated commit and ref.
+	verbose bool
+	// debug receives the troubleshooting logs enabled with --debug.
+	debug *slog.Logger
+	// dryRun reports error-level violations like warnings instead of failing.
+	dryRun bool
+	// failOn is the minimum severity of a violation failing the run, unless
+	// dryRun is set.
+	failOn Severity
+
+	// stdout receives machine-readable reports (e.g. JSON).
+	stdout io.Writer
+	// stderr receives notes and progress lines (e.g. skipped refs).
+	stderr io.Writer
+	// report receives the text reports of violations that do not fail the run
+	// (e.g. warnings) and the summary line: stderr or the --output file.
+	report io.Writer
+	// palette colorizes the text reports if stderr is a terminal.
+	palette palette
+
+	// jsonViolations collects the reported violations in JSON format mode.
+	jsonViolations []jsonViolation
+	// summary counts the validated commit messages and their violations.
+	summary runSummary
+
+	// ancestors caches the commits reachable from the base commits of the ranges
+	// validated by a single pre-push hook run (nil disables caching).
+	ancestors *ancestorCache
+	// mergeBases caches the merge bases of the pushed commits and the main ref of a
+	// single pre-push hook run (nil disables caching).
+	mergeBases *mergeBaseCache
+	// validatedCommits holds the commits validated by a single pre-push hook run,
+	// keyed by commit hash, rule set, and rule context, so commits shared by several
+	// pushed refs are only validated and reported once (nil disables tracking).
+	validatedCommits map[string]struct{}
+}
+
+// parseArgs parses command-line arguments into options.
+// Returns empty refs if no ref flags are provided (stdin mode).
+func parseArgs(args []string) (options, error) {
+	var opts options
+	opts.format = formatText
+	opts.failOn = SeverityError
+
 	// Handle nil or empty args (stdin mode)
 	if len(args) == 0 {
-		return "", "", nil
+		return opts, nil
 	}
 
+	var format, since, message, failOn string
+
 	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
 	fs.SetOutput(io.Discard) // Don't print default error messages
 
-	var base, head string
-	fs.StringVar(&base, "base-ref", "", "Base ref or SHA to compare from")
-	fs.StringVar(&head, "head-ref", "", "Head ref or SHA to compare to")
+	fs.StringVar(&opts.configPath, "config", "", "Path to the configuration file")
+	fs.StringVar(&opts.importCommitlint, "import-commitlint", "", "Import the rules of this commitlint JSON config")
+	fs.StringVar(&opts.importGitlint, "import-gitlint", "", "Import the rules of this gitlint config")
+	fs.StringVar(&opts.baseRef, "base-ref", "", "Base ref or SHA to compare from")
+	fs.StringVar(&opts.headRef, "head-ref", "", "Head ref or SHA to compare to")
+	fs.StringVar(&opts.asRef, "as-ref", "", "Evaluate commits as if pushed to this ref (applies its overrides)")
+	fs.StringVar(&format, "format", string(formatText), "Output format: text, json, or gitlab")
+	fs.StringVar(&opts.messageFile, "message-file", "", "Validate the commit message in this file (commit-msg hook mode)")
+	fs.StringVar(&opts.text, "text", "", "Validate this commit message (no repository required)")
+	fs.StringVar(&message, "message", "", "Alias of --text")
+	fs.BoolVar(&opts.verbose, "verbose", false, "Print each ref range and commit being validated to stderr")
+	fs.BoolVar(&opts.debug, "debug", false, "Write troubleshooting logs, e.g. the resolved config and ranges, to stderr")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "Report violations without failing")
+	fs.StringVar(&failOn, "fail-on", string(SeverityError), "Minimum severity failing the run: error or warning")
+	fs.BoolVar(&opts.checkConfig, "check-config", false, "Only validate the configuration file")
+	fs.BoolVar(&opts.listRules, "list-rules", false, "Print the configured rules")
+	fs.BoolVar(&opts.branch, "branch", false, "Validate the commits of the current branch that are not on the main ref")
+	fs.BoolVar(&opts.noColor, "no-color", false, "Disable colorized output")
+	fs.BoolVar(&opts.quiet, "quiet", false, "Suppress all output, only set the exit code")
+	fs.StringVar(&since, "since", "", "Validate the commits of HEAD authored since this date (RFC3339 or YYYY-MM-DD)")
+	fs.StringVar(&opts.output, "output", "", "Write the report to this file instead of stderr or stdout")
+
+	// The test subcommand takes its flags between its name and the fixture file
+	flagArgs := args[1:]
+	if isTestSubcommand(args) {
+		opts.testFile = args[len(args)-1]
+		flagArgs = args[2 : len(args)-1]
+	}
 
-	err = fs.Parse(args[1:])
+	err := fs.Parse(flagArgs)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to parse arguments: %w", err)
+		return options{}, fmt.Errorf("failed to parse arguments: %w", err)
 	}
 
-	// If no flags provided, return empty strings (stdin mode)
-	if base == "" && head == "" {
-		return "", "", nil
+	opts.positional = fs.Args()
+
+	if opts.testFile != "" && len(opts.positional) > 0 {
+		return options{}, errors.New("the test subcommand takes a single fixture file after its flags")
 	}
 
-	// If only head-ref is provided, default base-ref to "main"
-	if base == "" && head != "" {
-		base = config.Settings.MainRef
+	opts.format = outputFormat(format)
+	switch opts.format {
+	case formatText, formatJSON, formatGitLab:
+
+	default:
+		return options{}, fmt.Errorf("invalid --format %q: must be 'text', 'json', or 'gitlab'", format)
+	}
+
+	opts.failOn = Severity(failOn)
+	switch opts.failOn {
+	case SeverityError, SeverityWarning:
+
+	default:
+		return options{}, fmt.Errorf("invalid --fail-on %q: must be 'error' or 'warning'", failOn)
+	}
+
+	if opts.quiet && opts.format != formatText {
+		return options{}, fmt.Errorf("--quiet can not be combined with --format %s", opts.format)
+	}
+
+	if opts.quiet && (opts.verbose || opts.debug) {
+		return options{}, errors.New("--quiet can not be combined with --verbose or --debug")
+	}
+
+	if opts.importCommitlint != "" && opts.configPath != "" {
+		return options{}, errors.New("--import-commitlint can not be combined with --config")
+	}
+
+	if opts.importGitlint != "" && (opts.configPath != "" || opts.importCommitlint != "") {
+		return options{}, errors.New("--import-gitlint can not be combined with --config or --import-commitlint")
 	}
 
 	// If only base-ref is provided, error (need head-ref)
-	if base != "" && head == "" {
-		return "", "", errors.New("--head-ref is required when using --base-ref")
+	if opts.baseRef != "" && opts.headRef == "" {
+		return options{}, errors.New("--head-ref is required when using --base-ref")
+	}
+
+	// A single commit message can not be combined with a commit range
+	if opts.messageFile != "" && opts.headRef != "" {
+		return options{}, errors.New("--message-file can not be combined with --base-ref or --head-ref")
+	}
+
+	switch {
+	case opts.text != "" && message != "":
+		return options{}, errors.New("--text can not be combined with --message")
+
+	case message != "":
+		opts.text = message
+		opts.textSource = "the --message argument"
+
+	default:
+		opts.textSource = "the --text argument"
+	}
+
+	if opts.text != "" && (opts.messageFile != "" || opts.headRef != "") {
+		return options{}, errors.New("--text can not be combined with --message-file, --base-ref, or --head-ref")
+	}
+
+	if opts.checkConfig && (opts.text != "" || opts.messageFile != "" || opts.headRef != "") {
+		return options{}, errors.New("--check-config can not be combined with --text, --message-file, or the ref flags")
+	}
+
+	if opts.listRules {
+		if opts.text != "" || opts.messageFile != "" || opts.headRef != "" || opts.checkConfig || since != "" {
+			return options{}, errors.New("--list-rules can not be combined with --text, --message-file, " +
+				"--check-config, --since, or the ref flags")
+		}
+
+		if opts.format == formatGitLab {
+			return options{}, errors.New("--list-rules can not be combined with --format gitlab")
+		}
+	}
+
+	if since != "" {
+		if opts.text != "" || opts.messageFile != "" || opts.headRef != "" || opts.checkConfig {
+			return options{}, errors.New("--since can not be combined with --text, --message-file, --check-config, " +
+				"or the ref flags")
+		}
+
+		opts.since, err = parseSince(since)
+		if err != nil {
+			return options{}, err
+		}
+	}
+
+	if opts.branch {
+		if opts.text != "" || opts.messageFile != "" || opts.headRef != "" || opts.checkConfig || opts.listRules ||
+			since != "" {
+			return options{}, errors.New("--branch can not be combined with --text, --message-file, --check-config, " +
+				"--list-rules, --since, or the ref flags")
+		}
+
+		// The base defaults to the main ref like for --head-ref alone
+		opts.headRef = "HEAD"
+	}
+
+	if opts.output != "" && (opts.checkConfig || opts.listRules || opts.testFile != "") {
+		return options{}, errors.New("--output can not be combined with --check-config, --list-rules, " +
+			"or the test subcommand")
+	}
+
+	if opts.testFile != "" {
+		if opts.text != "" || opts.messageFile != "" || opts.headRef != "" || opts.checkConfig || opts.listRules ||
+			since != "" {
+			return options{}, errors.New("the test subcommand can not be combined with --text, --message-file, " +
+				"--check-config, --list-rules, --since, --branch, or the ref flags")
+		}
+
+		if opts.format != formatText {
+			return options{}, errors.New("the test subcommand only supports --format text")
+		}
+	}
+
+	return opts, nil
+}
+
+// parseSince parses the --since date, either an RFC3339 timestamp or a date
+// (YYYY-MM-DD), which denotes midnight in the local time zone.
+func parseSince(since string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, since)
+	if err == nil {
+		return t, nil
+	}
+
+	t, err = time.ParseInLocation(time.DateOnly, since, time.Local)
+	if err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --since %q: must be an RFC3339 timestamp or a date (YYYY-MM-DD)", since)
+}
+
+// applyMainRefDefault sets the main ref from the COMMIT_MSG_LINT_MAIN_REF
+// environment variable if set, otherwise it defaults main_ref to "main". An
+// explicit --base-ref still takes precedence over the main ref.
+func applyMainRefDefault(config *Config) {
+	config.Settings.MainRef = cmp.Or(os.Getenv(mainRefEnvVar), config.Settings.MainRef, defaultMainRef)
+}
+
+// lintingDisabled reports whether linting is disabled by the
+// COMMIT_MSG_LINT_DISABLE environment variable if set, otherwise by the disabled
+// setting. A note is written to stderr if linting is disabled.
+func lintingDisabled(config *Config, stderr io.Writer) (bool, error) {
+	disabled := isEnabled(config.Settings.Disabled)
+
+	if value := os.Getenv(disableEnvVar); value != "" {
+		var err error
+
+		disabled, err = strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s %q: must be a boolean, e.g. 1 or 0", disableEnvVar, value)
+		}
+	}
+
+	if disabled {
+		_, _ = fmt.Fprintln(stderr, "Commit message linting is disabled, skipping validation")
+	}
+
+	return disabled, nil
+}
+
+// isManualRun reports whether the tool was run without a mode selecting flag or
+// argument and stdin is a terminal, i.e. no pre-push hook input can be piped in.
+// Like with --branch, the commits of the current branch are validated then.
+func isManualRun(opts options, stdin io.Reader) bool {
+	if opts.text != "" || opts.messageFile != "" || opts.headRef != "" || !opts.since.IsZero() ||
+		len(opts.positional) > 0 {
+		return false
+	}
+
+	return isTerminal(stdin)
+}
+
+// applyRefDefaults fills in the base ref from the configured main ref, or the
+// upstream of the head branch with use_upstream_as_base, when only --head-ref was
+// provided.
+func applyRefDefaults(config *Config, opts *options) {
+	if opts.baseRef != "" || opts.headRef == "" {
+		return
+	}
+
+	if isEnabled(config.Settings.UseUpstreamAsBase) {
+		opts.baseRef = upstreamRevision
+		return
+	}
+
+	opts.baseRef = config.Settings.MainRef
+}
+
+// loadConfig loads the configuration from the path given with --config or,
+// if unset, from the default config file (see loadDefaultConfig). With
+// --import-commitlint or --import-gitlint, the config of the respective tool is
+// imported instead. The path of the loaded file is returned along with the config.
+func loadConfig(opts options, stderr io.Writer) (*Config, string, error) {
+	var config *Config
+	var err error
+
+	switch {
+	case opts.importCommitlint != "":
+		config, err = importConfig(ImportCommitlintConfig, opts.importCommitlint, "commitlint", stderr)
+		return config, opts.importCommitlint, err
+
+	case opts.importGitlint != "":
+		config, err = importConfig(ImportGitlintConfig, opts.importGitlint, "gitlint", stderr)
+		return config, opts.importGitlint, err
+
+	case opts.configPath != "":
+		config, err = LoadConfigFile(opts.configPath)
+		return config, opts.configPath, err
+
+	default:
+		return loadDefaultConfig()
+	}
+}
+
+// loadDefaultConfig loads the config file given by the COMMIT_MSG_LINT_CONFIG
+// environment variable or, if unset, the default config file (see LoadConfig).
+// Relative paths are resolved against the repository root. The path of the
+// loaded file is returned along with the config.
+//
+// The environment variable is an explicit choice like --config, e.g. of a CI job
+// or hook setup, so it takes precedence over the repository config. Only the
+// implicit lookup falls back from the repository config to the user config.
+func loadDefaultConfig() (*Config, string, error) {
+	root := findRepoRoot(currentDir)
+
+	configPath := os.Getenv(configEnvVar)
+	switch {
+	case configPath == "":
+		var err error
+
+		configPath, err = findConfigFile(root)
+		if err != nil {
+			return nil, "", err
+		}
+
+	case !filepath.IsAbs(configPath):
+		configPath = filepath.Join(root, configPath)
+	}
+
+	config, err := LoadConfigFile(configPath)
+
+	return config, configPath, err
+}
+
+// findRepoRoot returns the root of the repository containing dir, that is the
+// nearest of dir and its parent directories with a .git entry (a directory, or a
+// file for linked worktrees and submodules). Without such an entry, dir is
+// returned unchanged.
+func findRepoRoot(dir string) string {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+
+	for current := absDir; ; {
+		_, statErr := os.Stat(filepath.Join(current, git.GitDirName))
+		if statErr == nil {
+			return current
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+
+		current = parent
+	}
+}
+
+// openRepository opens the repository containing the current directory,
+// walking up the parent directories to its root.
+func openRepository() (*git.Repository, error) {
+	return git.PlainOpenWithOptions(currentDir, &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: false,
+	})
+}
+
+// importConfig imports the config of another commit message linter (tool) with
+// importer and reports the ignored, unsupported rules of the tool on stderr.
+func importConfig(
+	importer func(path string) (*Config, []string, error),
+	path string,
+	tool string,
+	stderr io.Writer,
+) (*Config, error) {
+	config, ignored, err := importer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range ignored {
+		_, _ = fmt.Fprintf(stderr, "Ignoring unsupported %s rule %q\n", tool, name)
 	}
 
-	return base, head, nil
+	return config, nil
 }
 
 // resolveRefOrSHA resolves a ref name or SHA to a commit object.
@@ -99,45 +554,188 @@ func resolveRefOrSHA(repo *git.Repository, refOrSHA string) (*object.Commit, err
 }
 
 // resolveBaseOID determines the base commit OID for computing the commit range.
-// For new branches (remoteOID is zero hash), it falls back to the configured main ref.
+// For new branches (remoteOID is zero hash), it falls back to the merge base with
+// the configured main ref.
 // For existing branches, it checks whether remoteOID is an ancestor of localOID.
-// If not (e.g. after a rebase + force push), it falls back to the configured main ref.
-func resolveBaseOID(config *Config, repo *git.Repository, remoteOID string, localOID string) (string, error) {
+// If not (e.g. after a rebase or amend + force push), the base is determined by
+// forcePushBase. The merge bases with the main ref are looked up in mergeBases, if
+// not nil.
+func resolveBaseOID(
+	config *Config,
+	repo *git.Repository,
+	mergeBases *mergeBaseCache,
+	remoteOID string,
+	localOID string,
+) (string, error) {
 	if remoteOID == gitZeroHash {
-		// New branch, examine all commits since main branch
-		mainRef, err := resolveRefOrSHA(repo, config.Settings.MainRef)
-		if err != nil {
-			return "", fmt.Errorf("failed to resolve main ref: %w", err)
-		}
-
-		return mainRef.Hash.String(), nil
+		// New branch, examine all commits since it diverged from the main branch
+		return mainMergeBase(config, repo, mergeBases, localOID)
 	}
 
-	// Check if remoteOID is an ancestor of localOID.
-	// If not (e.g. after a rebase + force push), the remote ref
-	// is no longer in the local commit graph and cannot be used
-	// as the base. Fall back to the configured main ref.
+	// If the remote commit is unknown locally, the remote ref can not be used as
+	// the base. Fall back to the merge base with the configured main ref.
 	ancestor, err := isAncestorOf(repo, remoteOID, localOID)
-	if err != nil || !ancestor {
-		mainRef, resolveErr := resolveRefOrSHA(repo, config.Settings.MainRef)
-		if resolveErr != nil {
-			return "", fmt.Errorf("failed to resolve main ref: %w", resolveErr)
-		}
+	if err != nil {
+		return mainMergeBase(config, repo, mergeBases, localOID)
+	}
 
-		return mainRef.Hash.String(), nil
+	if !ancestor {
+		return forcePushBase(config, repo, mergeBases, remoteOID, localOID)
 	}
 
 	return remoteOID, nil
 }
 
-// runStdinMode reads git pre-push hook input from stdin and validates commits.
-func runStdinMode(config *Config, repo *git.Repository, stdin io.Reader) error {
+// forcePushBase determines the base commit OID of a force push, where remoteOID is
+// not an ancestor of localOID. The commits reachable from the merge base of
+// remoteOID and localOID were already accepted by the remote, so this merge base is
+// used if the branch still contains commits of its own after it (e.g. after
+// amending the last commit). Otherwise (e.g. after a rebase onto the updated main
+// ref), the merge base with the configured main ref is used.
+func forcePushBase(
+	config *Config,
+	repo *git.Repository,
+	mergeBases *mergeBaseCache,
+	remoteOID string,
+	localOID string,
+) (string, error) {
+	mainBase, err := mainMergeBase(config, repo, mergeBases, localOID)
+	if err != nil {
+		return "", err
+	}
+
+	remoteBase, ok := mergeBaseOf(repo, remoteOID, localOID)
+	if !ok {
+		return mainBase, nil
+	}
+
+	// Only a merge base on the branch itself, i.e. after the main merge base, excludes
+	// more commits than the main merge base
+	afterMain, ancestorErr := isAncestorOf(repo, mainBase, remoteBase)
+	if ancestorErr == nil && afterMain {
+		return remoteBase, nil
+	}
+
+	return mainBase, nil
+}
+
+// mergeBaseOf returns the merge base of the commits oidA and oidB and reports
+// whether there is one.
+func mergeBaseOf(repo *git.Repository, oidA string, oidB string) (string, bool) {
+	commitA, err := repo.CommitObject(plumbing.NewHash(oidA))
+	if err != nil {
+		return "", false
+	}
+
+	commitB, err := repo.CommitObject(plumbing.NewHash(oidB))
+	if err != nil {
+		return "", false
+	}
+
+	bases, err := commitA.MergeBase(commitB)
+	if err != nil || len(bases) == 0 {
+		return "", false
+	}
+
+	return bases[0].Hash.String(), true
+}
+
+// mainMergeBase returns the merge base of localOID and the configured main ref,
+// i.e. the commit the pushed branch diverged from the main branch. If the
+// histories are unrelated, the tip of the main ref is returned. The merge base is
+// looked up in mergeBases, if not nil.
+func mainMergeBase(config *Config, repo *git.Repository, mergeBases *mergeBaseCache, localOID string) (string, error) {
+	mainCommit, err := resolveRefOrSHA(repo, config.Settings.MainRef)
+	if err != nil {
+		return "", &missingMainRefError{ref: config.Settings.MainRef, err: err}
+	}
+
+	key := mergeBaseKey{local: plumbing.NewHash(localOID), main: mainCommit.Hash}
+	if base, ok := mergeBases.lookup(key); ok {
+		return base, nil
+	}
+
+	localCommit, err := repo.CommitObject(key.local)
+	if err != nil {
+		return "", fmt.Errorf("failed to get local commit %s: %w", localOID, err)
+	}
+
+	bases, err := localCommit.MergeBase(mainCommit)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute merge base with main ref: %w", err)
+	}
+
+	base := mainCommit.Hash.String()
+	if len(bases) > 0 {
+		base = bases[0].Hash.String()
+	}
+
+	mergeBases.store(key, base)
+
+	return base, nil
+}
+
+// mergeBaseKey identifies the merge base of a local commit and the main ref.
+type mergeBaseKey struct {
+	local plumbing.Hash
+	main  plumbing.Hash
+}
+
+// mergeBaseCache caches the merge bases of pushed commits and the main ref, so
+// the history is only walked once for refs pushed together pointing to the same
+// commit, e.g. a branch and a tag.
+type mergeBaseCache struct {
+	bases map[mergeBaseKey]string
+	// computations counts the merge base computations, i.e. the cache misses.
+	computations int
+}
+
+func newMergeBaseCache() *mergeBaseCache {
+	return &mergeBaseCache{
+		bases:        map[mergeBaseKey]string{},
+		computations: 0,
+	}
+}
+
+// lookup returns the cached merge base for key. A nil cache never has one.
+func (c *mergeBaseCache) lookup(key mergeBaseKey) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	base, ok := c.bases[key]
+
+	return base, ok
+}
+
+// store caches the computed merge base for key. A nil cache does nothing.
+func (c *mergeBaseCache) store(key mergeBaseKey, base string) {
+	if c == nil {
+		return
+	}
+
+	c.bases[key] = base
+	c.computations++
+}
+
+// runStdinMode validates the refs pushed according to the git pre-push hook input
+// read from stdin. The violations of all refs are reported together, unless
+// fail_fast is set.
+func (r *runner) runStdinMode(stdin io.Reader) error {
+	// Refs pushed together usually share their base, e.g. the main ref
+	r.ancestors = newAncestorCache()
+	r.mergeBases = newMergeBaseCache()
+	r.validatedCommits = map[string]struct{}{}
+
 	// Read from stdin - git pre-push hook provides refs via stdin
 	scanner := bufio.NewScanner(stdin)
 
+	var violationErrs []*ViolationError
+
 	const (
 		stdinPosLocalRef  = 0
 		stdinPosLocalOID  = 1
+		stdinPosRemoteRef = 2
 		stdinPosRemoteOID = 3
 	)
 
@@ -154,6 +752,7 @@ func runStdinMode(config *Config, repo *git.Repository, stdin io.Reader) error {
 
 		localRef := fields[stdinPosLocalRef]
 		localOID := fields[stdinPosLocalOID]
+		remoteRef := fields[stdinPosRemoteRef]
 		remoteOID := fields[stdinPosRemoteOID]
 
 		// Handle delete
@@ -161,18 +760,69 @@ func runStdinMode(config *Config, repo *git.Repository, stdin io.Reader) error {
 			continue
 		}
 
-		// Determine the base commit for the range
-		baseOID, err := resolveBaseOID(config, repo, remoteOID, localOID)
-		if err != nil {
+		// Only refs matching protected_refs are validated, if configured
+		if !isProtectedRef(localRef, r.config.Settings.protectedRefRegexes) {
+			r.logf("Skipping %s: not a protected ref", localRef)
+			continue
+		}
+
+		// Pushing the main ref itself to a new remote brings nothing new to validate
+		if remoteOID == gitZeroHash && refMatches(localRef, r.config.Settings.MainRef) {
+			r.logf("Skipping %s: main ref pushed to new %s", localRef, remoteRef)
+			continue
+		}
+
+		// Annotated tags are validated by their message instead of a commit range
+		if tag, ok := r.annotatedTag(localRef, localOID); ok {
+			r.logf("Checking annotation of %s pushed to %s", localRef, remoteRef)
+
+			var err error
+
+			violationErrs, err = r.collectViolationError(violationErrs, r.checkTag(tag, remoteRef))
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		// Determine the base commit for the range, a new branch has none without the main ref
+		baseOID, err := resolveBaseOID(r.config, r.repo, r.mergeBases, remoteOID, localOID)
+		if err != nil && (remoteOID != gitZeroHash || !r.fallbackMissingMainRef(err, localRef)) {
 			return err
 		}
 
-		commitRange := fmt.Sprintf("%s..%s", baseOID, localOID)
+		// A branch identical to its base has no commits to validate
+		if baseOID == localOID {
+			r.reportNoCommits(localRef)
+			continue
+		}
 
-		// Check commits in the range
-		checkErr := checkCommits(config, repo, commitRange, localRef)
-		if checkErr != nil {
-			return checkErr
+		// Without a base, all commits reachable from the pushed ref are validated
+		commitRange := localOID
+		if baseOID != "" {
+			commitRange = fmt.Sprintf("%s..%s", baseOID, localOID)
+		}
+
+		r.debug.Debug("resolved pushed ref", "local_ref", localRef, "local_oid", localOID, "remote_ref", remoteRef,
+			"remote_oid", remoteOID, "base", baseOID)
+		r.logf("Checking %s pushed to %s (%s)", localRef, remoteRef, commitRange)
+
+		// Check commits in the range, applying the overrides of the ref pushed to
+		refRunner := *r
+		refRunner.config = configForRef(r.config, remoteRef)
+		refRunner.branch = plumbing.ReferenceName(localRef).Short()
+		if r.targetBranch == "" {
+			refRunner.targetBranch = plumbing.ReferenceName(remoteRef).Short()
+		}
+
+		checkErr := refRunner.checkCommits(commitRange, localRef)
+		r.jsonViolations = refRunner.jsonViolations
+		r.summary = refRunner.summary
+
+		violationErrs, err = r.collectViolationError(violationErrs, checkErr)
+		if err != nil {
+			return err
 		}
 	}
 
@@ -181,85 +831,515 @@ func runStdinMode(config *Config, repo *git.Repository, stdin io.Reader) error {
 		return fmt.Errorf("error reading stdin: %w", err)
 	}
 
-	return nil
-}
+	return joinViolationErrors(violationErrs)
+}
+
+// collectViolationError appends err to errs if it is a *ViolationError, as the
+// violations of all refs are reported together. Other errors, and any error in
+// fail-fast mode, are returned to abort the run.
+func (r *runner) collectViolationError(errs []*ViolationError, err error) ([]*ViolationError, error) {
+	if err == nil {
+		return errs, nil
+	}
+
+	var violationErr *ViolationError
+	if !errors.As(err, &violationErr) || isEnabled(r.config.Settings.FailFast) {
+		return errs, err
+	}
+
+	return append(errs, violationErr), nil
+}
+
+// annotatedTag returns the tag object of a pushed tag ref with lint_tags set.
+// It returns false for other refs and lightweight tags, which point to a commit
+// directly and are validated as a commit range.
+func (r *runner) annotatedTag(localRef string, localOID string) (*object.Tag, bool) {
+	if !isEnabled(r.config.Settings.LintTags) || !plumbing.ReferenceName(localRef).IsTag() {
+		return nil, false
+	}
+
+	tag, err := r.repo.TagObject(plumbing.NewHash(localOID))
+	if err != nil {
+		return nil, false
+	}
+
+	return tag, true
+}
+
+// checkTag validates the message of an annotated tag with the rules of the ref
+// it is pushed to. Rules depending on a commit are skipped, like for a commit
+// message that is not (yet) a commit.
+func (r *runner) checkTag(tag *object.Tag, remoteRef string) error {
+	tagRunner := *r
+	tagRunner.config = configForRef(r.config, remoteRef)
+
+	err := tagRunner.reportMessageViolations("the annotation of tag "+tag.Name, Lint(tagRunner.config, tag.Message))
+	r.jsonViolations = tagRunner.jsonViolations
+	r.summary = tagRunner.summary
+
+	return err
+}
+
+// validateCommits validates a list of commits against configured rules.
+// Commits with only warning-level violations are reported to stderr and do not
+// stop the validation. In fail-fast mode, the validation stops at the first commit
+// with error-level violations, otherwise all failed commits are reported at the end.
+// Rules with first_commit_only are only evaluated for the first, i.e. the newest,
+// commit.
+func (r *runner) validateCommits(commits []*object.Commit, refName string) error {
+	config := r.config
+
+	// Only parse the message sections the rules depend on
+	parts := messagePartsForRules(config.Rules)
+	if isEnabled(config.Settings.AllowSkipTrailer) {
+		parts |= partFooter
+	}
+
+	var failed []commitViolations
+
+	r.summary.ranges++
+
+	// Commits shared with an earlier ref are only skipped if validated with the same rules
+	ruleNames := make([]string, 0, len(config.Rules))
+	for _, rule := range config.Rules {
+		ruleNames = append(ruleNames, rule.Name)
+	}
+
+	ruleSet := strings.Join(ruleNames, "\x00")
+
+	// Merge commits skipped by skip_merge_commits are still validated by the
+	// no_merge_commits rules, which exist to reject them
+	mergeRules := slices.DeleteFunc(slices.Clone(config.Rules), func(rule Rule) bool {
+		return rule.Type != RuleTypeNoMergeCommits
+	})
+
+	for i, commit := range commits {
+		rules := config.Rules
+		if reason := commitSkipReason(config, commit); reason != "" {
+			if reason != skipMergeCommitsReason || len(mergeRules) == 0 {
+				r.debug.Debug("skipped commit", "commit", commit.Hash.String(), "reason", reason)
+				r.logCommit(commit, "skip")
+
+				continue
+			}
+
+			rules = mergeRules
+		}
+
+		// The rules are evaluated for the newest (the tip) to the oldest commit
+		ctx := r.ruleContext(commit)
+		ctx.intermediate = i > 0
+
+		if r.validatedCommits != nil {
+			key := commit.Hash.String() + "\x00" + ruleSet + "\x00" + ruleContextKey(config.Rules, ctx)
+			if _, ok := r.validatedCommits[key]; ok {
+				r.debug.Debug("skipped commit", "commit", commit.Hash.String(), "reason", "already validated")
+				r.logf("skip %s %s (already validated for another ref)", commit.Hash.String()[:7], getFirstLine(commit.Message))
+				continue
+			}
+
+			r.validatedCommits[key] = struct{}{}
+		}
+
+		// Parse commit message
+		parsed := parseCommitMessage(commit.Message, parts)
+
+		// Evaluate all rules
+		ctx.skippedRules = r.skippedRules(parsed, commit.Hash.String()[:7])
+		violations := evaluateRules(rules, parsed, ctx, isEnabled(config.Settings.FailFast))
+
+		if len(violations) == 0 {
+			r.summary.record(nil)
+			r.logCommit(commit, "pass")
+
+			continue
+		}
+
+		violationsToShow := limitViolations(config, violations)
+		r.summary.record(violationsToShow)
+
+		// In machine-readable format modes, violations are collected and written at the end of the run
+		if r.format.machineReadable() {
+			r.recordViolations(commit.Hash.String(), refName, violationsToShow)
+		}
+
+		if !r.fails(violationsToShow) {
+			r.logCommit(commit, "warn")
+
+			if !r.format.machineReadable() {
+				_, _ = fmt.Fprint(r.report, formatCommitReport(
+					r.palette, commit, refName, violationsToShow, reportedMessageLines(config.Settings),
+				))
+			}
+
+			continue
+		}
+
+		r.logCommit(commit, "fail")
+
+		// Failed commits are reported at the end, fail-fast mode stops at the first one
+		failed = append(failed, commitViolations{commit: commit, violations: violationsToShow})
+
+		if isEnabled(config.Settings.FailFast) {
+			break
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	// Dry-run only suppresses the error, the violations are still reported: the
+	// machine-readable report already holds them, the text report goes to stderr
+	if r.dryRun {
+		if !r.format.machineReadable() {
+			_, _ = fmt.Fprint(r.report, formatCommitsViolationError(
+				r.palette, refName, failed, reportedMessageLines(config.Settings),
+			).Error())
+		}
+
+		return nil
+	}
+
+	if r.format.machineReadable() {
+		if len(failed) == 1 {
+			return violationErrorf("commit %s in %s failed validation", failed[0].commit.Hash.String()[:7], refName)
+		}
+
+		return violationErrorf("%d commits in %s failed validation", len(failed), refName)
+	}
+
+	return formatCommitsViolationError(r.palette, refName, failed, reportedMessageLines(config.Settings))
+}
+
+// ruleContextKey returns the parts of ctx the rules depend on, so a commit shared
+// by several pushed refs is validated again for a ref with a different context:
+// the target branch for revert_requires_approval rules, the branch for
+// subject_not_branch_name rules and rules with branch_ticket_pattern, and the
+// position of the commit for first_commit_only rules.
+func ruleContextKey(rules []Rule, ctx ruleContext) string {
+	var branch, targetBranch, intermediate string
+
+	for _, rule := range rules {
+		if rule.Type == RuleTypeRevertRequiresApproval {
+			targetBranch = ctx.targetBranch
+		}
+
+		if rule.Type == RuleTypeSubjectNotBranchName || rule.branchTicketRegex != nil {
+			branch = ctx.branch
+		}
+
+		if rule.FirstCommitOnly {
+			intermediate = strconv.FormatBool(ctx.intermediate)
+		}
+	}
+
+	return branch + "\x00" + targetBranch + "\x00" + intermediate
+}
+
+// ruleContext returns the context to evaluate the rules for commit. Its commit
+// field is nil in commit-msg hook and text mode, where the commit does not exist
+// yet.
+func (r *runner) ruleContext(commit *object.Commit) ruleContext {
+	return ruleContext{
+		repo:         r.repo,
+		commit:       commit,
+		branch:       r.branch,
+		targetBranch: cmp.Or(r.targetBranch, r.branch),
+		intermediate: false,
+		skippedRules: nil,
+	}
+}
+
+// skippedRules returns the names of the rules disabled by the Lint-Skip trailers
+// of message if the allow_skip_trailer setting is enabled. The skipped rules are
+// logged in verbose mode for the commit or file named by subject.
+func (r *runner) skippedRules(message ParsedCommitMessage, subject string) []string {
+	if !isEnabled(r.config.Settings.AllowSkipTrailer) {
+		return nil
+	}
+
+	names := skippedRuleNames(message)
+	for _, name := range names {
+		r.logf("Skipping rule %s for %s: %s trailer", name, subject, lintSkipTrailerKey)
+	}
+
+	return names
+}
+
+// commitSkipReason returns why a commit is excluded from validation by the
+// skip settings (merge commits, fixup commits, authors, committers, subjects).
+// Merge commits are checked last, as they are still validated by no_merge_commits
+// rules unless skipped for another reason.
+func commitSkipReason(config *Config, commit *object.Commit) string {
+	// Skip fixup! and squash! commits if configured
+	if isEnabled(config.Settings.SkipFixupCommits) && isFixupCommit(commit.Message) {
+		return "skip_fixup_commits"
+	}
+
+	// Skip by author or committer pattern if configured, e.g. for bot pushes
+	// committed on behalf of a user
+	if shouldSkipAuthor(commit.Author.Name, commit.Author.Email, config.Settings.skipAuthorRegexes) {
+		return "skip_authors"
+	}
+
+	if shouldSkipAuthor(commit.Committer.Name, commit.Committer.Email, config.Settings.skipCommitterRegexes) {
+		return "skip_committers"
+	}
+
+	// Skip by subject pattern if configured (e.g. generated revert commits)
+	if shouldSkipSubject(commit.Message, config.Settings.skipSubjectRegexes) {
+		return "skip_subjects"
+	}
+
+	// Skip merge commits if configured
+	if isEnabled(config.Settings.SkipMergeCommits) && len(commit.ParentHashes) > 1 {
+		return skipMergeCommitsReason
+	}
+
+	return ""
+}
+
+// reportNoCommits tells the user that ref has no commits to validate, e.g. for
+// a push without new commits.
+func (r *runner) reportNoCommits(ref string) {
+	_, _ = fmt.Fprintf(r.stderr, "No commits to check in %s\n", ref)
+}
+
+// logf writes a progress line to stderr in verbose mode.
+func (r *runner) logf(format string, args ...any) {
+	if !r.verbose {
+		return
+	}
+
+	_, _ = fmt.Fprintf(r.stderr, format+"\n", args...)
+}
+
+// newDebugLogger returns the logger for the troubleshooting logs of --debug,
+// which writes structured lines to w if enabled and discards them otherwise.
+func newDebugLogger(w io.Writer, enabled bool) *slog.Logger {
+	if !enabled {
+		return slog.New(slog.DiscardHandler)
+	}
+
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		AddSource:   false,
+		Level:       slog.LevelDebug,
+		ReplaceAttr: nil,
+	}))
+}
+
+// logCommit writes the result of validating a commit to stderr in verbose mode.
+// The status is one of skip, pass, warn, or fail.
+func (r *runner) logCommit(commit *object.Commit, status string) {
+	r.logf("%s %s %s", status, commit.Hash.String()[:7], getFirstLine(commit.Message))
+}
+
+// fails reports whether any of the violations has at least the severity given
+// with --fail-on, i.e. fails the run unless in dry-run mode.
+func (r *runner) fails(violations []RuleViolation) bool {
+	return failsAt(violations, r.failOn)
+}
+
+// failsAt reports whether any of the violations has at least the severity failOn.
+func failsAt(violations []RuleViolation, failOn Severity) bool {
+	if failOn == SeverityWarning {
+		return len(violations) > 0
+	}
+
+	return hasErrors(violations)
+}
+
+// limitViolations returns the violations to report. In fail-fast mode only the
+// first error-level violation is reported if there is one.
+func limitViolations(config *Config, violations []RuleViolation) []RuleViolation {
+	if !isEnabled(config.Settings.FailFast) {
+		return violations
+	}
+
+	for i, v := range violations {
+		if v.Severity == SeverityError {
+			return violations[i : i+1]
+		}
+	}
+
+	return violations
+}
+
+// runArgsMode validates commits between base and head refs/SHAs.
+// A base ref of "@{upstream}" (or "@{u}") is replaced by the upstream tracking
+// branch of the head branch, or the main ref if it has no upstream.
+func (r *runner) runArgsMode(baseRef string, headRef string) error {
+	if baseRef == upstreamRevision || baseRef == upstreamRevisionShort {
+		baseRef = r.upstreamBase(headRef)
+	}
+
+	refName := fmt.Sprintf("%s..%s", baseRef, headRef)
+
+	// Resolve base and head to commits, without the main ref as base all commits of head are validated
+	baseCommit, err := resolveRefOrSHA(r.repo, baseRef)
+	if err != nil {
+		if baseRef != r.config.Settings.MainRef {
+			return err
+		}
+
+		if !r.fallbackMissingMainRef(&missingMainRefError{ref: baseRef, err: err}, refName) {
+			return fmt.Errorf("%w (hint: use --base-ref to specify a different base)", err)
+		}
+	}
+
+	headCommit, err := resolveRefOrSHA(r.repo, headRef)
+	if err != nil {
+		return err
+	}
+
+	// Get commits in range base..head, or all commits of head without a base
+	var commits []*object.Commit
+
+	commitRange := headCommit.Hash.String()
+	if baseCommit == nil {
+		commits, err = getCommitsUpTo(r.repo, commitRange, r.config.Settings.MaxCommits)
+	} else {
+		r.debug.Debug("resolved refs", "base_ref", baseRef, "base", baseCommit.Hash.String(), "head_ref", headRef,
+			"head", headCommit.Hash.String())
+
+		commitRange = baseCommit.Hash.String() + ".." + commitRange
+		commits, err = getCommitsInRange(
+			r.repo, r.ancestors, baseCommit.Hash.String(), headCommit.Hash.String(), r.config.Settings.MaxCommits,
+		)
+	}
+
+	if r.skipMissingCommits(err, refName) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to get commits: %w", err)
+	}
+
+	r.debug.Debug("walked commit range", "range", commitRange, "commits", len(commits))
+
+	// Base and head are the same commit (or head is an ancestor of base)
+	if len(commits) == 0 {
+		r.reportNoCommits(refName)
+		return nil
+	}
+
+	// The head ref names the branch the commits are validated for
+	r.branch = plumbing.ReferenceName(headRef).Short()
+	if headRef == "HEAD" {
+		r.branch = currentBranch(r.repo)
+	}
+
+	// Validate commits
+	return r.validateCommits(commits, refName)
+}
+
+// runSinceMode validates the commits reachable from HEAD whose author date is at
+// or after since.
+func (r *runner) runSinceMode(since time.Time) error {
+	head, err := resolveRefOrSHA(r.repo, "HEAD")
+	if err != nil {
+		return err
+	}
+
+	commits, err := getCommitsSince(head, since, r.config.Settings.MaxCommits)
+	if err != nil {
+		return fmt.Errorf("failed to get commits: %w", err)
+	}
+
+	r.debug.Debug("walked commits since", "head", head.Hash.String(), "since", since, "commits", len(commits))
+
+	r.branch = currentBranch(r.repo)
+
+	return r.validateCommits(commits, "HEAD")
+}
+
+// stripCommentLines removes lines starting with commentChar from a commit message
+// and truncates the message at the scissors line.
+// Git adds comment lines (e.g. hints, status) to the commit message file; these must
+// be stripped before linting so they do not trigger rule violations. With
+// commit.verbose, git appends the diff below a scissors line, which is dropped as well.
+func stripCommentLines(msg string, commentChar string) string {
+	scissorsLine := commentChar + scissorsMarker
+
+	lines := strings.Split(msg, "\n")
+	filtered := lines[:0]
 
-// validateCommits validates a list of commits against configured rules.
-func validateCommits(config *Config, commits []*object.Commit, refName string) error {
-	for _, commit := range commits {
-		// Skip merge commits if configured
-		if config.Settings.SkipMergeCommits != nil && *config.Settings.SkipMergeCommits &&
-			len(commit.ParentHashes) > 1 {
-			continue
+	for _, line := range lines {
+		if line == scissorsLine {
+			break
 		}
 
-		// Skip by author pattern if configured
-		if shouldSkipAuthor(commit.Author.Name, commit.Author.Email, config.Settings.SkipAuthors) {
-			continue
+		if !strings.HasPrefix(line, commentChar) {
+			filtered = append(filtered, line)
 		}
+	}
 
-		// Parse commit message
-		parsed := ParseCommitMessage(commit.Message)
+	return strings.Join(filtered, "\n")
+}
 
-		// Evaluate all rules
-		violations := EvaluateRules(config.Rules, parsed)
+// commentCharFor returns the comment char to strip in commit-msg hook mode:
+// the comment_char setting if set, otherwise git's core.commentChar, falling
+// back to '#'.
+func commentCharFor(config *Config, repo *git.Repository) string {
+	if config.Settings.CommentChar != "" {
+		return config.Settings.CommentChar
+	}
 
-		if len(violations) > 0 {
-			// In fail-fast mode, only show the first violation
-			violationsToShow := violations
-			if config.Settings.FailFast {
-				violationsToShow = violations[:1]
-			}
+	repoConfig, err := repo.Config()
+	if err != nil {
+		return defaultCommentChar
+	}
 
-			return formatViolationError(commit, refName, violationsToShow)
-		}
+	// "auto" lets git pick a char not used in the message, which can not be
+	// reproduced here, so it falls back to the default as well.
+	commentChar := repoConfig.Raw.Section("core").Option("commentChar")
+	if commentChar == "" || commentChar == "auto" {
+		return defaultCommentChar
 	}
 
-	return nil
+	return commentChar
 }
 
-// runArgsMode validates commits between base and head refs/SHAs.
-func runArgsMode(config *Config, repo *git.Repository, baseRef string, headRef string) error {
-	// Resolve base and head to commits
-	baseCommit, err := resolveRefOrSHA(repo, baseRef)
-	if err != nil {
-		if baseRef == config.Settings.MainRef {
-			return fmt.Errorf("%w (hint: use --base-ref to specify a different base)", err)
-		}
-
-		return err
+// upstreamBase returns the short name of the remote-tracking ref of the upstream
+// of the head branch, e.g. "origin/develop", as configured with
+// branch.<name>.remote and branch.<name>.merge. It falls back to the main ref if
+// the head is not a branch or the branch has no upstream.
+func (r *runner) upstreamBase(headRef string) string {
+	branch := plumbing.ReferenceName(headRef).Short()
+	if headRef == "HEAD" {
+		branch = currentBranch(r.repo)
 	}
 
-	headCommit, err := resolveRefOrSHA(repo, headRef)
+	cfg, err := r.repo.Config()
 	if err != nil {
-		return err
+		return r.config.Settings.MainRef
 	}
 
-	// Get commits in range base..head
-	commits, err := getCommitsInRange(repo, baseCommit.Hash.String(), headCommit.Hash.String())
-	if err != nil {
-		return fmt.Errorf("failed to get commits: %w", err)
+	branchCfg, ok := cfg.Branches[branch]
+	if !ok || branchCfg.Merge == "" {
+		r.logf("No upstream configured for %s, using %s as base", headRef, r.config.Settings.MainRef)
+		return r.config.Settings.MainRef
 	}
 
-	// Validate commits
-	refName := fmt.Sprintf("%s..%s", baseRef, headRef)
-	return validateCommits(config, commits, refName)
-}
+	// A remote of "." tracks a local branch
+	if branchCfg.Remote == "" || branchCfg.Remote == "." {
+		return branchCfg.Merge.Short()
+	}
 
-// stripCommentLines removes lines starting with '#' from a commit message.
-// Git adds comment lines (e.g. hints, status) to the commit message file; these must
-// be stripped before linting so they do not trigger rule violations.
-func stripCommentLines(msg string) string {
-	lines := strings.Split(msg, "\n")
-	filtered := lines[:0]
+	return plumbing.NewRemoteReferenceName(branchCfg.Remote, branchCfg.Merge.Short()).Short()
+}
 
-	for _, line := range lines {
-		if !strings.HasPrefix(line, "#") {
-			filtered = append(filtered, line)
-		}
+// currentBranch returns the short name of the checked out branch, or an empty
+// string if HEAD is detached or can not be resolved.
+func currentBranch(repo *git.Repository) string {
+	head, err := repo.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return ""
 	}
 
-	return strings.Join(filtered, "\n")
+	return head.Name().Short()
 }
 
 // isMergeInProgress reports whether a merge is currently in progress by checking
@@ -272,11 +1352,13 @@ func isMergeInProgress(repo *git.Repository) bool {
 
 // runCommitMsgHookMode validates a single commit message read from msgFilePath.
 // This is used when the binary is invoked as a git commit-msg hook.
-// Note: skip_authors is not evaluated in this mode because the commit author is
-// not yet determined at commit-msg hook time.
-func runCommitMsgHookMode(config *Config, repo *git.Repository, msgFilePath string) error {
+// Note: skip_authors and skip_committers are not evaluated in this mode because
+// the commit author and committer are not yet determined at commit-msg hook time.
+func (r *runner) runCommitMsgHookMode(msgFilePath string) error {
+	config := r.config
+
 	// Skip merge commits if configured
-	if config.Settings.SkipMergeCommits != nil && *config.Settings.SkipMergeCommits && isMergeInProgress(repo) {
+	if isEnabled(config.Settings.SkipMergeCommits) && isMergeInProgress(r.repo) {
 		return nil
 	}
 
@@ -285,105 +1367,405 @@ func runCommitMsgHookMode(config *Config, repo *git.Repository, msgFilePath stri
 		return fmt.Errorf("failed to read commit message file: %w", err)
 	}
 
-	message := stripCommentLines(string(msgBytes))
+	message := stripCommentLines(string(msgBytes), commentCharFor(config, r.repo))
+
+	// Skip fixup! and squash! commits and by subject pattern if configured
+	if (isEnabled(config.Settings.SkipFixupCommits) && isFixupCommit(message)) ||
+		shouldSkipSubject(message, config.Settings.skipSubjectRegexes) {
+		return nil
+	}
+
 	parsed := ParseCommitMessage(message)
-	violations := EvaluateRules(config.Rules, parsed)
+	r.branch = currentBranch(r.repo)
+	ctx := r.ruleContext(nil)
+	ctx.skippedRules = r.skippedRules(parsed, msgFilePath)
+
+	violations := evaluateRules(config.Rules, parsed, ctx, isEnabled(config.Settings.FailFast))
+
+	return r.reportMessageViolations(msgFilePath, violations)
+}
+
+// runTextMode validates a single commit message passed on the command line,
+// source names the flag it was passed with.
+// No repository is involved, so built-in rules depending on the commit or the
+// repository are skipped.
+func (r *runner) runTextMode(text, source string) error {
+	return r.reportMessageViolations(source, Lint(r.config, text))
+}
+
+// reportMessageViolations reports the violations of a single commit message that
+// is not (yet) a commit, e.g. read from a file. The source names the origin of
+// the message in the report.
+func (r *runner) reportMessageViolations(source string, violations []RuleViolation) error {
+	violationsToShow := limitViolations(r.config, violations)
+	r.summary.record(violationsToShow)
+
+	if len(violationsToShow) == 0 {
+		return nil
+	}
+
+	if r.format.machineReadable() {
+		r.recordViolations("", "", violationsToShow)
+
+		if r.fails(violationsToShow) && !r.dryRun {
+			return violationErrorf("commit message in %s failed validation", source)
+		}
 
-	if len(violations) == 0 {
 		return nil
 	}
 
-	violationsToShow := violations
-	if config.Settings.FailFast {
-		violationsToShow = violations[:1]
+	// Dry-run only suppresses the error, the violations are still reported
+	if !r.fails(violationsToShow) || r.dryRun {
+		_, _ = fmt.Fprint(r.report, formatMessageReport(r.palette, source, violationsToShow))
+		return nil
 	}
 
-	return formatMessageViolationError(msgFilePath, violationsToShow)
+	return formatMessageViolationError(r.palette, source, violationsToShow)
 }
 
 // Run validates commit messages.
 // Mode is auto-detected from the arguments:
+//   - If the first argument is test followed by a YAML file: test mode (validate the fixture messages)
 //   - If --base-ref / --head-ref flags are present: CI mode (validate commit range)
-//   - If args[1] is an existing file: commit-msg hook mode (validate that file)
+//   - If the --message-file flag is present: commit-msg hook mode (validate that file)
+//   - If the --text flag is present: text mode (validate the given message, no repository required)
+//   - If the first positional argument is an existing file: commit-msg hook mode (validate that file)
 //   - Otherwise: pre-push hook mode (read refs from stdin)
+//
+// The --config flag overrides the location of the configuration file in all modes.
+// The --verbose flag prints each ref range and commit being validated to stderr.
+// The --debug flag writes troubleshooting logs, e.g. the resolved config path and
+// commit ranges, to stderr.
+// The --dry-run flag reports all violations, but never fails because of them.
+// The --fail-on flag sets the minimum severity of a violation failing the run,
+// error (default) or warning.
+// The --as-ref flag applies the overrides configured for the given ref in all modes.
+// With --format json or --format gitlab, the violations are written to stdout as a
+// JSON report instead of the human-readable report.
+// The --output flag writes the report of any format to the given file instead; a
+// report that can not be written is returned as *OutputError.
+//
+// Warning-level violations are written to stderr and do not cause an error.
+// Error-level violations are returned as *ViolationError, all other failures
+// (e.g. invalid arguments or configuration) as *ConfigError.
 func Run(stdin io.Reader, args []string) error {
-	// Load configuration from .commit-msg-lint.yml
-	config, err := LoadConfig(currentDir)
+	opts, err := parseArgs(args)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return classifyError(err)
+	}
+
+	return classifyError(run(stdin, opts, os.Stdout, os.Stderr))
+}
+
+// RunOptions configures the arguments and streams of RunWith.
+type RunOptions struct {
+	// Args are the command-line arguments, including the program name.
+	Args []string
+	// Stdin provides the git pre-push hook input.
+	Stdin io.Reader
+	// Stdout receives machine-readable reports (e.g. JSON).
+	Stdout io.Writer
+	// Stderr receives warnings and the report of a failed validation.
+	Stderr io.Writer
+	// ErrorPrefix is written before the report of a failed validation, e.g. "Error: ".
+	ErrorPrefix string
+}
+
+// RunWith validates commit messages like Run, but uses the streams given in opts
+// instead of the OS streams. Nil writers discard their output. If the validation
+// fails, the error is also written to Stderr, prefixed with ErrorPrefix, so callers
+// embedding the linter only need to act on the returned error (e.g. exit code).
+// With --quiet, nothing is written at all.
+func RunWith(opts RunOptions) error {
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = io.Discard
 	}
 
-	// Apply default for main_ref if not explicitly set
-	if config.Settings.MainRef == "" {
-		config.Settings.MainRef = defaultMainRef
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = io.Discard
 	}
 
-	// Parse command-line arguments
-	baseRef, headRef, err := parseArgs(config, args)
+	runOpts, err := parseArgs(opts.Args)
+	if err == nil {
+		if runOpts.quiet {
+			stderr = io.Discard
+		}
+
+		err = run(opts.Stdin, runOpts, stdout, stderr)
+	}
+
+	err = classifyError(err)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "%s%v\n", opts.ErrorPrefix, err)
+	}
+
+	return err
+}
+
+// run validates commit messages like Run with the parsed options, writing to the
+// given streams.
+func run(stdin io.Reader, opts options, stdout io.Writer, stderr io.Writer) error {
+	// Quiet mode only reports the result by the returned error
+	if opts.quiet {
+		stdout = io.Discard
+		stderr = io.Discard
+	}
+
+	// Load configuration from --config or .commit-msg-lint.yml
+	debug := newDebugLogger(stderr, opts.debug)
+
+	config, configPath, err := loadConfig(opts, stderr)
 	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	debug.Debug("loaded config", "path", configPath, "rules", len(config.Rules))
+
+	// The config is validated while loading, no repository is required
+	if opts.checkConfig {
+		_, _ = fmt.Fprintln(stderr, "Configuration is valid")
+		return nil
+	}
+
+	// The rules are listed like they are evaluated, i.e. for --as-ref with its overrides
+	if opts.listRules {
+		if opts.asRef != "" {
+			config = configForRef(config, opts.asRef)
+		}
+
+		return writeRuleList(config.Rules, opts.format, stdout)
+	}
+
+	// The sample messages are validated like --text, no repository is required
+	if opts.testFile != "" {
+		if opts.asRef != "" {
+			config = configForRef(config, opts.asRef)
+		}
+
+		return runTestMode(config, opts.testFile, opts.failOn, stderr)
+	}
+
+	// Linting is disabled after loading the config, so config errors are still reported
+	disabled, err := lintingDisabled(config, stderr)
+	if err != nil || disabled {
 		return err
 	}
 
+	// Run manually in a terminal, there is no hook input: validate the current branch
+	if isManualRun(opts, stdin) {
+		opts.headRef = "HEAD"
+	}
+
+	applyMainRefDefault(config)
+	applyRefDefaults(config, &opts)
+
+	// Evaluate as if pushed to --as-ref, applying that ref's overrides in all modes
+	if opts.asRef != "" {
+		config = configForRef(config, opts.asRef)
+	}
+
 	// Apply default for skip_merge_commits if not explicitly set in config
 	if config.Settings.SkipMergeCommits == nil {
 		defaultTrue := true
 		config.Settings.SkipMergeCommits = &defaultTrue
 	}
 
-	repo, err := git.PlainOpen(currentDir)
+	// The --output file replaces the stream of the report of the format mode
+	report, palette := stderr, newPalette(stderr, opts.noColor)
+
+	var output *reportFile
+	if opts.output != "" {
+		output, err = createReportFile(opts.output)
+		if err != nil {
+			return err
+		}
+
+		report, palette = output, newPalette(output, opts.noColor)
+		if opts.format.machineReadable() {
+			stdout = output
+		}
+	}
+
+	r := &runner{
+		config:           config,
+		repo:             nil,
+		format:           opts.format,
+		branch:           "",
+		targetBranch:     plumbing.ReferenceName(opts.asRef).Short(),
+		verbose:          opts.verbose,
+		debug:            debug,
+		dryRun:           opts.dryRun,
+		failOn:           opts.failOn,
+		stdout:           stdout,
+		stderr:           stderr,
+		report:           report,
+		palette:          palette,
+		jsonViolations:   nil,
+		summary:          runSummary{},
+		ancestors:        nil,
+		mergeBases:       nil,
+		validatedCommits: nil,
+	}
+
+	runErr := r.withSummary(r.dispatch(opts, stdin))
+
+	err = r.writeReport()
+
+	// Failing writes to the --output file are reported by close
+	if output != nil {
+		return output.close(r.format, runErr)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return runErr
+}
+
+// dispatch runs the validation mode selected by the options.
+// The repository is opened for all modes except text mode.
+func (r *runner) dispatch(opts options, stdin io.Reader) error {
+	if opts.text != "" {
+		// Text mode: validate the message in memory
+		return r.runTextMode(opts.text, opts.textSource)
+	}
+
+	repo, err := openRepository()
 	if err != nil {
 		return fmt.Errorf("failed to open git repository: %w", err)
 	}
 
-	// Dispatch based on input mode
-	if headRef != "" {
+	r.repo = repo
+
+	if opts.headRef != "" {
 		// CI mode: validate between base and head refs
-		return runArgsMode(config, repo, baseRef, headRef)
+		return r.runArgsMode(opts.baseRef, opts.headRef)
+	}
+
+	if !opts.since.IsZero() {
+		// Audit mode: validate the recent commits of HEAD
+		return r.runSinceMode(opts.since)
+	}
+
+	if opts.messageFile != "" {
+		// Commit-msg hook mode with an explicit message file
+		return r.runCommitMsgHookMode(opts.messageFile)
 	}
 
 	// Auto-detect commit-msg hook mode: git always passes the commit message file as a
 	// path with a directory component (e.g. .git/COMMIT_EDITMSG). The basename may also
 	// match a known git commit message filename for invocations without a path separator.
 	// Remote names used by pre-push hooks (e.g. "origin") have neither property.
-	if len(args) >= 2 && (filepath.Dir(args[1]) != currentDir || isKnownCommitMsgBasename(filepath.Base(args[1]))) {
-		info, statErr := os.Stat(args[1])
-		if statErr == nil && info.Mode().IsRegular() {
-			return runCommitMsgHookMode(config, repo, args[1])
+	if len(opts.positional) >= 1 {
+		msgFile := opts.positional[0]
+		if filepath.Dir(msgFile) != currentDir || isKnownCommitMsgBasename(filepath.Base(msgFile)) {
+			info, statErr := os.Stat(msgFile)
+			if statErr == nil && info.Mode().IsRegular() {
+				return r.runCommitMsgHookMode(msgFile)
+			}
 		}
 	}
 
 	// Pre-push hook mode: read from stdin
-	return runStdinMode(config, repo, stdin)
+	return r.runStdinMode(stdin)
 }
 
 // RunPrePushHook validates commits from git pre-push hook input on stdin.
 // Use this entry point when the binary is explicitly deployed as a pre-push hook,
-// bypassing the auto-detection in Run.
+// bypassing the auto-detection in Run. Errors are classified like in Run.
 func RunPrePushHook(stdin io.Reader, _ []string) error {
-	config, err := LoadConfig(currentDir)
+	r, err := newHookRunner(os.Stdout, os.Stderr)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
+	}
+
+	disabled, err := lintingDisabled(r.config, r.stderr)
+	if err != nil {
+		return &ConfigError{Err: err}
+	}
+
+	if disabled {
+		return nil
+	}
+
+	return classifyError(r.withSummary(r.runStdinMode(stdin)))
+}
+
+// RunCommitMsgHook validates the commit message file git passes as the first
+// argument to commit-msg (and prepare-commit-msg) hooks. Use this entry point when
+// the binary is explicitly deployed as a commit-msg hook, bypassing the
+// auto-detection in Run. Neither stdin is read nor refs are resolved. Errors are
+// classified like in Run.
+func RunCommitMsgHook(args []string) error {
+	if len(args) < 2 || args[1] == "" {
+		return &ConfigError{Err: errors.New("commit message file argument is required")}
+	}
+
+	r, err := newHookRunner(os.Stdout, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	disabled, err := lintingDisabled(r.config, r.stderr)
+	if err != nil {
+		return &ConfigError{Err: err}
 	}
 
-	if config.Settings.MainRef == "" {
-		config.Settings.MainRef = defaultMainRef
+	if disabled {
+		return nil
+	}
+
+	return classifyError(r.runCommitMsgHookMode(args[1]))
+}
+
+// newHookRunner loads the default config (see loadDefaultConfig) and opens the
+// repository containing the current directory for the explicit hook entry points.
+// Reports are written to stdout and stderr like in Run.
+func newHookRunner(stdout io.Writer, stderr io.Writer) (*runner, error) {
+	config, _, err := loadDefaultConfig()
+	if err != nil {
+		return nil, &ConfigError{Err: fmt.Errorf("failed to load config: %w", err)}
 	}
 
+	applyMainRefDefault(config)
+
 	if config.Settings.SkipMergeCommits == nil {
 		defaultTrue := true
 		config.Settings.SkipMergeCommits = &defaultTrue
 	}
 
-	repo, err := git.PlainOpen(currentDir)
+	repo, err := openRepository()
 	if err != nil {
-		return fmt.Errorf("failed to open git repository: %w", err)
+		return nil, &ConfigError{Err: fmt.Errorf("failed to open git repository: %w", err)}
 	}
 
-	return runStdinMode(config, repo, stdin)
+	return &runner{
+		config:           config,
+		repo:             repo,
+		format:           formatText,
+		branch:           "",
+		targetBranch:     "",
+		verbose:          false,
+		debug:            newDebugLogger(stderr, false),
+		dryRun:           false,
+		failOn:           SeverityError,
+		stdout:           stdout,
+		stderr:           stderr,
+		report:           stderr,
+		palette:          newPalette(stderr, false),
+		jsonViolations:   nil,
+		summary:          runSummary{},
+		ancestors:        nil,
+		mergeBases:       nil,
+		validatedCommits: nil,
+	}, nil
 }
 
 // checkCommits validates all commits in the range against configured rules.
-func checkCommits(config *Config, repo *git.Repository, commitRange string, ref string) error {
+func (r *runner) checkCommits(commitRange string, ref string) error {
 	// Parse the commit range
 	var commits []*object.Commit
 	var err error
@@ -394,25 +1776,91 @@ func checkCommits(config *Config, repo *git.Repository, commitRange string, ref
 			return fmt.Errorf("invalid commit range format: %s", commitRange)
 		}
 
-		commits, err = getCommitsInRange(repo, parts[0], parts[1])
+		commits, err = getCommitsInRange(r.repo, r.ancestors, parts[0], parts[1], r.config.Settings.MaxCommits)
 	} else {
 		// Single commit format: get all commits up to this one
-		commits, err = getCommitsUpTo(repo, commitRange)
+		commits, err = getCommitsUpTo(r.repo, commitRange, r.config.Settings.MaxCommits)
+	}
+
+	if r.skipMissingCommits(err, ref) {
+		return nil
 	}
 
 	if err != nil {
 		return fmt.Errorf("failed to get commits: %w", err)
 	}
 
+	r.debug.Debug("walked commit range", "range", commitRange, "commits", len(commits))
+
+	if len(commits) == 0 {
+		r.reportNoCommits(ref)
+		return nil
+	}
+
 	// Validate commits
-	return validateCommits(config, commits, ref)
+	return r.validateCommits(commits, ref)
+}
+
+// ancestorCache caches the sets of commits reachable from a commit, so the
+// history of a base shared by several ranges is only walked once.
+type ancestorCache struct {
+	sets map[plumbing.Hash]map[plumbing.Hash]bool
+	// walks counts the history walks, i.e. the cache misses.
+	walks int
+}
+
+func newAncestorCache() *ancestorCache {
+	return &ancestorCache{
+		sets:  map[plumbing.Hash]map[plumbing.Hash]bool{},
+		walks: 0,
+	}
+}
+
+// reachable returns the set of commits reachable from (and including) commit.
+// A nil cache walks the history on every call.
+func (c *ancestorCache) reachable(commit *object.Commit) (map[plumbing.Hash]bool, error) {
+	if c != nil {
+		set, ok := c.sets[commit.Hash]
+		if ok {
+			return set, nil
+		}
+	}
+
+	set := map[plumbing.Hash]bool{}
+	iter := object.NewCommitIterCTime(commit, nil, nil)
+	err := iter.ForEach(func(ancestor *object.Commit) error {
+		set[ancestor.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if c != nil {
+		c.sets[commit.Hash] = set
+		c.walks++
+	}
+
+	return set, nil
 }
 
 // getCommitsInRange returns all commits between oldCommit and newCommit (exclusive of oldCommit).
-func getCommitsInRange(repo *git.Repository, oldCommit string, newCommit string) ([]*object.Commit, error) {
+// The commits reachable from oldCommit are looked up in ancestors, if not nil.
+// If maxCommits is greater than zero, more commits are reported as an error.
+func getCommitsInRange(
+	repo *git.Repository,
+	ancestors *ancestorCache,
+	oldCommit string,
+	newCommit string,
+	maxCommits int,
+) ([]*object.Commit, error) {
 	// Get the new commit
 	newHash := plumbing.NewHash(newCommit)
 	newCommitObj, err := repo.CommitObject(newHash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, &missingCommitError{commit: newCommit, history: false, err: err}
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to get new commit %s: %w", newCommit, err)
 	}
@@ -420,31 +1868,50 @@ func getCommitsInRange(repo *git.Repository, oldCommit string, newCommit string)
 	// Get the old commit
 	oldHash := plumbing.NewHash(oldCommit)
 	oldCommitObj, err := repo.CommitObject(oldHash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, &missingCommitError{commit: oldCommit, history: false, err: err}
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to get old commit %s: %w", oldCommit, err)
 	}
 
 	// Create a set of old commits to exclude
-	oldCommits := map[plumbing.Hash]bool{}
-	oldIter := object.NewCommitIterCTime(oldCommitObj, nil, nil)
-	err = oldIter.ForEach(func(c *object.Commit) error {
-		oldCommits[c.Hash] = true
-		return nil
-	})
+	oldCommits, err := ancestors.reachable(oldCommitObj)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, &missingCommitError{commit: oldCommit, history: true, err: err}
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to iterate old commits: %w", err)
 	}
 
 	// Get commits from new that are not in old
 	var commits []*object.Commit
+	exceeded := false
 	newIter := object.NewCommitIterCTime(newCommitObj, nil, nil)
 	err = newIter.ForEach(func(c *object.Commit) error {
-		if !oldCommits[c.Hash] {
-			commits = append(commits, c)
+		if oldCommits[c.Hash] {
+			return nil
 		}
 
+		if exceedsMaxCommits(len(commits), maxCommits) {
+			exceeded = true
+			return storer.ErrStop
+		}
+
+		commits = append(commits, c)
+
 		return nil
 	})
+	if exceeded {
+		return nil, tooManyCommitsError(maxCommits)
+	}
+
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, &missingCommitError{commit: newCommit, history: true, err: err}
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to iterate new commits: %w", err)
 	}
@@ -452,6 +1919,92 @@ func getCommitsInRange(repo *git.Repository, oldCommit string, newCommit string)
 	return commits, nil
 }
 
+// exceedsMaxCommits reports whether collecting another commit after count
+// commits exceeds maxCommits. Zero means unlimited.
+func exceedsMaxCommits(count int, maxCommits int) bool {
+	return maxCommits > 0 && count >= maxCommits
+}
+
+// missingCommitError reports a commit, or a commit of its history, that is not
+// available in the local repository, e.g. in a shallow clone or after a partial
+// fetch.
+type missingCommitError struct {
+	commit string
+	// history is set if an ancestor of commit is missing, not commit itself.
+	history bool
+	err     error
+}
+
+// Error returns an actionable message naming the missing commit.
+func (e *missingCommitError) Error() string {
+	if e.history {
+		return fmt.Sprintf("history of commit %s is incomplete locally; ensure the full history is fetched, "+
+			"e.g. with git fetch --unshallow", e.commit)
+	}
+
+	return fmt.Sprintf("commit %s not found locally; ensure the full history is fetched, e.g. with git fetch --unshallow",
+		e.commit)
+}
+
+// Unwrap returns the underlying error of the object lookup.
+func (e *missingCommitError) Unwrap() error {
+	return e.err
+}
+
+// missingMainRefError reports that the main ref does not exist, e.g. in a fresh
+// repository or after the default branch was renamed.
+type missingMainRefError struct {
+	ref string
+	err error
+}
+
+// Error returns the message of the failed main ref lookup.
+func (e *missingMainRefError) Error() string {
+	return fmt.Sprintf("failed to resolve main ref: %v", e.err)
+}
+
+// Unwrap returns the underlying error of the ref lookup.
+func (e *missingMainRefError) Unwrap() error {
+	return e.err
+}
+
+// fallbackMissingMainRef reports whether all commits of ref are validated because
+// the main ref does not exist, unless the require_main_ref setting is set. The
+// fallback is reported on stderr.
+func (r *runner) fallbackMissingMainRef(err error, ref string) bool {
+	var missingErr *missingMainRefError
+	if isEnabled(r.config.Settings.RequireMainRef) || !errors.As(err, &missingErr) {
+		return false
+	}
+
+	_, _ = fmt.Fprintf(r.stderr, "Warning: main ref %s not found, validating all commits of %s "+
+		"(set main_ref or require_main_ref to change this)\n", missingErr.ref, ref)
+
+	return true
+}
+
+// skipMissingCommits reports whether the validation of ref is skipped because of
+// a missing commit according to the lenient_missing_objects setting. Skipped refs
+// are reported on stderr.
+func (r *runner) skipMissingCommits(err error, ref string) bool {
+	var missingErr *missingCommitError
+	if !isEnabled(r.config.Settings.LenientMissingObjects) || !errors.As(err, &missingErr) {
+		return false
+	}
+
+	_, _ = fmt.Fprintf(r.stderr, "Skipping %s: %v\n", ref, missingErr)
+
+	return true
+}
+
+// tooManyCommitsError reports a commit range exceeding the max_commits setting.
+func tooManyCommitsError(maxCommits int) error {
+	return fmt.Errorf(
+		"more than %d commits to validate (max_commits), use a narrower range, e.g. with --base-ref or --since",
+		maxCommits,
+	)
+}
+
 // isAncestorOf checks if ancestorHash is an ancestor of (or equal to) descendantHash
 // by walking the commit graph from descendant backwards.
 func isAncestorOf(repo *git.Repository, ancestorHash string, descendantHash string) (bool, error) {
@@ -479,22 +2032,79 @@ func isAncestorOf(repo *git.Repository, ancestorHash string, descendantHash stri
 	return found, nil
 }
 
+// getCommitsSince returns the commits reachable from head whose author date is at
+// or after since. The history is walked in commit time order and the walk stops at
+// the first commit committed before since, as commits are not authored after
+// they are committed.
+func getCommitsSince(head *object.Commit, since time.Time, maxCommits int) ([]*object.Commit, error) {
+	var commits []*object.Commit
+	exceeded := false
+	iter := object.NewCommitIterCTime(head, nil, nil)
+	err := iter.ForEach(func(c *object.Commit) error {
+		if c.Committer.When.Before(since) {
+			return storer.ErrStop
+		}
+
+		if c.Author.When.Before(since) {
+			return nil
+		}
+
+		if exceedsMaxCommits(len(commits), maxCommits) {
+			exceeded = true
+			return storer.ErrStop
+		}
+
+		commits = append(commits, c)
+
+		return nil
+	})
+	if exceeded {
+		return nil, tooManyCommitsError(maxCommits)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate commits: %w", err)
+	}
+
+	return commits, nil
+}
+
 // getCommitsUpTo returns all commits up to and including the specified commit.
-func getCommitsUpTo(repo *git.Repository, commitHash string) ([]*object.Commit, error) {
+// If maxCommits is greater than zero, more commits are reported as an error.
+func getCommitsUpTo(repo *git.Repository, commitHash string, maxCommits int) ([]*object.Commit, error) {
 	// Get the commit
 	hash := plumbing.NewHash(commitHash)
 	commitObj, err := repo.CommitObject(hash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, &missingCommitError{commit: commitHash, history: false, err: err}
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to get commit %s: %w", commitHash, err)
 	}
 
 	// Get all commits up to this one
 	var commits []*object.Commit
+	exceeded := false
 	iter := object.NewCommitIterCTime(commitObj, nil, nil)
 	err = iter.ForEach(func(c *object.Commit) error {
+		if exceedsMaxCommits(len(commits), maxCommits) {
+			exceeded = true
+			return storer.ErrStop
+		}
+
 		commits = append(commits, c)
+
 		return nil
 	})
+	if exceeded {
+		return nil, tooManyCommitsError(maxCommits)
+	}
+
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, &missingCommitError{commit: commitHash, history: true, err: err}
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to iterate commits: %w", err)
 	}
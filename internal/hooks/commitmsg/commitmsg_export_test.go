@@ -1,6 +1,8 @@
 package commitmsg
 
 import (
+	"io"
+
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
@@ -8,8 +10,17 @@ import (
 // Test helpers - exported for testing only
 
 // ParseArgsForTesting exposes parseArgs for testing.
+// The main ref and base ref are defaulted like Run does.
 func ParseArgsForTesting(config *Config, args []string) (baseRef string, headRef string, err error) {
-	return parseArgs(config, args)
+	opts, err := parseArgs(args)
+	if err != nil {
+		return "", "", err
+	}
+
+	applyMainRefDefault(config)
+	applyRefDefaults(config, &opts)
+
+	return opts.baseRef, opts.headRef, nil
 }
 
 // ResolveRefOrSHAForTesting exposes resolveRefOrSHA for testing.
@@ -17,12 +28,65 @@ func ResolveRefOrSHAForTesting(repo *git.Repository, refOrSHA string) (*object.C
 	return resolveRefOrSHA(repo, refOrSHA)
 }
 
+// GetCommitsInRangeForTesting exposes getCommitsInRange for testing, without
+// caching and limit.
+func GetCommitsInRangeForTesting(repo *git.Repository, oldCommit string, newCommit string) ([]*object.Commit, error) {
+	return getCommitsInRange(repo, nil, oldCommit, newCommit, 0)
+}
+
 // StripCommentLinesForTesting exposes stripCommentLines for testing.
-func StripCommentLinesForTesting(msg string) string {
-	return stripCommentLines(msg)
+func StripCommentLinesForTesting(msg string, commentChar string) string {
+	return stripCommentLines(msg, commentChar)
 }
 
 // IsMergeInProgressForTesting exposes isMergeInProgress for testing.
 func IsMergeInProgressForTesting(repo *git.Repository) bool {
 	return isMergeInProgress(repo)
 }
+
+// ParseCommitMessageForRulesForTesting exposes parseCommitMessage for testing,
+// parsing only the message parts the rules depend on.
+func ParseCommitMessageForRulesForTesting(message string, rules []Rule) ParsedCommitMessage {
+	return parseCommitMessage(message, messagePartsForRules(rules))
+}
+
+// PrePushHistoryWalksForTesting validates the pre-push hook input on stdin like
+// RunPrePushHook and returns how often the history of a base commit was walked
+// and how often a merge base with the main ref was computed.
+func PrePushHistoryWalksForTesting(config *Config, repo *git.Repository, stdin io.Reader) (int, int, error) {
+	applyMainRefDefault(config)
+
+	r := &runner{
+		config:         config,
+		repo:           repo,
+		format:         formatText,
+		branch:         "",
+		targetBranch:   "",
+		verbose:        false,
+		debug:          newDebugLogger(io.Discard, false),
+		dryRun:         false,
+		stdout:         io.Discard,
+		stderr:         io.Discard,
+		report:         io.Discard,
+		palette:        palette{enabled: false},
+		jsonViolations: nil,
+		ancestors:      nil,
+		mergeBases:     nil,
+	}
+
+	err := r.runStdinMode(stdin)
+
+	return r.ancestors.walks, r.mergeBases.computations, err
+}
+
+// FormatMessageReportForTesting exposes formatMessageReport with colors enabled
+// or disabled.
+func FormatMessageReportForTesting(color bool, source string, violations []RuleViolation) string {
+	return formatMessageReport(palette{enabled: color}, source, violations)
+}
+
+// ColorEnabledForTesting reports whether the text reports written to w are
+// colorized.
+func ColorEnabledForTesting(w io.Writer, noColor bool) bool {
+	return newPalette(w, noColor).enabled
+}
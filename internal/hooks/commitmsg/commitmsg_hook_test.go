@@ -1,6 +1,7 @@
 package commitmsg_test
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,45 +15,75 @@ import (
 
 func TestStripCommentLines(t *testing.T) {
 	tests := []struct {
-		name  string
-		input string
-		want  string
+		name        string
+		input       string
+		commentChar string
+		want        string
 	}{
 		{
-			name:  "no comments",
-			input: "feat: add feature\n\nSome body text",
-			want:  "feat: add feature\n\nSome body text",
+			name:        "no comments",
+			input:       "feat: add feature\n\nSome body text",
+			commentChar: "#",
+			want:        "feat: add feature\n\nSome body text",
+		},
+		{
+			name:        "comment only",
+			input:       "# Please enter a commit message",
+			commentChar: "#",
+			want:        "",
+		},
+		{
+			name:        "mixed lines",
+			input:       "feat: add feature\n# Please enter a commit message\n# Changes:\n#\tmodified: file.go\n",
+			commentChar: "#",
+			want:        "feat: add feature\n",
+		},
+		{
+			name:        "empty input",
+			input:       "",
+			commentChar: "#",
+			want:        "",
 		},
 		{
-			name:  "comment only",
-			input: "# Please enter a commit message",
-			want:  "",
+			name:        "comment at end",
+			input:       "feat: add feature\n\nSigned-off-by: Dev <dev@example.com>\n# On branch main\n",
+			commentChar: "#",
+			want:        "feat: add feature\n\nSigned-off-by: Dev <dev@example.com>\n",
 		},
 		{
-			name:  "mixed lines",
-			input: "feat: add feature\n# Please enter a commit message\n# Changes:\n#\tmodified: file.go\n",
-			want:  "feat: add feature\n",
+			name:        "line with hash in body is preserved",
+			input:       "feat: add feature\n\nSee issue #42 for context",
+			commentChar: "#",
+			want:        "feat: add feature\n\nSee issue #42 for context",
 		},
 		{
-			name:  "empty input",
-			input: "",
-			want:  "",
+			name: "verbose diff below scissors line is dropped",
+			input: "feat: add feature\n\nSome body text\n# Please enter a commit message\n" +
+				"# ------------------------ >8 ------------------------\n" +
+				"# Do not modify or remove the line above.\n" +
+				"diff --git a/file.go b/file.go\n+WIP: not part of the message\n",
+			commentChar: "#",
+			want:        "feat: add feature\n\nSome body text",
 		},
 		{
-			name:  "comment at end",
-			input: "feat: add feature\n\nSigned-off-by: Dev <dev@example.com>\n# On branch main\n",
-			want:  "feat: add feature\n\nSigned-off-by: Dev <dev@example.com>\n",
+			name:        "custom comment char",
+			input:       "feat: add feature\n\n#42 is fixed\n; Please enter a commit message\n",
+			commentChar: ";",
+			want:        "feat: add feature\n\n#42 is fixed\n",
 		},
 		{
-			name:  "line with hash in body is preserved",
-			input: "feat: add feature\n\nSee issue #42 for context",
-			want:  "feat: add feature\n\nSee issue #42 for context",
+			name: "scissors line with custom comment char",
+			input: "feat: add feature\n" +
+				"; ------------------------ >8 ------------------------\n" +
+				"diff --git a/file.go b/file.go\n",
+			commentChar: ";",
+			want:        "feat: add feature",
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := commitmsg.StripCommentLinesForTesting(tc.input)
+			got := commitmsg.StripCommentLinesForTesting(tc.input, tc.commentChar)
 			if got != tc.want {
 				t.Errorf("StripCommentLines() = %q, want %q", got, tc.want)
 			}
@@ -135,6 +166,30 @@ func TestRunCommitMsgHook(t *testing.T) {
 			wantErr:       true,
 			description:   "Missing required sign-off should fail",
 		},
+		{
+			name: "verbose diff below scissors line is ignored",
+			config: `rules:
+  - name: require-signoff
+    type: require
+    scope: footer
+    pattern: '^Signed-off-by:'
+`,
+			messageInFile: "feat: add feature\n\nSigned-off-by: Dev <dev@example.com>\n" +
+				"# ------------------------ >8 ------------------------\n" +
+				"# Do not modify or remove the line above.\n" +
+				"diff --git a/file.go b/file.go\n",
+			wantErr:     false,
+			description: "Diff content below the scissors line must not be treated as the footer",
+		},
+		{
+			name: "comment_char setting",
+			config: defaultWIPConfig + `settings:
+  comment_char: ";"
+`,
+			messageInFile: "feat: add feature\n; WIP: left over from the template\n",
+			wantErr:       false,
+			description:   "Lines starting with the configured comment char should be stripped",
+		},
 	}
 
 	for _, tc := range tests {
@@ -292,6 +347,78 @@ func TestRunPrePushHook(t *testing.T) {
 	}
 }
 
+func TestRunCommitMsgHookEntryPoint(t *testing.T) {
+	tests := []struct {
+		name          string
+		messageInFile string
+		extraArgs     []string
+		wantErr       bool
+	}{
+		{
+			name:          "valid message passes",
+			messageInFile: "feat: add feature\n",
+			extraArgs:     nil,
+			wantErr:       false,
+		},
+		{
+			name:          "WIP message rejected",
+			messageInFile: "WIP: debugging\n",
+			extraArgs:     nil,
+			wantErr:       true,
+		},
+		{
+			name:          "prepare-commit-msg arguments are ignored",
+			messageInFile: "feat: add feature\n",
+			extraArgs:     []string{"message"},
+			wantErr:       false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir, _, _ := createTestRepo(t, nil)
+			writeConfigFile(t, tmpDir, defaultWIPConfig)
+			t.Chdir(tmpDir)
+
+			msgFile := filepath.Join(tmpDir, ".git", "COMMIT_EDITMSG")
+			writeErr := os.WriteFile(msgFile, []byte(tc.messageInFile), 0o644)
+			if writeErr != nil {
+				t.Fatalf("failed to write message file: %v", writeErr)
+			}
+
+			args := append([]string{"commit-msg-lint-commitmsg", msgFile}, tc.extraArgs...)
+			err := commitmsg.RunCommitMsgHook(args)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("RunCommitMsgHook() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			var violationErr *commitmsg.ViolationError
+			if err != nil && !errors.As(err, &violationErr) {
+				t.Errorf("RunCommitMsgHook() error = %T, want *ViolationError", err)
+			}
+		})
+	}
+}
+
+func TestRunCommitMsgHookMissingFile(t *testing.T) {
+	tmpDir, _, _ := createTestRepo(t, nil)
+	writeConfigFile(t, tmpDir, defaultWIPConfig)
+	t.Chdir(tmpDir)
+
+	var configErr *commitmsg.ConfigError
+
+	err := commitmsg.RunCommitMsgHook([]string{"commit-msg-lint-commitmsg"})
+	if !errors.As(err, &configErr) {
+		t.Errorf("RunCommitMsgHook() without file error = %v, want *ConfigError", err)
+	}
+
+	err = commitmsg.RunCommitMsgHook([]string{"commit-msg-lint-commitmsg", filepath.Join(tmpDir, "missing")})
+	if !errors.As(err, &configErr) {
+		t.Errorf("RunCommitMsgHook() with missing file error = %v, want *ConfigError", err)
+	}
+}
+
 const noCoAuthoredByAgentConfig = `rules:
   - name: no-co-authored-by-agent
     type: deny
@@ -483,3 +610,62 @@ func TestAutoDetect(t *testing.T) {
 		}
 	})
 }
+
+func TestRunMessageFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		messageInFile string
+		wantErr       bool
+	}{
+		{
+			name:          "valid message passes",
+			messageInFile: "feat: add feature\n",
+			wantErr:       false,
+		},
+		{
+			name:          "WIP message rejected",
+			messageInFile: "WIP: debugging\n",
+			wantErr:       true,
+		},
+		{
+			name:          "git comments stripped before linting",
+			messageInFile: "feat: add feature\n# WIP: part of the template\n",
+			wantErr:       false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir, _, _ := createTestRepo(t, nil)
+			writeConfigFile(t, tmpDir, defaultWIPConfig)
+			t.Chdir(tmpDir)
+
+			// A bare filename is not auto-detected, so this only works through the flag
+			writeErr := os.WriteFile(filepath.Join(tmpDir, "msg.txt"), []byte(tc.messageInFile), 0o644)
+			if writeErr != nil {
+				t.Fatalf("failed to write message file: %v", writeErr)
+			}
+
+			err := commitmsg.Run(nil, []string{"commit-msg-lint", "--message-file", "msg.txt"})
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), "Commit message in msg.txt failed validation") {
+				t.Errorf("Run() error = %q, want the commit-msg violation report", err.Error())
+			}
+		})
+	}
+
+	t.Run("missing message file", func(t *testing.T) {
+		tmpDir, _, _ := createTestRepo(t, nil)
+		writeConfigFile(t, tmpDir, defaultWIPConfig)
+		t.Chdir(tmpDir)
+
+		err := commitmsg.Run(nil, []string{"commit-msg-lint", "--message-file", "missing.txt"})
+		if err == nil || !strings.Contains(err.Error(), "failed to read commit message file") {
+			t.Errorf("Run() error = %v, want read error", err)
+		}
+	})
+}
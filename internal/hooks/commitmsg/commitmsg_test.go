@@ -1,7 +1,10 @@
 package commitmsg_test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,6 +12,7 @@ import (
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 
@@ -22,7 +26,7 @@ type commit struct {
 
 // Helper function to create a test repository with commits.
 func createTestRepo(
-	t *testing.T,
+	t testing.TB,
 	commits []commit,
 ) (string, *git.Repository, []plumbing.Hash) {
 	t.Helper()
@@ -110,7 +114,7 @@ func createTestRepo(
 }
 
 // Helper function to create a test config file.
-func writeConfigFile(t *testing.T, dir string, config string) {
+func writeConfigFile(t testing.TB, dir string, config string) {
 	t.Helper()
 
 	configPath := filepath.Join(dir, commitmsg.DefaultConfigFile)
@@ -500,6 +504,126 @@ func TestParseArgs(t *testing.T) {
 			wantErr:     false,
 			description: "Should accept SHA values in place of ref names",
 		},
+		{
+			name:        "message-file with head-ref - error",
+			args:        []string{"commit-msg-lint", "--message-file", "msg.txt", "--head-ref", "feature"},
+			wantBase:    "",
+			wantHead:    "",
+			wantErr:     true,
+			description: "Should error when a message file is combined with a commit range",
+		},
+		{
+			name:        "invalid fail-on - error",
+			args:        []string{"commit-msg-lint", "--fail-on", "info", "--head-ref", "feature"},
+			wantBase:    "",
+			wantHead:    "",
+			wantErr:     true,
+			description: "Should error when --fail-on is neither error nor warning",
+		},
+		{
+			name:        "text with head-ref - error",
+			args:        []string{"commit-msg-lint", "--text", "feat: add feature", "--head-ref", "feature"},
+			wantBase:    "",
+			wantHead:    "",
+			wantErr:     true,
+			description: "Should error when a text message is combined with a commit range",
+		},
+		{
+			name:        "message with head-ref - error",
+			args:        []string{"commit-msg-lint", "--message", "feat: add feature", "--head-ref", "feature"},
+			wantBase:    "",
+			wantHead:    "",
+			wantErr:     true,
+			description: "Should error when the --text alias is combined with a commit range",
+		},
+		{
+			name:        "list-rules with head-ref - error",
+			args:        []string{"commit-msg-lint", "--list-rules", "--head-ref", "feature"},
+			wantBase:    "",
+			wantHead:    "",
+			wantErr:     true,
+			description: "Should error when listing the rules is combined with a commit range",
+		},
+		{
+			name:        "list-rules with gitlab format - error",
+			args:        []string{"commit-msg-lint", "--list-rules", "--format", "gitlab"},
+			wantBase:    "",
+			wantHead:    "",
+			wantErr:     true,
+			description: "Should error when listing the rules in GitLab Code Quality format",
+		},
+		{
+			name:        "branch - defaults to main..HEAD",
+			args:        []string{"commit-msg-lint", "--branch"},
+			wantBase:    "main",
+			wantHead:    "HEAD",
+			wantErr:     false,
+			description: "Should validate the commits of the current branch that are not on main",
+		},
+		{
+			name:        "branch with head-ref - error",
+			args:        []string{"commit-msg-lint", "--branch", "--head-ref", "feature"},
+			wantBase:    "",
+			wantHead:    "",
+			wantErr:     true,
+			description: "Should error when the current branch is combined with a commit range",
+		},
+		{
+			name:        "text with message - error",
+			args:        []string{"commit-msg-lint", "--text", "feat: add feature", "--message", "fix: bug"},
+			wantBase:    "",
+			wantHead:    "",
+			wantErr:     true,
+			description: "Should error when both --text and its alias are given",
+		},
+		{
+			name:        "quiet with json format - error",
+			args:        []string{"commit-msg-lint", "--quiet", "--format", "json"},
+			wantBase:    "",
+			wantHead:    "",
+			wantErr:     true,
+			description: "Should error when quiet mode is combined with a machine-readable format",
+		},
+		{
+			name:        "quiet with verbose - error",
+			args:        []string{"commit-msg-lint", "--quiet", "--verbose"},
+			wantBase:    "",
+			wantHead:    "",
+			wantErr:     true,
+			description: "Should error when quiet mode is combined with verbose output",
+		},
+		{
+			name:        "quiet with debug - error",
+			args:        []string{"commit-msg-lint", "--quiet", "--debug"},
+			wantBase:    "",
+			wantHead:    "",
+			wantErr:     true,
+			description: "Should error when quiet mode is combined with debug logs",
+		},
+		{
+			name:        "test subcommand with text - error",
+			args:        []string{"commit-msg-lint", "test", "--text", "Add feature", "fixtures.yml"},
+			wantBase:    "",
+			wantHead:    "",
+			wantErr:     true,
+			description: "Should error when the test subcommand is combined with a commit message",
+		},
+		{
+			name:        "test subcommand with extra argument - error",
+			args:        []string{"commit-msg-lint", "test", "other.yml", "fixtures.yml"},
+			wantBase:    "",
+			wantHead:    "",
+			wantErr:     true,
+			description: "Should error when the test subcommand gets more than one fixture file",
+		},
+		{
+			name:        "output with list-rules - error",
+			args:        []string{"commit-msg-lint", "--output", "report.txt", "--list-rules"},
+			wantBase:    "",
+			wantHead:    "",
+			wantErr:     true,
+			description: "Should error when the report file is combined with listing the rules",
+		},
 	}
 
 	for _, testCase := range tests {
@@ -527,6 +651,73 @@ func TestParseArgs(t *testing.T) {
 	}
 }
 
+func TestParseArgsMainRefPrecedence(t *testing.T) {
+	tests := []struct {
+		name          string
+		envMainRef    string
+		configMainRef string
+		args          []string
+		wantBase      string
+	}{
+		{
+			name:          "built-in default",
+			envMainRef:    "",
+			configMainRef: "",
+			args:          []string{"commit-msg-lint", "--head-ref", "feature"},
+			wantBase:      "main",
+		},
+		{
+			name:          "config main_ref",
+			envMainRef:    "",
+			configMainRef: "develop",
+			args:          []string{"commit-msg-lint", "--head-ref", "feature"},
+			wantBase:      "develop",
+		},
+		{
+			name:          "env var over config main_ref",
+			envMainRef:    "trunk",
+			configMainRef: "develop",
+			args:          []string{"commit-msg-lint", "--head-ref", "feature"},
+			wantBase:      "trunk",
+		},
+		{
+			name:          "env var over built-in default",
+			envMainRef:    "trunk",
+			configMainRef: "",
+			args:          []string{"commit-msg-lint", "--head-ref", "feature"},
+			wantBase:      "trunk",
+		},
+		{
+			name:          "base-ref over env var",
+			envMainRef:    "trunk",
+			configMainRef: "develop",
+			args:          []string{"commit-msg-lint", "--base-ref", "release", "--head-ref", "feature"},
+			wantBase:      "release",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Setenv("COMMIT_MSG_LINT_MAIN_REF", testCase.envMainRef)
+
+			config := &commitmsg.Config{
+				Settings: commitmsg.Settings{
+					MainRef: testCase.configMainRef,
+				},
+			}
+
+			base, _, err := commitmsg.ParseArgsForTesting(config, testCase.args)
+			if err != nil {
+				t.Fatalf("parseArgs() returned unexpected error: %v", err)
+			}
+
+			if base != testCase.wantBase {
+				t.Errorf("parseArgs() base = %v, want %v", base, testCase.wantBase)
+			}
+		})
+	}
+}
+
 func TestResolveRefOrSHA(t *testing.T) {
 	// Create a test repository with branches
 	commits := []commit{
@@ -796,3 +987,2180 @@ func TestRebaseForcePush(t *testing.T) {
 		t.Errorf("Run() returned unexpected error (base branch commit should not be validated): %v", err)
 	}
 }
+
+func TestAmendForcePush(t *testing.T) {
+	// The remote accepted "WIP: spike" before the rule was added:
+	//
+	// base (main) -- spike -- feature (remote)
+	//                     \
+	//                      amended (local)
+	tmpDir, repo, hashes := createTestRepo(t, []commit{
+		{message: "WIP: spike", files: map[string]string{"spike.txt": "spike"}},
+		{message: "feat: add feature", files: map[string]string{"feature.txt": "feature"}},
+	})
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	err = worktree.Reset(&git.ResetOptions{Commit: hashes[0], Mode: git.HardReset})
+	if err != nil {
+		t.Fatalf("failed to reset to spike: %v", err)
+	}
+
+	amend := func(message string) plumbing.Hash {
+		t.Helper()
+
+		writeErr := os.WriteFile(filepath.Join(tmpDir, "feature.txt"), []byte(message), 0o644)
+		if writeErr != nil {
+			t.Fatalf("failed to write file: %v", writeErr)
+		}
+
+		_, addErr := worktree.Add("feature.txt")
+		if addErr != nil {
+			t.Fatalf("failed to add file: %v", addErr)
+		}
+
+		hash, commitErr := worktree.Commit(message, &git.CommitOptions{
+			Author: &object.Signature{
+				Name:  "Test User",
+				Email: "test@example.com",
+				When:  time.Now().Add(time.Hour),
+			},
+		})
+		if commitErr != nil {
+			t.Fatalf("failed to commit: %v", commitErr)
+		}
+
+		return hash
+	}
+
+	t.Chdir(tmpDir)
+
+	tests := []struct {
+		name    string
+		message string
+		wantErr bool
+	}{
+		{
+			name:    "accepted history is not validated again",
+			message: "feat: add feature (amended)",
+			wantErr: false,
+		},
+		{
+			name:    "amended commit is validated",
+			message: "WIP: add feature",
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			localOID := amend(testCase.message)
+
+			err := worktree.Reset(&git.ResetOptions{Commit: hashes[0], Mode: git.HardReset})
+			if err != nil {
+				t.Fatalf("failed to reset to spike: %v", err)
+			}
+
+			// The hard reset removes the untracked config file
+			writeConfigFile(t, tmpDir, defaultWIPConfig)
+
+			// Additional fields passed by git wrappers are ignored
+			input := fmt.Sprintf(
+				"refs/heads/feature %s refs/heads/feature %s forced\n",
+				localOID.String(),
+				hashes[1].String(),
+			)
+
+			err = commitmsg.Run(strings.NewReader(input), nil)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			if err != nil && strings.Contains(err.Error(), hashes[0].String()[:7]) {
+				t.Errorf("Run() error = %v, want accepted commit %s not reported", err, hashes[0].String()[:7])
+			}
+		})
+	}
+}
+
+func TestRunWithConfigFlag(t *testing.T) {
+	// A config in the user config dir of the developer must not replace the missing default config
+	setUserConfigDir(t)
+
+	commits := []commit{
+		{
+			message: "feat: add feature",
+			files:   map[string]string{"file1.txt": "content1"},
+		},
+		{
+			message: "WIP: debugging",
+			files:   map[string]string{"file2.txt": "content2"},
+		},
+	}
+
+	tmpDir, _, hashes := createTestRepo(t, commits)
+
+	// Place the config in a non-default location, no default config file present
+	configDir := filepath.Join(tmpDir, "build")
+	err := os.MkdirAll(configDir, 0o755)
+	if err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+
+	configPath := filepath.Join(configDir, "commit-msg.yml")
+	err = os.WriteFile(configPath, []byte(defaultWIPConfig), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	t.Chdir(tmpDir)
+
+	stdinInput := fmt.Sprintf("refs/heads/feature %s refs/heads/feature %s\n", hashes[1].String(), gitZeroHash)
+
+	tests := []struct {
+		name        string
+		args        []string
+		stdin       string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:        "relative config path with refs",
+			args:        []string{"commit-msg-lint", "--config", "build/commit-msg.yml", "--head-ref", hashes[0].String()},
+			wantErr:     false,
+			errContains: "",
+		},
+		{
+			name:        "absolute config path with refs detects violation",
+			args:        []string{"commit-msg-lint", "--config", configPath, "--head-ref", hashes[1].String()},
+			wantErr:     true,
+			errContains: "prevent-wip",
+		},
+		{
+			name:        "config flag in stdin mode",
+			args:        []string{"commit-msg-lint", "--config", "build/commit-msg.yml"},
+			stdin:       stdinInput,
+			wantErr:     true,
+			errContains: "prevent-wip",
+		},
+		{
+			name:        "missing config file",
+			args:        []string{"commit-msg-lint", "--config", "build/missing.yml", "--head-ref", "HEAD"},
+			wantErr:     true,
+			errContains: "config file not found: build/missing.yml",
+		},
+		{
+			name:        "default config file is used when flag is unset",
+			args:        []string{"commit-msg-lint", "--head-ref", "HEAD"},
+			wantErr:     true,
+			errContains: "config file not found",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := commitmsg.Run(strings.NewReader(testCase.stdin), testCase.args)
+
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), testCase.errContains) {
+				t.Errorf("Run() error = %v, want error containing %q", err, testCase.errContains)
+			}
+		})
+	}
+}
+
+func TestRunFromSubdirectory(t *testing.T) {
+	commits := []commit{
+		{
+			message: "feat: add feature",
+			files:   map[string]string{"file1.txt": "content1"},
+		},
+		{
+			message: "WIP: debugging",
+			files:   map[string]string{"file2.txt": "content2"},
+		},
+	}
+
+	tmpDir, _, hashes := createTestRepo(t, commits)
+	writeConfigFile(t, tmpDir, defaultWIPConfig)
+
+	nestedDir := filepath.Join(tmpDir, "pkg", "nested")
+	err := os.MkdirAll(nestedDir, 0o755)
+	if err != nil {
+		t.Fatalf("failed to create nested dir: %v", err)
+	}
+
+	// Permissive config next to the invoking directory, only used with --config
+	permissiveConfig := `rules:
+  - name: prevent-fixme
+    type: deny
+    scope: title
+    pattern: 'FIXME'
+`
+	err = os.WriteFile(filepath.Join(nestedDir, "permissive.yml"), []byte(permissiveConfig), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	t.Chdir(nestedDir)
+
+	stdinInput := fmt.Sprintf("refs/heads/feature %s refs/heads/feature %s\n", hashes[1].String(), gitZeroHash)
+
+	tests := []struct {
+		name        string
+		args        []string
+		stdin       string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:        "repository root config with refs",
+			args:        []string{"commit-msg-lint", "--head-ref", hashes[1].String()},
+			wantErr:     true,
+			errContains: "prevent-wip",
+		},
+		{
+			name:        "repository root config in stdin mode",
+			args:        []string{"commit-msg-lint"},
+			stdin:       stdinInput,
+			wantErr:     true,
+			errContains: "prevent-wip",
+		},
+		{
+			name:        "repository root config in text mode",
+			args:        []string{"commit-msg-lint", "--text", "feat: add feature"},
+			wantErr:     false,
+			errContains: "",
+		},
+		{
+			name:        "config flag takes precedence over repository root config",
+			args:        []string{"commit-msg-lint", "--config", "permissive.yml", "--head-ref", hashes[1].String()},
+			wantErr:     false,
+			errContains: "",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := commitmsg.Run(strings.NewReader(testCase.stdin), testCase.args)
+
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), testCase.errContains) {
+				t.Errorf("Run() error = %v, want error containing %q", err, testCase.errContains)
+			}
+		})
+	}
+}
+
+func TestRunConfigPrecedence(t *testing.T) {
+	setUserConfigDir(t)
+
+	tmpDir, _, _ := createTestRepo(t, nil)
+
+	configs := map[string]string{
+		commitmsg.DefaultConfigFile:    "deny-default",
+		".githooks/commit-lint.yml":    "deny-env",
+		".githooks/commit-lint-ci.yml": "deny-env-absolute",
+		"build/commit-msg.yml":         "deny-flag",
+	}
+
+	for name, ruleName := range configs {
+		path := filepath.Join(tmpDir, name)
+
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+
+		content := fmt.Sprintf("rules:\n  - name: %s\n    type: deny\n    scope: title\n    pattern: 'WIP'\n", ruleName)
+		err = os.WriteFile(path, []byte(content), 0o644)
+		if err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	// Relative paths of the env var are resolved against the repository root,
+	// the ones of --config against the current directory
+	t.Chdir(filepath.Join(tmpDir, "build"))
+
+	tests := []struct {
+		name     string
+		envValue string
+		args     []string
+		wantRule string
+	}{
+		{
+			name:     "default config file",
+			envValue: "",
+			args:     []string{"commit-msg-lint", "--text", "WIP: debugging"},
+			wantRule: "deny-default",
+		},
+		{
+			name:     "env var over default config file",
+			envValue: ".githooks/commit-lint.yml",
+			args:     []string{"commit-msg-lint", "--text", "WIP: debugging"},
+			wantRule: "deny-env",
+		},
+		{
+			name:     "absolute path in env var",
+			envValue: filepath.Join(tmpDir, ".githooks/commit-lint-ci.yml"),
+			args:     []string{"commit-msg-lint", "--text", "WIP: debugging"},
+			wantRule: "deny-env-absolute",
+		},
+		{
+			name:     "config flag over env var",
+			envValue: ".githooks/commit-lint.yml",
+			args:     []string{"commit-msg-lint", "--config", "commit-msg.yml", "--text", "WIP: debugging"},
+			wantRule: "deny-flag",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Setenv("COMMIT_MSG_LINT_CONFIG", testCase.envValue)
+
+			err := commitmsg.Run(strings.NewReader(""), testCase.args)
+			if err == nil || !strings.Contains(err.Error(), "["+testCase.wantRule+"]") {
+				t.Errorf("Run() error = %v, want violation of rule %q", err, testCase.wantRule)
+			}
+		})
+	}
+}
+
+func TestRunAsRef(t *testing.T) {
+	commits := []commit{
+		{
+			message: "Add feature",
+			files:   map[string]string{"file1.txt": "content1"},
+		},
+	}
+
+	tmpDir, _, hashes := createTestRepo(t, commits)
+
+	// The main branch additionally requires Conventional Commits titles
+	writeConfigFile(t, tmpDir, defaultWIPConfig+`overrides:
+  - refs: [refs/heads/main]
+    rules:
+      - name: conventional-commits
+        type: require
+        scope: title
+        pattern: '^(feat|fix|chore)(\([a-z0-9-]+\))?!?: .+'
+`)
+
+	t.Chdir(tmpDir)
+
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		wantErr bool
+	}{
+		{
+			name:    "range without as-ref uses base rules only",
+			args:    []string{"commit-msg-lint", "--head-ref", hashes[0].String()},
+			wantErr: false,
+		},
+		{
+			name:    "range as main applies main override",
+			args:    []string{"commit-msg-lint", "--head-ref", hashes[0].String(), "--as-ref", "main"},
+			wantErr: true,
+		},
+		{
+			name:    "range as full main ref applies main override",
+			args:    []string{"commit-msg-lint", "--head-ref", hashes[0].String(), "--as-ref", "refs/heads/main"},
+			wantErr: true,
+		},
+		{
+			name:    "range as other ref does not apply main override",
+			args:    []string{"commit-msg-lint", "--head-ref", hashes[0].String(), "--as-ref", "develop"},
+			wantErr: false,
+		},
+		{
+			name: "stdin push to feature uses base rules only",
+			args: []string{"commit-msg-lint"},
+			stdin: fmt.Sprintf(
+				"refs/heads/feature %s refs/heads/feature %s\n", hashes[0].String(), gitZeroHash,
+			),
+			wantErr: false,
+		},
+		{
+			name: "stdin push to main applies main override",
+			args: []string{"commit-msg-lint"},
+			stdin: fmt.Sprintf(
+				"refs/heads/feature %s refs/heads/main %s\n", hashes[0].String(), gitZeroHash,
+			),
+			wantErr: true,
+		},
+		{
+			name: "stdin push to feature as main applies main override",
+			args: []string{"commit-msg-lint", "--as-ref", "main"},
+			stdin: fmt.Sprintf(
+				"refs/heads/feature %s refs/heads/feature %s\n", hashes[0].String(), gitZeroHash,
+			),
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := commitmsg.Run(strings.NewReader(testCase.stdin), testCase.args)
+
+			if (err != nil) != testCase.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunSeverity(t *testing.T) {
+	const warningIssueRefRule = `  - name: issue-ref
+    type: require
+    scope: message
+    pattern: '#\d+'
+    severity: warning
+`
+
+	tests := []struct {
+		name        string
+		config      string
+		message     string
+		wantErr     bool
+		errContains []string
+	}{
+		{
+			name:        "warning only does not fail",
+			config:      "rules:\n" + warningIssueRefRule,
+			message:     "Add feature",
+			wantErr:     false,
+			errContains: nil,
+		},
+		{
+			name:        "error and warning fails and reports both",
+			config:      defaultWIPConfig + warningIssueRefRule,
+			message:     "WIP: add feature",
+			wantErr:     true,
+			errContains: []string{"Rule violations:", "[prevent-wip]", "Warnings:", "[issue-ref]"},
+		},
+		{
+			name:        "explicit error severity fails",
+			config:      defaultWIPConfig + "    severity: error\n",
+			message:     "WIP: add feature",
+			wantErr:     true,
+			errContains: []string{"[prevent-wip]"},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, _, hashes := createTestRepo(t, []commit{
+				{message: testCase.message, files: map[string]string{"file1.txt": "content1"}},
+			})
+			writeConfigFile(t, tmpDir, testCase.config)
+			t.Chdir(tmpDir)
+
+			input := fmt.Sprintf("refs/heads/feature %s refs/heads/feature %s\n", hashes[0].String(), gitZeroHash)
+
+			err := commitmsg.Run(strings.NewReader(input), nil)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			for _, want := range testCase.errContains {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Run() error = %q, want it to contain %q", err.Error(), want)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRunTitleScopedRules(b *testing.B) {
+	const numCommits = 500
+
+	// Commits with a body and footer, which title-scoped rules never look at
+	commits := make([]commit, 0, numCommits)
+	for i := range numCommits {
+		commits = append(commits, commit{
+			message: fmt.Sprintf(
+				"feat: add feature %d\n\nThis adds feature %d.\nIt is needed for X.\n\n"+
+					"Details follow in a second paragraph.\n\nRefs: #%d\nSigned-off-by: Dev <dev@example.com>",
+				i, i, i,
+			),
+			files: map[string]string{"file.txt": fmt.Sprintf("content%d", i)},
+		})
+	}
+
+	tmpDir, _, hashes := createTestRepo(b, commits)
+	writeConfigFile(b, tmpDir, defaultWIPConfig+`  - name: conventional-commits
+    type: require
+    scope: cc_type
+    pattern: '^(feat|fix|docs|chore)$'
+`)
+	b.Chdir(tmpDir)
+
+	args := []string{"commit-msg-lint", "--base-ref", "main", "--head-ref", hashes[len(hashes)-1].String()}
+
+	for b.Loop() {
+		err := commitmsg.Run(strings.NewReader(""), args)
+		if err != nil {
+			b.Fatalf("Run() returned unexpected error: %v", err)
+		}
+	}
+}
+
+func TestRunBranchTicketPattern(t *testing.T) {
+	const config = `rules:
+  - name: require-ticket
+    type: require
+    scope: message
+    pattern: '\b[A-Z]+-\d+\b'
+    branch_ticket_pattern: '^[A-Z]+-\d+'
+    message: "Commit must reference a ticket (e.g. PROJ-123)"
+`
+
+	tests := []struct {
+		name    string
+		branch  string
+		message string
+		wantErr bool
+	}{
+		{
+			name:    "ticket branch exempts message",
+			branch:  "PROJ-1-foo",
+			message: "feat: add feature",
+			wantErr: false,
+		},
+		{
+			name:    "branch without ticket requires message ticket",
+			branch:  "feature-x",
+			message: "feat: add feature",
+			wantErr: true,
+		},
+		{
+			name:    "branch without ticket with message ticket",
+			branch:  "feature-x",
+			message: "feat: add feature\n\nRefs: PROJ-1",
+			wantErr: false,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, repo, hashes := createTestRepo(t, []commit{
+				{message: testCase.message, files: map[string]string{"file1.txt": "content1"}},
+			})
+			writeConfigFile(t, tmpDir, config)
+			t.Chdir(tmpDir)
+
+			t.Run("pre-push", func(t *testing.T) {
+				input := fmt.Sprintf("refs/heads/%s %s refs/heads/%s %s\n",
+					testCase.branch, hashes[0].String(), testCase.branch, gitZeroHash)
+
+				err := commitmsg.Run(strings.NewReader(input), nil)
+				if (err != nil) != testCase.wantErr {
+					t.Errorf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+				}
+			})
+
+			t.Run("head-ref", func(t *testing.T) {
+				branchRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(testCase.branch), hashes[0])
+				err := repo.Storer.SetReference(branchRef)
+				if err != nil {
+					t.Fatalf("failed to create branch: %v", err)
+				}
+
+				err = commitmsg.Run(strings.NewReader(""), []string{"commit-msg-lint", "--head-ref", testCase.branch})
+				if (err != nil) != testCase.wantErr {
+					t.Errorf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+				}
+			})
+		})
+	}
+}
+
+func TestRunText(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{
+			name:    "clean message passes",
+			text:    "feat: add feature",
+			wantErr: false,
+		},
+		{
+			name:    "WIP message rejected",
+			text:    "WIP: debugging",
+			wantErr: true,
+		},
+		{
+			name:    "multi-line message",
+			text:    "feat: add feature\n\nThis is still WIP in the body.\n\nRefs: #123",
+			wantErr: false,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			// No git repository is required in text mode
+			tmpDir := t.TempDir()
+			writeConfigFile(t, tmpDir, defaultWIPConfig)
+			t.Chdir(tmpDir)
+
+			err := commitmsg.Run(nil, []string{"commit-msg-lint", "--text", testCase.text})
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), "Commit message in the --text argument failed validation") {
+				t.Errorf("Run() error = %q, want the commit message violation report", err.Error())
+			}
+		})
+	}
+}
+
+func TestRunMessageAlias(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, tmpDir, defaultWIPConfig)
+	t.Chdir(tmpDir)
+
+	err := commitmsg.Run(nil, []string{"commit-msg-lint", "--message", "feat: add feature"})
+	if err != nil {
+		t.Fatalf("Run() error = %v, want nil", err)
+	}
+
+	err = commitmsg.Run(nil, []string{"commit-msg-lint", "--message", "WIP: debugging"})
+	if err == nil || !strings.Contains(err.Error(), "Commit message in the --message argument failed validation") {
+		t.Errorf("Run() error = %v, want the commit message violation report", err)
+	}
+}
+
+func TestRunTextReportsMatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, tmpDir, defaultWIPConfig)
+	t.Chdir(tmpDir)
+
+	err := commitmsg.Run(nil, []string{"commit-msg-lint", "--text", "Add login form (WIP)"})
+	if err == nil {
+		t.Fatal("Run() error = nil, want violation")
+	}
+
+	want := `was found in title at offset 15: "(WIP)" (deny rule)`
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("Run() error = %q, want it to contain %q", err.Error(), want)
+	}
+}
+
+func TestRunCheckConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		args        []string
+		errContains string
+	}{
+		{
+			name:        "valid config",
+			config:      defaultWIPConfig,
+			args:        []string{"commit-msg-lint", "--check-config"},
+			errContains: "",
+		},
+		{
+			name:        "invalid pattern",
+			config:      "rules:\n  - name: broken\n    type: deny\n    scope: title\n    pattern: '[invalid'\n",
+			args:        []string{"commit-msg-lint", "--check-config"},
+			errContains: "invalid regex pattern",
+		},
+		{
+			name:        "invalid YAML",
+			config:      "rules: [",
+			args:        []string{"commit-msg-lint", "--check-config"},
+			errContains: "failed to load config",
+		},
+		{
+			name:        "combined with text",
+			config:      defaultWIPConfig,
+			args:        []string{"commit-msg-lint", "--check-config", "--text", "feat: add feature"},
+			errContains: "--check-config can not be combined",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			// No git repository is required to check the config
+			tmpDir := t.TempDir()
+			writeConfigFile(t, tmpDir, testCase.config)
+			t.Chdir(tmpDir)
+
+			err := commitmsg.Run(nil, testCase.args)
+			if testCase.errContains == "" {
+				if err != nil {
+					t.Errorf("Run() returned unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			var configErr *commitmsg.ConfigError
+			if !errors.As(err, &configErr) || !strings.Contains(err.Error(), testCase.errContains) {
+				t.Errorf("Run() error = %v, want *ConfigError containing %q", err, testCase.errContains)
+			}
+		})
+	}
+}
+
+func TestRunSince(t *testing.T) {
+	tmpDir, repo, hashes := createTestRepo(t, []commit{
+		{message: "WIP: first", files: map[string]string{"file1.txt": "content1"}},
+		{message: "feat: add feature", files: map[string]string{"file2.txt": "content2"}},
+	})
+	writeConfigFile(t, tmpDir, defaultWIPConfig)
+	t.Chdir(tmpDir)
+
+	latest, err := repo.CommitObject(hashes[1])
+	if err != nil {
+		t.Fatalf("failed to get commit: %v", err)
+	}
+
+	// The WIP commit was authored a minute before the latest commit
+	cutoff := latest.Author.When.Add(-30 * time.Second).Format(time.RFC3339)
+
+	tests := []struct {
+		name        string
+		args        []string
+		errContains string
+	}{
+		{
+			name:        "only commits since the cutoff are validated",
+			args:        []string{"commit-msg-lint", "--since", cutoff},
+			errContains: "",
+		},
+		{
+			name:        "date includes older commits",
+			args:        []string{"commit-msg-lint", "--since", "2000-01-01"},
+			errContains: "WIP commits are not allowed",
+		},
+		{
+			name:        "invalid date",
+			args:        []string{"commit-msg-lint", "--since", "yesterday"},
+			errContains: `invalid --since "yesterday"`,
+		},
+		{
+			name:        "combined with head-ref",
+			args:        []string{"commit-msg-lint", "--since", "2000-01-01", "--head-ref", "HEAD"},
+			errContains: "--since can not be combined",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := commitmsg.Run(nil, testCase.args)
+			if testCase.errContains == "" {
+				if err != nil {
+					t.Errorf("Run() returned unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), testCase.errContains) {
+				t.Errorf("Run() error = %v, want it to contain %q", err, testCase.errContains)
+			}
+		})
+	}
+}
+
+func TestRunSkipSubjects(t *testing.T) {
+	const config = `rules:
+  - name: conventional-commits
+    type: require
+    scope: title
+    pattern: '^(feat|fix|chore)(\([a-z0-9-]+\))?!?: .+'
+settings:
+  skip_subjects:
+    - '^Revert "'
+`
+
+	tests := []struct {
+		name    string
+		message string
+		wantErr bool
+	}{
+		{
+			name:    "revert commit is skipped",
+			message: "Revert \"feat: add feature\"\n\nThis reverts commit 0123456789abcdef0123456789abcdef01234567.",
+			wantErr: false,
+		},
+		{
+			name:    "other subject is validated",
+			message: "Add feature",
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, _, hashes := createTestRepo(t, []commit{
+				{message: testCase.message, files: map[string]string{"file1.txt": "content1"}},
+			})
+			writeConfigFile(t, tmpDir, config)
+			t.Chdir(tmpDir)
+
+			err := commitmsg.Run(strings.NewReader(""), []string{"commit-msg-lint", "--head-ref", hashes[0].String()})
+			if (err != nil) != testCase.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunSkipFixupCommits(t *testing.T) {
+	const rules = `rules:
+  - name: conventional-commits
+    type: require
+    scope: title
+    pattern: '^(feat|fix|chore)(\([a-z0-9-]+\))?!?: .+'
+`
+
+	tests := []struct {
+		name             string
+		skipFixupCommits bool
+		message          string
+		wantErr          bool
+	}{
+		{
+			name:             "fixup commit is skipped",
+			skipFixupCommits: true,
+			message:          "fixup! feat: add feature",
+			wantErr:          false,
+		},
+		{
+			name:             "squash commit is skipped",
+			skipFixupCommits: true,
+			message:          "squash! feat: add feature\n\nAdd tests.",
+			wantErr:          false,
+		},
+		{
+			name:             "prefix not at start is validated",
+			skipFixupCommits: true,
+			message:          "Add fixup! handling",
+			wantErr:          true,
+		},
+		{
+			name:             "fixup commit is validated if not configured",
+			skipFixupCommits: false,
+			message:          "fixup! feat: add feature",
+			wantErr:          true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, _, hashes := createTestRepo(t, []commit{
+				{message: testCase.message, files: map[string]string{"file1.txt": "content1"}},
+			})
+			writeConfigFile(t, tmpDir, rules+fmt.Sprintf("settings:\n  skip_fixup_commits: %t\n", testCase.skipFixupCommits))
+			t.Chdir(tmpDir)
+
+			err := commitmsg.Run(strings.NewReader(""), []string{"commit-msg-lint", "--head-ref", hashes[0].String()})
+			if (err != nil) != testCase.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunLintSkipTrailer(t *testing.T) {
+	const rules = defaultWIPConfig + `  - name: conventional-commits
+    type: require
+    scope: title
+    pattern: '^(feat|fix|chore)(\([a-z0-9-]+\))?!?: .+'
+`
+
+	tests := []struct {
+		name             string
+		allowSkipTrailer bool
+		message          string
+		wantErr          bool
+		wantLog          string
+	}{
+		{
+			name:             "rule skipped by trailer",
+			allowSkipTrailer: true,
+			message:          "chore: WIP scratch\n\nLint-Skip: prevent-wip",
+			wantErr:          false,
+			wantLog:          "Skipping rule prevent-wip for ",
+		},
+		{
+			name:             "several rules skipped by one trailer",
+			allowSkipTrailer: true,
+			message:          "WIP scratch\n\nLint-Skip: prevent-wip, conventional-commits",
+			wantErr:          false,
+			wantLog:          "Skipping rule conventional-commits for ",
+		},
+		{
+			name:             "other rules are still evaluated",
+			allowSkipTrailer: true,
+			message:          "WIP scratch\n\nLint-Skip: prevent-wip",
+			wantErr:          true,
+			wantLog:          "Skipping rule prevent-wip for ",
+		},
+		{
+			name:             "trailer ignored if not allowed",
+			allowSkipTrailer: false,
+			message:          "chore: WIP scratch\n\nLint-Skip: prevent-wip",
+			wantErr:          true,
+			wantLog:          "",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, _, hashes := createTestRepo(t, []commit{
+				{message: testCase.message, files: map[string]string{"file1.txt": "content1"}},
+			})
+			writeConfigFile(t, tmpDir, rules+fmt.Sprintf("settings:\n  allow_skip_trailer: %t\n", testCase.allowSkipTrailer))
+			t.Chdir(tmpDir)
+
+			var stderr bytes.Buffer
+
+			err := commitmsg.RunWith(commitmsg.RunOptions{
+				Args:        []string{"commit-msg-lint", "--verbose", "--head-ref", hashes[0].String()},
+				Stdin:       strings.NewReader(""),
+				Stdout:      io.Discard,
+				Stderr:      &stderr,
+				ErrorPrefix: "",
+			})
+			if (err != nil) != testCase.wantErr {
+				t.Errorf("RunWith() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			if testCase.wantLog != "" && !strings.Contains(stderr.String(), testCase.wantLog) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), testCase.wantLog)
+			}
+
+			if testCase.wantLog == "" && strings.Contains(stderr.String(), "Skipping rule") {
+				t.Errorf("stderr = %q, want no skipped rules", stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunFirstCommitOnly(t *testing.T) {
+	const config = `rules:
+  - name: pr-reference
+    type: require
+    scope: message
+    pattern: '#\d+'
+    first_commit_only: true
+`
+
+	tests := []struct {
+		name       string
+		messages   []string
+		wantErr    bool
+		wantCommit int
+	}{
+		{
+			name:       "only the tip commit references a pull request",
+			messages:   []string{"feat: add parser", "feat: add lexer", "feat: add language (#12)"},
+			wantErr:    false,
+			wantCommit: 0,
+		},
+		{
+			name:       "tip commit without pull request reference",
+			messages:   []string{"feat: add parser (#12)", "feat: add lexer", "feat: add language"},
+			wantErr:    true,
+			wantCommit: 2,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			commits := make([]commit, 0, len(testCase.messages))
+			for i, message := range testCase.messages {
+				commits = append(commits, commit{
+					message: message,
+					files:   map[string]string{fmt.Sprintf("file%d.txt", i): "content"},
+				})
+			}
+
+			tmpDir, _, hashes := createTestRepo(t, commits)
+			writeConfigFile(t, tmpDir, config)
+			t.Chdir(tmpDir)
+
+			err := commitmsg.Run(strings.NewReader(""), []string{"commit-msg-lint", "--head-ref", hashes[2].String()})
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			if err == nil {
+				return
+			}
+
+			// Only the tip commit is reported
+			for i, hash := range hashes {
+				reported := strings.Contains(err.Error(), hash.String()[:7])
+				if reported != (i == testCase.wantCommit) {
+					t.Errorf("Run() error = %v, commit %d reported = %t", err, i, reported)
+				}
+			}
+		})
+	}
+}
+
+func TestRunFailFastAcrossCommits(t *testing.T) {
+	tests := []struct {
+		name              string
+		failFast          bool
+		wantFailedCommits int
+	}{
+		{
+			name:              "all failed commits are reported",
+			failFast:          false,
+			wantFailedCommits: 2,
+		},
+		{
+			name:              "fail fast stops at first failed commit",
+			failFast:          true,
+			wantFailedCommits: 1,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, _, hashes := createTestRepo(t, []commit{
+				{message: "WIP: first", files: map[string]string{"file1.txt": "content1"}},
+				{message: "feat: add feature", files: map[string]string{"file2.txt": "content2"}},
+				{message: "WIP: second", files: map[string]string{"file3.txt": "content3"}},
+			})
+			writeConfigFile(t, tmpDir, defaultWIPConfig+fmt.Sprintf("settings:\n  fail_fast: %t\n", testCase.failFast))
+			t.Chdir(tmpDir)
+
+			err := commitmsg.Run(strings.NewReader(""), []string{"commit-msg-lint", "--head-ref", hashes[2].String()})
+			if err == nil {
+				t.Fatal("Run() returned no error, want violations")
+			}
+
+			gotFailedCommits := strings.Count(err.Error(), "failed validation:\nCommit message:")
+			if gotFailedCommits != testCase.wantFailedCommits {
+				t.Errorf("Run() reported %d failed commits, want %d:\n%s",
+					gotFailedCommits, testCase.wantFailedCommits, err.Error())
+			}
+
+			if testCase.wantFailedCommits > 1 && !strings.HasPrefix(err.Error(), "2 commits in ") {
+				t.Errorf("Run() error = %q, want summary line", err.Error())
+			}
+		})
+	}
+}
+
+func TestRunPushWithoutNewCommits(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		localRef  string
+		remoteRef string
+	}{
+		{
+			name:      "new branch identical to main",
+			config:    defaultWIPConfig,
+			localRef:  "refs/heads/feature",
+			remoteRef: "refs/heads/feature",
+		},
+		{
+			// The main ref is not resolved, so it does not need to exist locally
+			name:      "main pushed to new remote",
+			config:    defaultWIPConfig + "settings:\n  main_ref: trunk\n",
+			localRef:  "refs/heads/trunk",
+			remoteRef: "refs/heads/trunk",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, repo, hashes := createTestRepo(t, []commit{
+				{message: "WIP: already on main", files: map[string]string{"file1.txt": "content1"}},
+			})
+			writeConfigFile(t, tmpDir, testCase.config)
+			t.Chdir(tmpDir)
+
+			// Main contains a violating commit, which must not be validated again
+			err := repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/main", hashes[0]))
+			if err != nil {
+				t.Fatalf("failed to update main branch: %v", err)
+			}
+
+			input := fmt.Sprintf("%s %s %s %s\n", testCase.localRef, hashes[0].String(), testCase.remoteRef, gitZeroHash)
+
+			err = commitmsg.Run(strings.NewReader(input), nil)
+			if err != nil {
+				t.Errorf("Run() returned unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRunEmptyRange(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{message: "WIP: debugging", files: map[string]string{"file1.txt": "content1"}},
+	})
+	writeConfigFile(t, tmpDir, defaultWIPConfig)
+	t.Chdir(tmpDir)
+
+	head := hashes[0].String()
+
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantStderr string
+	}{
+		{
+			name:       "base equals head",
+			args:       []string{"commit-msg-lint", "--base-ref", head, "--head-ref", head},
+			stdin:      "",
+			wantStderr: fmt.Sprintf("No commits to check in %s..%s\n", head, head),
+		},
+		{
+			name:       "push without changes",
+			args:       []string{"commit-msg-lint"},
+			stdin:      fmt.Sprintf("refs/heads/feature %s refs/heads/feature %s\n", head, head),
+			wantStderr: "No commits to check in refs/heads/feature\n",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+
+			err := commitmsg.RunWith(commitmsg.RunOptions{
+				Args:        testCase.args,
+				Stdin:       strings.NewReader(testCase.stdin),
+				Stdout:      nil,
+				Stderr:      &stderr,
+				ErrorPrefix: "",
+			})
+			if err != nil {
+				t.Fatalf("RunWith() returned unexpected error: %v", err)
+			}
+
+			if stderr.String() != testCase.wantStderr {
+				t.Errorf("stderr = %q, want %q", stderr.String(), testCase.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRunUpstreamBase(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    string
+		args        []string
+		setUpstream bool
+		wantErr     bool
+	}{
+		{
+			name:        "upstream as base skips the commits of the upstream",
+			settings:    "settings:\n  use_upstream_as_base: true\n",
+			args:        []string{"commit-msg-lint", "--head-ref", "HEAD"},
+			setUpstream: true,
+			wantErr:     false,
+		},
+		{
+			name:        "explicit upstream base ref",
+			settings:    "",
+			args:        []string{"commit-msg-lint", "--base-ref", "@{u}", "--head-ref", "HEAD"},
+			setUpstream: true,
+			wantErr:     false,
+		},
+		{
+			name:        "main ref as base by default",
+			settings:    "",
+			args:        []string{"commit-msg-lint", "--head-ref", "HEAD"},
+			setUpstream: true,
+			wantErr:     true,
+		},
+		{
+			name:        "fallback to main ref without upstream",
+			settings:    "settings:\n  use_upstream_as_base: true\n",
+			args:        []string{"commit-msg-lint", "--head-ref", "HEAD"},
+			setUpstream: false,
+			wantErr:     true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			// The WIP commit is already on the upstream develop branch
+			tmpDir, repo, hashes := createTestRepo(t, []commit{
+				{message: "WIP: debugging", files: map[string]string{"file1.txt": "content1"}},
+				{message: "feat: add feature", files: map[string]string{"file2.txt": "content2"}},
+			})
+
+			err := repo.Storer.SetReference(plumbing.NewHashReference("refs/remotes/origin/develop", hashes[0]))
+			if err != nil {
+				t.Fatalf("failed to create remote-tracking ref: %v", err)
+			}
+
+			if testCase.setUpstream {
+				setUpstream(t, repo, "origin", "refs/heads/develop")
+			}
+
+			writeConfigFile(t, tmpDir, defaultWIPConfig+testCase.settings)
+			t.Chdir(tmpDir)
+
+			err = commitmsg.Run(strings.NewReader(""), testCase.args)
+			if (err != nil) != testCase.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+// setUpstream configures the upstream of the checked out branch.
+func setUpstream(t *testing.T, repo *git.Repository, remote string, merge plumbing.ReferenceName) {
+	t.Helper()
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("failed to resolve HEAD: %v", err)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("failed to read repository config: %v", err)
+	}
+
+	cfg.Branches[head.Name().Short()] = &gitconfig.Branch{
+		Name:   head.Name().Short(),
+		Remote: remote,
+		Merge:  merge,
+	}
+
+	err = repo.SetConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to write repository config: %v", err)
+	}
+}
+
+func TestRunMaxCommits(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{message: "feat: add parser", files: map[string]string{"file1.txt": "content1"}},
+		{message: "feat: add lexer", files: map[string]string{"file2.txt": "content2"}},
+		{message: "feat: add language", files: map[string]string{"file3.txt": "content3"}},
+	})
+	t.Chdir(tmpDir)
+
+	stdinInput := fmt.Sprintf("refs/heads/feature %s refs/heads/feature %s\n", hashes[2].String(), gitZeroHash)
+
+	tests := []struct {
+		name        string
+		maxCommits  int
+		args        []string
+		stdin       string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:        "unlimited by default",
+			maxCommits:  0,
+			args:        []string{"commit-msg-lint", "--head-ref", hashes[2].String()},
+			wantErr:     false,
+			errContains: "",
+		},
+		{
+			name:        "range within limit",
+			maxCommits:  3,
+			args:        []string{"commit-msg-lint", "--head-ref", hashes[2].String()},
+			wantErr:     false,
+			errContains: "",
+		},
+		{
+			name:        "range exceeds limit",
+			maxCommits:  2,
+			args:        []string{"commit-msg-lint", "--head-ref", hashes[2].String()},
+			wantErr:     true,
+			errContains: "more than 2 commits to validate (max_commits)",
+		},
+		{
+			name:        "new branch exceeds limit in stdin mode",
+			maxCommits:  2,
+			args:        []string{"commit-msg-lint"},
+			stdin:       stdinInput,
+			wantErr:     true,
+			errContains: "more than 2 commits to validate (max_commits)",
+		},
+		{
+			name:        "since exceeds limit",
+			maxCommits:  1,
+			args:        []string{"commit-msg-lint", "--since", "2000-01-01"},
+			wantErr:     true,
+			errContains: "more than 1 commits to validate (max_commits)",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			writeConfigFile(t, tmpDir, defaultWIPConfig+fmt.Sprintf("settings:\n  max_commits: %d\n", testCase.maxCommits))
+
+			err := commitmsg.Run(strings.NewReader(testCase.stdin), testCase.args)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			var configErr *commitmsg.ConfigError
+			if err != nil && (!strings.Contains(err.Error(), testCase.errContains) || !errors.As(err, &configErr)) {
+				t.Errorf("Run() error = %v, want config error containing %q", err, testCase.errContains)
+			}
+		})
+	}
+}
+
+func TestRunSkipAuthors(t *testing.T) {
+	tests := []struct {
+		name        string
+		skipAuthors string
+		wantErr     bool
+	}{
+		{
+			name:        "matching author name is skipped",
+			skipAuthors: `'^Test User$'`,
+			wantErr:     false,
+		},
+		{
+			name:        "matching author email is skipped",
+			skipAuthors: `'@example\.com$'`,
+			wantErr:     false,
+		},
+		{
+			name:        "other author is validated",
+			skipAuthors: `'renovate\[bot\]'`,
+			wantErr:     true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, _, hashes := createTestRepo(t, []commit{
+				{message: "WIP: debugging", files: map[string]string{"file1.txt": "content1"}},
+				{message: "WIP: more debugging", files: map[string]string{"file2.txt": "content2"}},
+			})
+			writeConfigFile(t, tmpDir, defaultWIPConfig+"settings:\n  skip_authors:\n    - "+testCase.skipAuthors+"\n")
+			t.Chdir(tmpDir)
+
+			err := commitmsg.Run(strings.NewReader(""), []string{"commit-msg-lint", "--head-ref", hashes[1].String()})
+			if (err != nil) != testCase.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunSkipCommitters(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		wantErr  bool
+	}{
+		{
+			name:     "matching committer is skipped",
+			settings: "  skip_committers: ['^GitHub$']\n",
+			wantErr:  false,
+		},
+		{
+			name:     "matching committer email is skipped",
+			settings: "  skip_committers: ['^noreply@github\\.com$']\n",
+			wantErr:  false,
+		},
+		{
+			name:     "skip_authors does not match the committer",
+			settings: "  skip_authors: ['^GitHub$']\n",
+			wantErr:  true,
+		},
+		{
+			name:     "skip_committers does not match the author",
+			settings: "  skip_committers: ['^Test User$']\n",
+			wantErr:  true,
+		},
+		{
+			name:     "either list matches",
+			settings: "  skip_authors: ['^Test User$']\n  skip_committers: ['renovate\\[bot\\]']\n",
+			wantErr:  false,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, repo, _ := createTestRepo(t, nil)
+
+			worktree, err := repo.Worktree()
+			if err != nil {
+				t.Fatalf("failed to get worktree: %v", err)
+			}
+
+			// Committed by a bot on behalf of the author, like GitHub's web UI does
+			hash, err := worktree.Commit("WIP: edit in web UI", &git.CommitOptions{
+				AllowEmptyCommits: true,
+				Author:            &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+				Committer:         &object.Signature{Name: "GitHub", Email: "noreply@github.com", When: time.Now()},
+			})
+			if err != nil {
+				t.Fatalf("failed to commit: %v", err)
+			}
+
+			writeConfigFile(t, tmpDir, defaultWIPConfig+"settings:\n"+testCase.settings)
+			t.Chdir(tmpDir)
+
+			err = commitmsg.Run(strings.NewReader(""), []string{"commit-msg-lint", "--head-ref", hash.String()})
+			if (err != nil) != testCase.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunErrorTypes(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        string
+		args          []string
+		wantViolation bool
+	}{
+		{
+			name:          "rule violation",
+			config:        defaultWIPConfig,
+			args:          []string{"commit-msg-lint", "--text", "WIP: debugging"},
+			wantViolation: true,
+		},
+		{
+			name:          "invalid config",
+			config:        "rules:\n  - name: broken\n    type: deny\n    scope: title\n    pattern: '[invalid'\n",
+			args:          []string{"commit-msg-lint", "--text", "feat: add feature"},
+			wantViolation: false,
+		},
+		{
+			name:          "invalid flags",
+			config:        defaultWIPConfig,
+			args:          []string{"commit-msg-lint", "--text", "feat: add feature", "--head-ref", "HEAD"},
+			wantViolation: false,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writeConfigFile(t, tmpDir, testCase.config)
+			t.Chdir(tmpDir)
+
+			err := commitmsg.Run(nil, testCase.args)
+			if err == nil {
+				t.Fatal("Run() error = nil, want error")
+			}
+
+			var violationErr *commitmsg.ViolationError
+			var configErr *commitmsg.ConfigError
+
+			if errors.As(err, &violationErr) != testCase.wantViolation {
+				t.Errorf("Run() error = %T, want *ViolationError %v", err, testCase.wantViolation)
+			}
+
+			if errors.As(err, &configErr) == testCase.wantViolation {
+				t.Errorf("Run() error = %T, want *ConfigError %v", err, !testCase.wantViolation)
+			}
+		})
+	}
+}
+
+func TestRunStdinModeSharedBase(t *testing.T) {
+	tmpDir, repo, hashes := createTestRepo(t, []commit{
+		{message: "feat: add feature one", files: map[string]string{"file1.txt": "content1"}},
+		{message: "feat: add feature two", files: map[string]string{"file2.txt": "content2"}},
+		{message: "feat: add feature three", files: map[string]string{"file3.txt": "content3"}},
+	})
+	writeConfigFile(t, tmpDir, defaultWIPConfig)
+
+	config, err := commitmsg.LoadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	// Three new branches based on main, all sharing main as their base
+	var input strings.Builder
+	for i, hash := range hashes {
+		ref := fmt.Sprintf("refs/heads/feature-%d", i)
+		fmt.Fprintf(&input, "%s %s %s %s\n", ref, hash.String(), ref, gitZeroHash)
+	}
+
+	// A tag on the tip of the last branch shares its merge base with main
+	fmt.Fprintf(&input, "refs/tags/v1.0.0 %s refs/tags/v1.0.0 %s\n", hashes[2].String(), gitZeroHash)
+
+	walks, mergeBases, err := commitmsg.PrePushHistoryWalksForTesting(config, repo, strings.NewReader(input.String()))
+	if err != nil {
+		t.Fatalf("PrePushHistoryWalksForTesting() returned unexpected error: %v", err)
+	}
+
+	if walks != 1 {
+		t.Errorf("history of the shared base walked %d times, want 1", walks)
+	}
+
+	if mergeBases != len(hashes) {
+		t.Errorf("merge base with main computed %d times, want %d", mergeBases, len(hashes))
+	}
+}
+
+func TestRunStdinModeSharedCommitsRevalidatedPerBranch(t *testing.T) {
+	tmpDir, repo, hashes := createTestRepo(t, []commit{
+		{message: "Revert \"feat: add feature\"", files: map[string]string{"file1.txt": "content1"}},
+	})
+	writeConfigFile(t, tmpDir, `rules:
+  - name: revert-approval
+    type: revert_requires_approval
+    protected_branches: [main]
+`)
+	t.Chdir(tmpDir)
+
+	revertCommit, err := repo.CommitObject(hashes[0])
+	if err != nil {
+		t.Fatalf("failed to get revert commit: %v", err)
+	}
+
+	// The same commit is pushed to a feature branch and the protected main branch
+	input := fmt.Sprintf("refs/heads/feature %s refs/heads/feature %s\nrefs/heads/main %s refs/heads/main %s\n",
+		hashes[0], gitZeroHash, hashes[0], revertCommit.ParentHashes[0])
+
+	err = commitmsg.Run(strings.NewReader(input), nil)
+
+	var violationErr *commitmsg.ViolationError
+	if !errors.As(err, &violationErr) {
+		t.Fatalf("Run() error = %v, want violation error", err)
+	}
+
+	if !strings.Contains(err.Error(), "refs/heads/main") || strings.Contains(err.Error(), "refs/heads/feature") {
+		t.Errorf("Run() error = %v, want only refs/heads/main to fail", err)
+	}
+}
+
+func TestRunStdinModeReportsAllRefs(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{message: "WIP: first feature", files: map[string]string{"file1.txt": "content1"}},
+		{message: "feat: add feature", files: map[string]string{"file2.txt": "content2"}},
+		{message: "WIP: second feature", files: map[string]string{"file3.txt": "content3"}},
+	})
+	t.Chdir(tmpDir)
+
+	input := fmt.Sprintf("refs/heads/first %s refs/heads/first %s\nrefs/heads/second %s refs/heads/second %s\n",
+		hashes[0].String(), gitZeroHash, hashes[2].String(), hashes[1].String())
+
+	tests := []struct {
+		name       string
+		failFast   bool
+		wantCommit []bool
+	}{
+		{
+			name:       "violations of all refs",
+			failFast:   false,
+			wantCommit: []bool{true, false, true},
+		},
+		{
+			name:       "fail fast stops at the first ref",
+			failFast:   true,
+			wantCommit: []bool{true, false, false},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			writeConfigFile(t, tmpDir, defaultWIPConfig+fmt.Sprintf("settings:\n  fail_fast: %t\n", testCase.failFast))
+
+			err := commitmsg.Run(strings.NewReader(input), nil)
+
+			var violationErr *commitmsg.ViolationError
+			if !errors.As(err, &violationErr) {
+				t.Fatalf("Run() error = %v, want violation error", err)
+			}
+
+			for i, hash := range hashes {
+				reported := strings.Contains(err.Error(), hash.String()[:7])
+				if reported != testCase.wantCommit[i] {
+					t.Errorf("Run() error = %v, commit %d reported = %t, want %t", err, i, reported, testCase.wantCommit[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunStdinModeProtectedRefs(t *testing.T) {
+	tmpDir, repo, hashes := createTestRepo(t, []commit{
+		{message: "feat: add feature", files: map[string]string{"file1.txt": "content1"}},
+		{message: "WIP: work in progress", files: map[string]string{"file2.txt": "content2"}},
+	})
+
+	featureCommit, err := repo.CommitObject(hashes[0])
+	if err != nil {
+		t.Fatalf("failed to get feature commit: %v", err)
+	}
+
+	baseHash := featureCommit.ParentHashes[0]
+
+	tests := []struct {
+		name          string
+		protectedRefs string
+		input         string
+		wantErr       bool
+	}{
+		{
+			name:          "WIP commit on unprotected ref is not validated",
+			protectedRefs: "  protected_refs:\n    - '^main$'\n    - '^release/'\n",
+			input: fmt.Sprintf("refs/heads/topic %s refs/heads/topic %s\nrefs/heads/release/1.0 %s refs/heads/release/1.0 %s\n",
+				hashes[1], hashes[0], hashes[0], baseHash),
+			wantErr: false,
+		},
+		{
+			name:          "WIP commit on protected ref is validated",
+			protectedRefs: "  protected_refs:\n    - '^main$'\n    - '^release/'\n",
+			input: fmt.Sprintf("refs/heads/topic %s refs/heads/topic %s\nrefs/heads/release/1.0 %s refs/heads/release/1.0 %s\n",
+				hashes[0], baseHash, hashes[1], hashes[0]),
+			wantErr: true,
+		},
+		{
+			name:          "all refs are validated without protected refs",
+			protectedRefs: "",
+			input: fmt.Sprintf("refs/heads/topic %s refs/heads/topic %s\nrefs/heads/release/1.0 %s refs/heads/release/1.0 %s\n",
+				hashes[1], hashes[0], hashes[0], baseHash),
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			writeConfigFile(t, tmpDir, defaultWIPConfig+"settings:\n"+testCase.protectedRefs)
+			t.Chdir(tmpDir)
+
+			err := commitmsg.Run(strings.NewReader(testCase.input), []string{"commit-msg-lint"})
+			if (err != nil) != testCase.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunNewBranchMergeBase(t *testing.T) {
+	// Build the following history, with WIP commits on main only:
+	//
+	// base -- feature1 -- feature2 (feature)
+	//     \
+	//      main1 -- main2 -- main3 (main)
+	tmpDir, repo, hashes := createTestRepo(t, []commit{
+		{message: "feat: add feature one", files: map[string]string{"feature1.txt": "content1"}},
+		{message: "feat: add feature two", files: map[string]string{"feature2.txt": "content2"}},
+	})
+
+	featureCommit, err := repo.CommitObject(hashes[0])
+	if err != nil {
+		t.Fatalf("failed to get feature commit: %v", err)
+	}
+
+	baseHash := featureCommit.ParentHashes[0]
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+
+	err = worktree.Reset(&git.ResetOptions{Commit: baseHash, Mode: git.HardReset})
+	if err != nil {
+		t.Fatalf("failed to reset to base: %v", err)
+	}
+
+	var mainHash plumbing.Hash
+	for i := range 3 {
+		mainHash = addCommit(t, tmpDir, repo, fmt.Sprintf("WIP: main commit %d", i))
+	}
+
+	err = repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/main", mainHash))
+	if err != nil {
+		t.Fatalf("failed to update main branch: %v", err)
+	}
+
+	writeConfigFile(t, tmpDir, defaultWIPConfig)
+	t.Chdir(tmpDir)
+
+	var stderr bytes.Buffer
+
+	input := fmt.Sprintf("refs/heads/feature %s refs/heads/feature %s\n", hashes[1].String(), gitZeroHash)
+	err = commitmsg.RunWith(commitmsg.RunOptions{
+		Args:        []string{"commit-msg-lint", "--verbose"},
+		Stdin:       strings.NewReader(input),
+		Stdout:      nil,
+		Stderr:      &stderr,
+		ErrorPrefix: "",
+	})
+	if err != nil {
+		t.Fatalf("RunWith() returned unexpected error: %v", err)
+	}
+
+	// The range starts at the divergence point, not at the tip of main
+	wantRange := fmt.Sprintf("(%s..%s)", baseHash.String(), hashes[1].String())
+	if !strings.Contains(stderr.String(), wantRange) {
+		t.Errorf("stderr = %q, want range %s", stderr.String(), wantRange)
+	}
+
+	for _, hash := range hashes {
+		if !strings.Contains(stderr.String(), "pass "+hash.String()[:7]) {
+			t.Errorf("stderr = %q, want feature commit %s validated", stderr.String(), hash.String()[:7])
+		}
+	}
+}
+
+func TestRunDisabled(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		envValue    string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:     "env var disables linting",
+			config:   defaultWIPConfig,
+			envValue: "1",
+			wantErr:  false,
+		},
+		{
+			name:     "disabled setting disables linting",
+			config:   defaultWIPConfig + "settings:\n  disabled: true\n",
+			envValue: "",
+			wantErr:  false,
+		},
+		{
+			name:        "env var takes precedence over disabled setting",
+			config:      defaultWIPConfig + "settings:\n  disabled: true\n",
+			envValue:    "0",
+			wantErr:     true,
+			errContains: "WIP commits are not allowed",
+		},
+		{
+			name:        "invalid env var",
+			config:      defaultWIPConfig,
+			envValue:    "maybe",
+			wantErr:     true,
+			errContains: `invalid COMMIT_MSG_LINT_DISABLE "maybe"`,
+		},
+		{
+			name:        "config errors are still reported",
+			config:      "rules:\n  - name: broken\n    type: deny\n    pattern: '(['\n",
+			envValue:    "1",
+			wantErr:     true,
+			errContains: "failed to load config",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writeConfigFile(t, tmpDir, testCase.config)
+			t.Chdir(tmpDir)
+			t.Setenv("COMMIT_MSG_LINT_DISABLE", testCase.envValue)
+
+			err := commitmsg.Run(nil, []string{"commit-msg-lint", "--text", "WIP: debugging"})
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			if testCase.errContains != "" && !strings.Contains(err.Error(), testCase.errContains) {
+				t.Errorf("Run() error = %q, want it to contain %q", err.Error(), testCase.errContains)
+			}
+		})
+	}
+}
+
+func TestRunOverlappingRefs(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		wantReports int
+	}{
+		{
+			name:        "shared commits are reported once",
+			config:      defaultWIPConfig,
+			wantReports: 1,
+		},
+		{
+			name: "shared commits are validated again with other rules",
+			config: defaultWIPConfig + `overrides:
+  - refs: [refs/tags/v1.0.0]
+    rules:
+      - name: release-prefix
+        type: require
+        scope: title
+        pattern: '^release:'
+`,
+			wantReports: 2,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, _, hashes := createTestRepo(t, []commit{
+				{message: "Initial commit", files: map[string]string{"file1.txt": "content1"}},
+				{message: "WIP: debugging", files: map[string]string{"file2.txt": "content2"}},
+			})
+			writeConfigFile(t, tmpDir, testCase.config)
+			t.Chdir(tmpDir)
+
+			// A branch and a tag pushed together on the same tip
+			input := fmt.Sprintf(
+				"refs/heads/feature %s refs/heads/feature %s\n"+
+					"refs/tags/v1.0.0 %s refs/tags/v1.0.0 %s\n",
+				hashes[1].String(), gitZeroHash, hashes[1].String(), gitZeroHash,
+			)
+
+			err := commitmsg.Run(strings.NewReader(input), nil)
+			if err == nil {
+				t.Fatal("Run() error = nil, want violation")
+			}
+
+			got := strings.Count(err.Error(), "Commit message: WIP: debugging")
+			if got != testCase.wantReports {
+				t.Errorf("Run() reported the shared commit %d times, want %d:\n%s", got, testCase.wantReports, err)
+			}
+		})
+	}
+}
+
+func TestRunLintTags(t *testing.T) {
+	tests := []struct {
+		name       string
+		tagMessage string
+		wantErr    string
+	}{
+		{
+			name:       "tag message violates rules",
+			tagMessage: "WIP release\n",
+			wantErr:    "Commit message in the annotation of tag v1.0.0 failed validation",
+		},
+		{
+			name:       "tag message passes, commits are not validated",
+			tagMessage: "Release 1.0.0\n",
+			wantErr:    "",
+		},
+		{
+			name:       "lightweight tag is validated as commit range",
+			tagMessage: "",
+			wantErr:    "Commit message: WIP: debugging",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, repo, hashes := createTestRepo(t, []commit{
+				{message: "WIP: debugging", files: map[string]string{"file1.txt": "content1"}},
+			})
+			writeConfigFile(t, tmpDir, "settings:\n  lint_tags: true\n"+defaultWIPConfig)
+			t.Chdir(tmpDir)
+
+			// Without a message, go-git creates a lightweight tag
+			var opts *git.CreateTagOptions
+			if testCase.tagMessage != "" {
+				opts = &git.CreateTagOptions{
+					Tagger:  &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+					Message: testCase.tagMessage,
+					SignKey: nil,
+				}
+			}
+
+			tagRef, err := repo.CreateTag("v1.0.0", hashes[0], opts)
+			if err != nil {
+				t.Fatalf("failed to create tag: %v", err)
+			}
+
+			input := fmt.Sprintf("refs/tags/v1.0.0 %s refs/tags/v1.0.0 %s\n", tagRef.Hash().String(), gitZeroHash)
+
+			err = commitmsg.Run(strings.NewReader(input), nil)
+			if testCase.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Run() returned unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), testCase.wantErr) {
+				t.Errorf("Run() error = %v, want it to contain %q", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunBranch(t *testing.T) {
+	tmpDir, _, _ := createTestRepo(t, []commit{
+		{message: "feat: add feature", files: map[string]string{"file1.txt": "content1"}},
+		{message: "WIP: debugging", files: map[string]string{"file2.txt": "content2"}},
+	})
+	writeConfigFile(t, tmpDir, defaultWIPConfig)
+	t.Chdir(tmpDir)
+
+	// The hook input on stdin is ignored, the commits of HEAD not on main are validated
+	input := fmt.Sprintf("refs/heads/main %s refs/heads/main abc123def456\n", gitZeroHash)
+
+	err := commitmsg.Run(strings.NewReader(input), []string{"commit-msg-lint", "--branch"})
+	if err == nil || !strings.Contains(err.Error(), "main..HEAD") || !strings.Contains(err.Error(), "WIP: debugging") {
+		t.Errorf("Run() error = %v, want the WIP commit of main..HEAD reported", err)
+	}
+}
+
+func TestGetCommitsInRangeMissingCommit(t *testing.T) {
+	_, repo, hashes := createTestRepo(t, []commit{
+		{message: "feat: add feature", files: map[string]string{"file1.txt": "content1"}},
+	})
+
+	const unknown = "1234567890123456789012345678901234567890"
+
+	tests := []struct {
+		name      string
+		oldCommit string
+		newCommit string
+	}{
+		{name: "unknown new commit", oldCommit: hashes[0].String(), newCommit: unknown},
+		{name: "unknown old commit", oldCommit: unknown, newCommit: hashes[0].String()},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := commitmsg.GetCommitsInRangeForTesting(repo, testCase.oldCommit, testCase.newCommit)
+
+			want := "commit " + unknown + " not found locally; ensure the full history is fetched"
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("getCommitsInRange() error = %v, want it to contain %q", err, want)
+			}
+		})
+	}
+}
+
+func TestRunLenientMissingObjects(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    string
+		wantErr     bool
+		wantSkipped bool
+	}{
+		{
+			name:     "incomplete history fails",
+			settings: "",
+			wantErr:  true,
+		},
+		{
+			name:        "incomplete history is skipped",
+			settings:    "settings:\n  lenient_missing_objects: true\n",
+			wantErr:     false,
+			wantSkipped: true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, _, hashes := createTestRepo(t, []commit{
+				{message: "feat: add feature", files: map[string]string{"file1.txt": "content1"}},
+				{message: "feat: add another feature", files: map[string]string{"file2.txt": "content2"}},
+				{message: "WIP: debugging", files: map[string]string{"file3.txt": "content3"}},
+			})
+			writeConfigFile(t, tmpDir, defaultWIPConfig+testCase.settings)
+			t.Chdir(tmpDir)
+
+			// Remove a commit between base and head, like in a shallow clone
+			missing := hashes[1].String()
+			err := os.Remove(filepath.Join(tmpDir, ".git", "objects", missing[:2], missing[2:]))
+			if err != nil {
+				t.Fatalf("failed to remove commit object: %v", err)
+			}
+
+			var stderr bytes.Buffer
+
+			err = commitmsg.RunWith(commitmsg.RunOptions{
+				Args:        []string{"commit-msg-lint", "--base-ref", hashes[0].String(), "--head-ref", hashes[2].String()},
+				Stdin:       strings.NewReader(""),
+				Stdout:      io.Discard,
+				Stderr:      &stderr,
+				ErrorPrefix: "Error: ",
+			})
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("RunWith() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			want := "history of commit " + hashes[2].String() + " is incomplete locally"
+			if !strings.Contains(stderr.String(), want) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), want)
+			}
+
+			if skipped := strings.Contains(stderr.String(), "Skipping "); skipped != testCase.wantSkipped {
+				t.Errorf("stderr = %q, want skipped %v", stderr.String(), testCase.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestRunLenientMissingObjectsNewBranch(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    string
+		wantErr     bool
+		wantSkipped bool
+	}{
+		{
+			name:     "incomplete history fails",
+			settings: "",
+			wantErr:  true,
+		},
+		{
+			name:        "incomplete history is skipped",
+			settings:    "settings:\n  lenient_missing_objects: true\n",
+			wantErr:     false,
+			wantSkipped: true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, repo, hashes := createTestRepo(t, []commit{
+				{message: "feat: add feature", files: map[string]string{"file1.txt": "content1"}},
+				{message: "WIP: debugging", files: map[string]string{"file2.txt": "content2"}},
+			})
+			writeConfigFile(t, tmpDir, defaultWIPConfig+testCase.settings)
+			t.Chdir(tmpDir)
+
+			// A shallow clone of a single branch, without the main ref and the
+			// history before the fetched depth
+			err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName("main"))
+			if err != nil {
+				t.Fatalf("failed to remove main ref: %v", err)
+			}
+
+			missing := hashes[0].String()
+			err = os.Remove(filepath.Join(tmpDir, ".git", "objects", missing[:2], missing[2:]))
+			if err != nil {
+				t.Fatalf("failed to remove commit object: %v", err)
+			}
+
+			stdin := fmt.Sprintf("refs/heads/feature %s refs/heads/feature %s\n", hashes[1].String(), gitZeroHash)
+
+			var stderr bytes.Buffer
+
+			err = commitmsg.RunWith(commitmsg.RunOptions{
+				Args:        []string{"commit-msg-lint"},
+				Stdin:       strings.NewReader(stdin),
+				Stdout:      io.Discard,
+				Stderr:      &stderr,
+				ErrorPrefix: "Error: ",
+			})
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("RunWith() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+
+			want := "history of commit " + hashes[1].String() + " is incomplete locally"
+			if !strings.Contains(stderr.String(), want) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), want)
+			}
+
+			if skipped := strings.Contains(stderr.String(), "Skipping "); skipped != testCase.wantSkipped {
+				t.Errorf("stderr = %q, want skipped %v", stderr.String(), testCase.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestRunMissingMainRef(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    string
+		args        func(hashes []plumbing.Hash) []string
+		stdin       func(hashes []plumbing.Hash) string
+		wantErr     string
+		wantWarning bool
+	}{
+		{
+			name:     "new branch push validates all commits",
+			settings: "",
+			args:     func([]plumbing.Hash) []string { return []string{"commit-msg-lint"} },
+			stdin: func(hashes []plumbing.Hash) string {
+				return fmt.Sprintf("refs/heads/feature %s refs/heads/feature %s\n", hashes[1].String(), gitZeroHash)
+			},
+			wantErr:     "Commit message: WIP: debugging",
+			wantWarning: true,
+		},
+		{
+			name:     "new branch push fails with require_main_ref",
+			settings: "settings:\n  require_main_ref: true\n",
+			args:     func([]plumbing.Hash) []string { return []string{"commit-msg-lint"} },
+			stdin: func(hashes []plumbing.Hash) string {
+				return fmt.Sprintf("refs/heads/feature %s refs/heads/feature %s\n", hashes[1].String(), gitZeroHash)
+			},
+			wantErr:     "failed to resolve main ref",
+			wantWarning: false,
+		},
+		{
+			name:     "head ref validates all commits",
+			settings: "",
+			args: func(hashes []plumbing.Hash) []string {
+				return []string{"commit-msg-lint", "--head-ref", hashes[1].String()}
+			},
+			stdin:       func([]plumbing.Hash) string { return "" },
+			wantErr:     "Commit message: WIP: debugging",
+			wantWarning: true,
+		},
+		{
+			name:     "head ref fails with require_main_ref",
+			settings: "settings:\n  require_main_ref: true\n",
+			args: func(hashes []plumbing.Hash) []string {
+				return []string{"commit-msg-lint", "--head-ref", hashes[1].String()}
+			},
+			stdin:       func([]plumbing.Hash) string { return "" },
+			wantErr:     "hint: use --base-ref",
+			wantWarning: false,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			tmpDir, repo, hashes := createTestRepo(t, []commit{
+				{message: "WIP: debugging", files: map[string]string{"file1.txt": "content1"}},
+				{message: "feat: add feature", files: map[string]string{"file2.txt": "content2"}},
+			})
+			writeConfigFile(t, tmpDir, testCase.settings+defaultWIPConfig)
+			t.Chdir(tmpDir)
+
+			// A fresh repository without the main branch
+			err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName("main"))
+			if err != nil {
+				t.Fatalf("failed to remove main ref: %v", err)
+			}
+
+			var stderr bytes.Buffer
+
+			err = commitmsg.RunWith(commitmsg.RunOptions{
+				Args:        testCase.args(hashes),
+				Stdin:       strings.NewReader(testCase.stdin(hashes)),
+				Stdout:      io.Discard,
+				Stderr:      &stderr,
+				ErrorPrefix: "",
+			})
+			if err == nil || !strings.Contains(err.Error(), testCase.wantErr) {
+				t.Errorf("RunWith() error = %v, want it to contain %q", err, testCase.wantErr)
+			}
+
+			warned := strings.Contains(stderr.String(), "Warning: main ref main not found")
+			if warned != testCase.wantWarning {
+				t.Errorf("stderr = %q, want warning %v", stderr.String(), testCase.wantWarning)
+			}
+		})
+	}
+}
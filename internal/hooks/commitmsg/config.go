@@ -1,18 +1,74 @@
 package commitmsg
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strings"
+	"unicode"
 
+	"github.com/go-git/go-git/v5/plumbing"
 	"gopkg.in/yaml.v3"
 )
 
 // DefaultConfigFile is the name of the configuration file.
 const DefaultConfigFile = ".commit-msg-lint.yml"
 
+// userConfigFile is the path of the fallback configuration file relative to the
+// user config directory, e.g. $XDG_CONFIG_HOME on Linux.
+const userConfigFile = "commit-msg-lint/config.yml"
+
+// defaultQuestionSubjectPattern is the pattern no_question_subject rules match
+// against the title by default.
+const defaultQuestionSubjectPattern = `\?\s*$`
+
+// defaultTrailingPunctuation are the characters no_trailing_punctuation rules
+// forbid at the end of the title by default.
+const defaultTrailingPunctuation = ".,;:!?"
+
+// defaultIssueRefPrefixPattern is the pattern of the issue references
+// require_issue_ref rules require by default, e.g. "ABC-123".
+const defaultIssueRefPrefixPattern = `[A-Z]+-\d+`
+
+// Strictness levels of subject_not_branch_name rules.
+const (
+	// strictnessExact flags subjects that equal the descriptive part of the branch name.
+	strictnessExact = "exact"
+	// strictnessContains flags subjects that contain the descriptive part of the branch name.
+	strictnessContains = "contains"
+)
+
+// Signers of dco rules.
+const (
+	// signerAuthor requires the commit author to sign off.
+	signerAuthor = "author"
+	// signerCommitter requires the committer to sign off.
+	signerCommitter = "committer"
+)
+
+// Match modes combining the patterns of deny and require rules.
+const (
+	// matchAny matches if any of the patterns matches.
+	matchAny = "any"
+	// matchAll matches if all of the patterns match.
+	matchAll = "all"
+)
+
+// inlineIgnoreCaseRegex matches a pattern starting with an inline flag group
+// that enables case-insensitive matching, e.g. "(?i)" or "(?im)".
+var inlineIgnoreCaseRegex = regexp.MustCompile(`^\(\?[a-zA-Z]*i[a-zA-Z-]*\)`)
+
+// envReferenceRegex matches an environment variable reference like "${VAR}" in a
+// pattern. A plain "$" is not matched, so it can still be used as an anchor.
+var envReferenceRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// defaultApprovalTrailerKey is the trailer required by revert_requires_approval rules by default.
+const defaultApprovalTrailerKey = "Approved-by"
+
 // RuleType defines the type of rule enforcement.
 type RuleType string
 
@@ -21,6 +77,80 @@ const (
 	RuleTypeDeny RuleType = "deny"
 	// RuleTypeRequire fails if the pattern does NOT match.
 	RuleTypeRequire RuleType = "require"
+	// RuleTypeFixReferencesCause requires fix commits to reference the commit
+	// that introduced the bug with a "Caused-by: <hash>" trailer.
+	RuleTypeFixReferencesCause RuleType = "fix_references_cause"
+	// RuleTypeAuthorEmailAllowlist requires the commit author email to be listed
+	// in an allowlist file.
+	RuleTypeAuthorEmailAllowlist RuleType = "author_email_allowlist"
+	// RuleTypeNoRepeatedWords fails if a word is immediately repeated, e.g. "the the".
+	RuleTypeNoRepeatedWords RuleType = "no_repeated_words"
+	// RuleTypeRevertRequiresApproval requires revert commits pushed to protected
+	// branches to carry an approval trailer.
+	RuleTypeRevertRequiresApproval RuleType = "revert_requires_approval"
+	// RuleTypeMaxTrailerRepeats limits how often any single trailer key may occur.
+	RuleTypeMaxTrailerRepeats RuleType = "max_trailer_repeats"
+	// RuleTypeTemplateMatch requires the message to follow the structure of a
+	// template with section headers and named holes.
+	RuleTypeTemplateMatch RuleType = "template_match"
+	// RuleTypeNoQuestionSubject forbids titles phrased as questions.
+	RuleTypeNoQuestionSubject RuleType = "no_question_subject"
+	// RuleTypeSubjectNotBranchName forbids titles merely restating the branch name.
+	RuleTypeSubjectNotBranchName RuleType = "subject_not_branch_name"
+	// RuleTypeMinLength requires the text of the scope to have a minimum length.
+	RuleTypeMinLength RuleType = "min_length"
+	// RuleTypeMaxWords limits the number of words of the text of the scope.
+	RuleTypeMaxWords RuleType = "max_words"
+	// RuleTypeMinWords requires the text of the scope, if present, to have a
+	// minimum number of words.
+	RuleTypeMinWords RuleType = "min_words"
+	// RuleTypeRequireBodyWhen requires a body if the title matches a pattern.
+	RuleTypeRequireBodyWhen RuleType = "require_body_when"
+	// RuleTypeImperativeSubject forbids titles starting with a past tense or
+	// gerund verb, e.g. "Added" or "Adding".
+	RuleTypeImperativeSubject RuleType = "imperative_subject"
+	// RuleTypeNoTrailingPunctuation forbids titles ending with a punctuation
+	// character, e.g. a period.
+	RuleTypeNoTrailingPunctuation RuleType = "no_trailing_punctuation"
+	// RuleTypeRequireIssueRef requires an issue reference, e.g. "ABC-123", in the
+	// title or footer.
+	RuleTypeRequireIssueRef RuleType = "require_issue_ref"
+	// RuleTypeCharset forbids characters not matching any of the allowed
+	// character classes, e.g. non-ASCII characters.
+	RuleTypeCharset RuleType = "charset"
+	// RuleTypeBodyWrap requires the lines of the body to be wrapped at a maximum
+	// number of characters.
+	RuleTypeBodyWrap RuleType = "body_wrap"
+	// RuleTypeBlankLineAfterTitle requires a blank line between the title and
+	// the body or footer.
+	RuleTypeBlankLineAfterTitle RuleType = "blank_line_after_title"
+	// RuleTypeDCO requires a Signed-off-by trailer matching the identity of the
+	// commit author or committer (Developer Certificate of Origin).
+	RuleTypeDCO RuleType = "dco"
+	// RuleTypeNoMergeCommits forbids merge commits, e.g. to enforce a linear
+	// history.
+	RuleTypeNoMergeCommits RuleType = "no_merge_commits"
+	// RuleTypeCCLowercase requires the Conventional Commits type of the title to
+	// be lowercase, e.g. "feat" instead of "Feat".
+	RuleTypeCCLowercase RuleType = "cc_lowercase"
+	// RuleTypeRequireTrailer requires a trailer, optionally with a value matching
+	// a pattern, e.g. Co-authored-by for pair-programming commits.
+	RuleTypeRequireTrailer RuleType = "require_trailer"
+	// RuleTypeMaxBodyLines limits the number of non-empty lines of the body.
+	RuleTypeMaxBodyLines RuleType = "max_body_lines"
+	// RuleTypeNoTrailingWhitespace forbids lines of the raw message ending with
+	// whitespace.
+	RuleTypeNoTrailingWhitespace RuleType = "no_trailing_whitespace"
+)
+
+// Severity defines how a rule violation affects the result of a run.
+type Severity string
+
+const (
+	// SeverityError fails the run (default).
+	SeverityError Severity = "error"
+	// SeverityWarning reports the violation without failing the run.
+	SeverityWarning Severity = "warning"
 )
 
 // Scope defines where in the commit message to search.
@@ -31,52 +161,378 @@ const (
 	ScopeTitle Scope = "title"
 	// ScopeBody searches the middle sections (between title and footer).
 	ScopeBody Scope = "body"
+	// ScopeBodyLine searches each non-empty line of the body separately.
+	ScopeBodyLine Scope = "body_line"
+	// ScopeBodySection searches each paragraph of the body separately.
+	ScopeBodySection Scope = "body_section"
 	// ScopeFooter searches the last section (after final empty line).
 	ScopeFooter Scope = "footer"
+	// ScopeTrailer searches each value of the footer trailers with the key TrailerKey.
+	ScopeTrailer Scope = "trailer"
+	// ScopeTrailerBlock searches the trailer lines at the end of the footer.
+	ScopeTrailerBlock Scope = "trailer_block"
+	// ScopeFooterText searches the freeform footer text preceding the trailer lines.
+	ScopeFooterText Scope = "footer_text"
 	// ScopeMessage searches the complete commit message.
 	ScopeMessage Scope = "message"
+	// ScopeTitleAndBody searches the title and the body joined by an empty line,
+	// i.e. the complete commit message except the footer.
+	ScopeTitleAndBody Scope = "title_and_body"
+	// ScopeCCType searches the Conventional Commits type (e.g. "feat").
+	ScopeCCType Scope = "cc_type"
+	// ScopeCCScope searches the Conventional Commits scope (text in parentheses).
+	ScopeCCScope Scope = "cc_scope"
+	// ScopeCCDescription searches the Conventional Commits description (text after ": ").
+	ScopeCCDescription Scope = "cc_description"
 )
 
 // Config represents the complete configuration for commit message linting.
 type Config struct {
-	Rules    []Rule   `yaml:"rules"`
-	Settings Settings `yaml:"settings,omitempty"`
+	// Includes lists config files (relative to this file) whose rules and
+	// overrides are applied before the ones of this file. Settings of this file
+	// take precedence over included settings.
+	Includes []string `yaml:"includes,omitempty"`
+
+	// Extends lists built-in rule presets (e.g. "conventional-commits") whose
+	// rules are applied before the rules of this file.
+	Extends []string `yaml:"extends,omitempty"`
+
+	Rules     []Rule     `yaml:"rules"`
+	Overrides []Override `yaml:"overrides,omitempty"`
+	Settings  Settings   `yaml:"settings,omitempty"`
+}
+
+// Override defines additional rules that only apply to commits pushed to
+// (or evaluated as) one of the listed refs, e.g. stricter rules for main.
+type Override struct {
+	Refs  []string `yaml:"refs"`
+	Rules []Rule   `yaml:"rules"`
 }
 
 // Rule represents a single linting rule.
 type Rule struct {
-	Name    string   `yaml:"name"`
-	Type    RuleType `yaml:"type"`
-	Scope   Scope    `yaml:"scope"`
-	Pattern string   `yaml:"pattern"`
-	Message string   `yaml:"message,omitempty"`
+	Name     string   `yaml:"name"`
+	Type     RuleType `yaml:"type"`
+	Scope    Scope    `yaml:"scope"`
+	Pattern  string   `yaml:"pattern"`
+	Message  string   `yaml:"message,omitempty"`
+	Severity Severity `yaml:"severity,omitempty"`
+
+	// Patterns are several patterns of a deny or require rule instead of a single
+	// Pattern, combined according to Match.
+	Patterns []string `yaml:"patterns,omitempty"`
+
+	// Match combines the Patterns: "any" (default for deny rules) matches if any of
+	// them matches, "all" (default for require rules) if all of them match.
+	Match string `yaml:"match,omitempty"`
+
+	// AppliesTo limits the rule to commits with one of the listed Conventional
+	// Commits types. The rule applies to all commits if empty.
+	AppliesTo []string `yaml:"applies_to,omitempty"`
+
+	// FirstCommitOnly limits the rule to the newest commit of a validated range,
+	// i.e. the tip of the pushed branch, e.g. to require a PR reference only once.
+	FirstCommitOnly bool `yaml:"first_commit_only,omitempty"`
+
+	// IgnoreCase matches the pattern case-insensitively, as if it started with
+	// the "(?i)" flag.
+	IgnoreCase bool `yaml:"ignore_case,omitempty"`
+
+	// DotAll lets "." in the pattern match newlines as well, as if it started with
+	// the "(?s)" flag, e.g. to match across the lines of the body.
+	DotAll bool `yaml:"dotall,omitempty"`
+
+	// RawPattern opts a deny or require rule out of the pattern_prefix and
+	// pattern_suffix settings, its patterns are compiled as they are.
+	RawPattern bool `yaml:"raw_pattern,omitempty"`
+
+	// Exceptions are patterns that allow text matched by the pattern of a deny
+	// rule: the rule passes if any of them matches the text as well.
+	Exceptions []string `yaml:"exceptions,omitempty"`
+
+	// BranchTicketPattern exempts commits from a require rule if the name of the
+	// branch they are validated for matches this pattern, e.g. because the branch
+	// name already encodes the ticket the rule requires in the message.
+	BranchTicketPattern string `yaml:"branch_ticket_pattern,omitempty"`
+
+	// Verify checks that commits referenced by the rule exist in the repository
+	// (fix_references_cause).
+	Verify bool `yaml:"verify,omitempty"`
+
+	// AllowlistFile is the path of a newline-separated list of permitted author
+	// emails (author_email_allowlist). Relative paths are resolved against the
+	// directory of the config file.
+	AllowlistFile string `yaml:"allowlist_file,omitempty"`
+
+	// Limit is the maximum number of occurrences of any trailer key
+	// (max_trailer_repeats), the minimum length in characters (min_length), the
+	// maximum length of a body line in characters (body_wrap), the maximum or
+	// minimum number of words (max_words, min_words), or the maximum number of
+	// non-empty body lines (max_body_lines).
+	Limit int `yaml:"limit,omitempty"`
+
+	// Strictness defines when a title restates the branch name (subject_not_branch_name):
+	// "exact" (default) if it equals the descriptive part of the branch name,
+	// "contains" if it contains it.
+	Strictness string `yaml:"strictness,omitempty"`
+
+	// Allow lists first words of the title that are not reported although they
+	// look like a past tense or gerund verb, e.g. "Rendering" (imperative_subject),
+	// or the character classes of the permitted characters, e.g. "[ -~]" (charset).
+	Allow []string `yaml:"allow,omitempty"`
+
+	// Punctuation lists the characters the title must not end with
+	// (no_trailing_punctuation), defaults to ".,;:!?".
+	Punctuation string `yaml:"punctuation,omitempty"`
+
+	// PrefixPattern is the pattern of the issue references (require_issue_ref),
+	// matched as whole words. Defaults to "[A-Z]+-\d+".
+	PrefixPattern string `yaml:"prefix_pattern,omitempty"`
+
+	// TitlePattern selects the commits whose title must be followed by a body
+	// (require_body_when) or that must have the trailer (require_trailer, all
+	// commits if empty).
+	TitlePattern string `yaml:"title_pattern,omitempty"`
+
+	// IgnorePattern exempts body lines matching it from the length limit, e.g.
+	// lines containing a URL (body_wrap).
+	IgnorePattern string `yaml:"ignore_pattern,omitempty"`
+
+	// Template is the expected message structure (template_match): header lines
+	// that must be present in order, each optionally followed by a "{{name}}" hole
+	// that must be filled with non-empty content.
+	Template string `yaml:"template,omitempty"`
+
+	// ProtectedBranches lists the branches (full or short ref names) the rule
+	// applies to (revert_requires_approval).
+	ProtectedBranches []string `yaml:"protected_branches,omitempty"`
+	// TrailerKey is the key of the trailer whose values are checked (deny and
+	// require rules with scope trailer) or the trailer the rule requires in the
+	// footer (revert_requires_approval, defaults to "Approved-by", and
+	// require_trailer).
+	TrailerKey string `yaml:"trailer_key,omitempty"`
+	// ValuePattern must match each value of the required trailer
+	// (require_trailer, any non-empty value if unset).
+	ValuePattern string `yaml:"value_pattern,omitempty"`
+	// Signer is the identity the Signed-off-by trailer must match (dco):
+	// "author" (default) or "committer".
+	Signer string `yaml:"signer,omitempty"`
+	// ForbidLeadingWhitespace forbids lines starting with whitespace as well
+	// (no_trailing_whitespace).
+	ForbidLeadingWhitespace bool `yaml:"forbid_leading_whitespace,omitempty"`
 
 	// regex is the compiled regular expression (cached, not in YAML)
 	regex *regexp.Regexp
+	// patternRegexes are the compiled Pattern or Patterns of deny and require
+	// rules (cached, not in YAML)
+	patternRegexes []*regexp.Regexp
+	// branchTicketRegex is the compiled BranchTicketPattern (cached, not in YAML)
+	branchTicketRegex *regexp.Regexp
+	// exceptionRegexes are the compiled Exceptions (cached, not in YAML)
+	exceptionRegexes []*regexp.Regexp
+	// allowlist is the set of lowercased emails loaded from AllowlistFile (cached, not in YAML)
+	allowlist map[string]struct{}
+	// templateSections are the sections parsed from Template (cached, not in YAML)
+	templateSections []templateSection
+	// allowedWords is the set of lowercased words of Allow (cached, not in YAML)
+	allowedWords map[string]struct{}
+	// valueRegex is the compiled ValuePattern (cached, not in YAML)
+	valueRegex *regexp.Regexp
+	// patternPrefix is the pattern_prefix setting the patterns are compiled with
+	// (cached, not in YAML)
+	patternPrefix string
+	// patternSuffix is the pattern_suffix setting the patterns are compiled with
+	// (cached, not in YAML)
+	patternSuffix string
 }
 
 // Settings contains global configuration options.
 type Settings struct {
-	FailFast         bool     `yaml:"fail_fast,omitempty"`
+	FailFast         *bool    `yaml:"fail_fast,omitempty"`
 	SkipMergeCommits *bool    `yaml:"skip_merge_commits,omitempty"`
+	SkipFixupCommits *bool    `yaml:"skip_fixup_commits,omitempty"`
 	SkipAuthors      []string `yaml:"skip_authors,omitempty"`
+	SkipCommitters   []string `yaml:"skip_committers,omitempty"`
+	SkipSubjects     []string `yaml:"skip_subjects,omitempty"`
+	ProtectedRefs    []string `yaml:"protected_refs,omitempty"`
 	MainRef          string   `yaml:"main_ref,omitempty"`
+	// UseUpstreamAsBase defaults the base of --head-ref to the upstream tracking
+	// branch of the head branch instead of MainRef, which is still used as
+	// fallback if no upstream is configured.
+	UseUpstreamAsBase *bool `yaml:"use_upstream_as_base,omitempty"`
+	// MaxCommits is the maximum number of commits collected for a single ref or
+	// range, the validation fails if exceeded. Unlimited if zero (default).
+	MaxCommits int `yaml:"max_commits,omitempty"`
+	// CommentChar is the comment char of commit message files in commit-msg hook
+	// mode. Defaults to git's core.commentChar or '#'.
+	CommentChar string `yaml:"comment_char,omitempty"`
+	// AllowSkipTrailer allows commits to disable rules by name with a Lint-Skip
+	// trailer, e.g. "Lint-Skip: prevent-wip".
+	AllowSkipTrailer *bool `yaml:"allow_skip_trailer,omitempty"`
+	// ReportFullMessage reports the complete commit message of failed commits,
+	// indented, instead of only its first line.
+	ReportFullMessage *bool `yaml:"report_full_message,omitempty"`
+	// ReportMaxLines caps the commit message lines reported with
+	// ReportFullMessage. Defaults to 20.
+	ReportMaxLines int `yaml:"report_max_lines,omitempty"`
+	// Disabled skips all validation after loading the config. The
+	// COMMIT_MSG_LINT_DISABLE environment variable takes precedence.
+	Disabled *bool `yaml:"disabled,omitempty"`
+	// ExpandEnv expands "${VAR}" references in the patterns of rules from the
+	// environment before they are compiled, e.g. a ticket prefix per team.
+	ExpandEnv *bool `yaml:"expand_env,omitempty"`
+	// LenientMissingObjects skips the commit ranges with commits missing locally,
+	// e.g. in shallow clones, with a note on stderr instead of failing.
+	LenientMissingObjects *bool `yaml:"lenient_missing_objects,omitempty"`
+	// LintTags validates the message of annotated tags pushed in pre-push hook
+	// mode instead of the commits they point to.
+	LintTags *bool `yaml:"lint_tags,omitempty"`
+	// RequireMainRef fails if MainRef does not exist, e.g. in a fresh repository,
+	// instead of validating all commits of a new branch with a warning.
+	RequireMainRef *bool `yaml:"require_main_ref,omitempty"`
+	// PatternPrefix is prepended to the patterns of all deny and require rules
+	// without raw_pattern, e.g. to anchor them behind an optional "[skip ci] ".
+	PatternPrefix string `yaml:"pattern_prefix,omitempty"`
+	// PatternSuffix is appended to the patterns of all deny and require rules
+	// without raw_pattern.
+	PatternSuffix string `yaml:"pattern_suffix,omitempty"`
+
+	// skipAuthorRegexes are the compiled SkipAuthors patterns (cached, not in YAML)
+	skipAuthorRegexes []*regexp.Regexp
+	// skipCommitterRegexes are the compiled SkipCommitters patterns (cached, not in YAML)
+	skipCommitterRegexes []*regexp.Regexp
+	// skipSubjectRegexes are the compiled SkipSubjects patterns (cached, not in YAML)
+	skipSubjectRegexes []*regexp.Regexp
+	// protectedRefRegexes are the compiled ProtectedRefs patterns (cached, not in YAML)
+	protectedRefRegexes []*regexp.Regexp
 }
 
 // LoadConfig loads and validates configuration from the specified directory.
+// Without a config file in the directory, it falls back to the config file of the
+// user, e.g. a default config installed on centrally managed machines.
 func LoadConfig(repoPath string) (*Config, error) {
+	configPath, err := findConfigFile(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadConfigFile(configPath)
+}
+
+// findConfigFile returns the path of the config file LoadConfig loads for the
+// repository at repoPath: the DefaultConfigFile in the repository root if it
+// exists, otherwise the config file in the user config directory.
+func findConfigFile(repoPath string) (string, error) {
 	configPath := filepath.Join(repoPath, DefaultConfigFile)
 
 	// Check if config file exists
 	_, statErr := os.Stat(configPath)
-	if os.IsNotExist(statErr) {
-		return nil, fmt.Errorf(
+	if !os.IsNotExist(statErr) {
+		return configPath, nil
+	}
+
+	userPath, ok := userConfigPath()
+	if !ok {
+		return "", fmt.Errorf(
 			"config file not found: %s\nCreate %s in repository root with linting rules",
 			configPath,
 			DefaultConfigFile,
 		)
 	}
 
+	_, statErr = os.Stat(userPath)
+	if statErr == nil {
+		return userPath, nil
+	}
+
+	return "", fmt.Errorf(
+		"config file not found: %s\nCreate %s in repository root or %s with linting rules",
+		configPath,
+		DefaultConfigFile,
+		userPath,
+	)
+}
+
+// userConfigPath returns the path of the config file in the user config
+// directory, or false if the user config directory is unknown.
+func userConfigPath() (string, bool) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", false
+	}
+
+	return filepath.Join(dir, userConfigFile), true
+}
+
+// LoadConfigFile loads and validates configuration from the specified file.
+// The path may be absolute or relative to the current directory.
+func LoadConfigFile(configPath string) (*Config, error) {
+	// Check if config file exists
+	_, statErr := os.Stat(configPath)
+	if os.IsNotExist(statErr) {
+		return nil, fmt.Errorf("config file not found: %s", configPath)
+	}
+
+	// Read config file and its includes
+	config, err := readConfigFile(configPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate and compile patterns
+	err = validateConfig(config, filepath.Dir(configPath))
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return config, nil
+}
+
+// LoadConfigFromBytes loads and validates configuration from YAML data, e.g. a
+// shared ruleset embedded in a Go module with go:embed. Presets (extends) are
+// supported, but includes are not, as there is no config file to resolve them
+// against. Relative paths of rules (e.g. allowlist_file) are resolved against the
+// current directory.
+func LoadConfigFromBytes(data []byte) (*Config, error) {
+	var config Config
+	err := yaml.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config YAML: %w", err)
+	}
+
+	err = expandExtends(&config)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Includes) > 0 {
+		return nil, errors.New("includes are not supported by configs loaded from bytes")
+	}
+
+	// Validate and compile patterns
+	err = validateConfig(&config, currentDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// readConfigFile reads and parses a config file and merges the config files it
+// includes, recursively. The including paths are tracked in stack to detect
+// include cycles. The returned config is not validated yet.
+func readConfigFile(configPath string, stack []string) (*Config, error) {
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config file path: %w", err)
+	}
+
+	if slices.Contains(stack, absPath) {
+		return nil, fmt.Errorf("include cycle: %s", strings.Join(append(stack, absPath), " -> "))
+	}
+
+	stack = append(stack, absPath)
+
 	// Read config file
 	data, err := os.ReadFile(configPath)
 	if err != nil {
@@ -90,64 +546,1013 @@ func LoadConfig(repoPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config YAML: %w", err)
 	}
 
-	// Validate and compile patterns
-	err = validateConfig(&config)
+	err = expandExtends(&config)
 	if err != nil {
-		return nil, fmt.Errorf("invalid config: %w", err)
+		return nil, fmt.Errorf("%s: %w", configPath, err)
 	}
 
-	return &config, nil
+	if len(config.Includes) == 0 {
+		return &config, nil
+	}
+
+	baseDir := filepath.Dir(configPath)
+	merged := &Config{Includes: nil, Extends: nil, Rules: nil, Overrides: nil, Settings: Settings{}}
+	for _, include := range config.Includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(baseDir, include)
+		}
+
+		_, statErr := os.Stat(include)
+		if os.IsNotExist(statErr) {
+			return nil, fmt.Errorf("included config file not found: %s (included from %s)", include, configPath)
+		}
+
+		included, includeErr := readConfigFile(include, stack)
+		if includeErr != nil {
+			return nil, includeErr
+		}
+
+		// Relative paths of included rules are relative to the file defining them
+		resolveRulePaths(included.Rules, filepath.Dir(include))
+		for i := range included.Overrides {
+			resolveRulePaths(included.Overrides[i].Rules, filepath.Dir(include))
+		}
+
+		mergeConfig(merged, included)
+	}
+
+	mergeConfig(merged, &config)
+
+	return merged, nil
+}
+
+// resolveRulePaths makes the relative file paths of rules absolute, resolved
+// against baseDir, so they stay valid when the rules are merged into another config.
+func resolveRulePaths(rules []Rule, baseDir string) {
+	for i := range rules {
+		if rules[i].AllowlistFile != "" && !filepath.IsAbs(rules[i].AllowlistFile) {
+			rules[i].AllowlistFile = filepath.Join(baseDir, rules[i].AllowlistFile)
+		}
+	}
+}
+
+// mergeConfig merges src into dst: rules and overrides are appended, settings
+// set in src take precedence over the ones of dst. Boolean settings set to false
+// in src disable the ones enabled in dst.
+func mergeConfig(dst *Config, src *Config) {
+	dst.Rules = append(dst.Rules, src.Rules...)
+	dst.Overrides = append(dst.Overrides, src.Overrides...)
+
+	mergeBool(&dst.Settings.FailFast, src.Settings.FailFast)
+	mergeBool(&dst.Settings.SkipMergeCommits, src.Settings.SkipMergeCommits)
+	mergeBool(&dst.Settings.SkipFixupCommits, src.Settings.SkipFixupCommits)
+	mergeBool(&dst.Settings.AllowSkipTrailer, src.Settings.AllowSkipTrailer)
+	mergeBool(&dst.Settings.UseUpstreamAsBase, src.Settings.UseUpstreamAsBase)
+	mergeBool(&dst.Settings.ReportFullMessage, src.Settings.ReportFullMessage)
+	mergeBool(&dst.Settings.Disabled, src.Settings.Disabled)
+	mergeBool(&dst.Settings.ExpandEnv, src.Settings.ExpandEnv)
+	mergeBool(&dst.Settings.LenientMissingObjects, src.Settings.LenientMissingObjects)
+	mergeBool(&dst.Settings.LintTags, src.Settings.LintTags)
+	mergeBool(&dst.Settings.RequireMainRef, src.Settings.RequireMainRef)
+
+	if src.Settings.SkipAuthors != nil {
+		dst.Settings.SkipAuthors = src.Settings.SkipAuthors
+	}
+
+	if src.Settings.SkipCommitters != nil {
+		dst.Settings.SkipCommitters = src.Settings.SkipCommitters
+	}
+
+	if src.Settings.SkipSubjects != nil {
+		dst.Settings.SkipSubjects = src.Settings.SkipSubjects
+	}
+
+	if src.Settings.ProtectedRefs != nil {
+		dst.Settings.ProtectedRefs = src.Settings.ProtectedRefs
+	}
+
+	dst.Settings.MainRef = cmp.Or(src.Settings.MainRef, dst.Settings.MainRef)
+	dst.Settings.MaxCommits = cmp.Or(src.Settings.MaxCommits, dst.Settings.MaxCommits)
+	dst.Settings.ReportMaxLines = cmp.Or(src.Settings.ReportMaxLines, dst.Settings.ReportMaxLines)
+	dst.Settings.CommentChar = cmp.Or(src.Settings.CommentChar, dst.Settings.CommentChar)
+	dst.Settings.PatternPrefix = cmp.Or(src.Settings.PatternPrefix, dst.Settings.PatternPrefix)
+	dst.Settings.PatternSuffix = cmp.Or(src.Settings.PatternSuffix, dst.Settings.PatternSuffix)
+}
+
+// mergeBool replaces the boolean setting dst with src, if set.
+func mergeBool(dst **bool, src *bool) {
+	if src != nil {
+		*dst = src
+	}
 }
 
-func validateConfig(config *Config) error {
+// isEnabled reports whether the optional boolean setting is set to true.
+func isEnabled(setting *bool) bool {
+	return setting != nil && *setting
+}
+
+// validateConfig validates the config and caches compiled rule data. Files
+// referenced by rules are resolved relative to baseDir.
+func validateConfig(config *Config, baseDir string) error {
 	if len(config.Rules) == 0 {
 		return errors.New("no rules defined in config")
 	}
 
-	for i := range config.Rules {
-		rule := &config.Rules[i]
+	if isEnabled(config.Settings.ExpandEnv) {
+		err := expandConfigEnv(config)
+		if err != nil {
+			return err
+		}
+	}
 
-		// Validate rule name
-		if rule.Name == "" {
-			return fmt.Errorf("rule %d: name is required", i)
+	applyPatternAffixes(config)
+
+	for i := range config.Rules {
+		err := validateRule(i, &config.Rules[i], baseDir)
+		if err != nil {
+			return err
 		}
+	}
+
+	err := checkDuplicateRuleNames(config.Rules, nil)
+	if err != nil {
+		return err
+	}
+
+	// Validate per-ref overrides
+	for i := range config.Overrides {
+		override := &config.Overrides[i]
 
-		// Validate rule type
-		if rule.Type != RuleTypeDeny && rule.Type != RuleTypeRequire {
-			return fmt.Errorf("rule %q: type must be 'deny' or 'require', got %q", rule.Name, rule.Type)
+		if len(override.Refs) == 0 {
+			return fmt.Errorf("overrides[%d]: at least one ref is required", i)
 		}
 
-		// Validate scope
-		if rule.Scope != ScopeTitle && rule.Scope != ScopeBody &&
-			rule.Scope != ScopeFooter && rule.Scope != ScopeMessage {
-			return fmt.Errorf(
-				"rule %q: scope must be 'title', 'body', 'footer', or 'message', got %q",
-				rule.Name,
-				rule.Scope,
-			)
+		if len(override.Rules) == 0 {
+			return fmt.Errorf("overrides[%d]: at least one rule is required", i)
 		}
 
-		// Validate pattern (compile regex)
-		if rule.Pattern == "" {
-			return fmt.Errorf("rule %q: pattern is required", rule.Name)
+		for j := range override.Rules {
+			err := validateRule(j, &override.Rules[j], baseDir)
+			if err != nil {
+				return fmt.Errorf("overrides[%d]: %w", i, err)
+			}
 		}
 
-		re, err := regexp.Compile(rule.Pattern)
+		// The rules of an override are evaluated together with the top-level rules
+		err := checkDuplicateRuleNames(override.Rules, config.Rules)
 		if err != nil {
-			return fmt.Errorf("rule %q: invalid regex pattern: %w", rule.Name, err)
+			return fmt.Errorf("overrides[%d]: %w", i, err)
 		}
+	}
 
-		// Cache the compiled regex
-		rule.regex = re
+	if config.Settings.MaxCommits < 0 {
+		return fmt.Errorf("max_commits must not be negative, got %d", config.Settings.MaxCommits)
 	}
 
-	// Validate skip_authors patterns
+	if config.Settings.ReportMaxLines < 0 {
+		return fmt.Errorf("report_max_lines must not be negative, got %d", config.Settings.ReportMaxLines)
+	}
+
+	// Validate and cache skip_authors patterns
+	config.Settings.skipAuthorRegexes = make([]*regexp.Regexp, 0, len(config.Settings.SkipAuthors))
 	for i, pattern := range config.Settings.SkipAuthors {
-		_, compileErr := regexp.Compile(pattern)
+		re, compileErr := regexp.Compile(pattern)
 		if compileErr != nil {
 			return fmt.Errorf("skip_authors[%d]: invalid regex pattern %q: %w", i, pattern, compileErr)
 		}
+
+		config.Settings.skipAuthorRegexes = append(config.Settings.skipAuthorRegexes, re)
+	}
+
+	// Validate and cache skip_committers patterns
+	config.Settings.skipCommitterRegexes = make([]*regexp.Regexp, 0, len(config.Settings.SkipCommitters))
+	for i, pattern := range config.Settings.SkipCommitters {
+		re, compileErr := regexp.Compile(pattern)
+		if compileErr != nil {
+			return fmt.Errorf("skip_committers[%d]: invalid regex pattern %q: %w", i, pattern, compileErr)
+		}
+
+		config.Settings.skipCommitterRegexes = append(config.Settings.skipCommitterRegexes, re)
+	}
+
+	// Validate and cache skip_subjects patterns
+	config.Settings.skipSubjectRegexes = make([]*regexp.Regexp, 0, len(config.Settings.SkipSubjects))
+	for i, pattern := range config.Settings.SkipSubjects {
+		re, compileErr := regexp.Compile(pattern)
+		if compileErr != nil {
+			return fmt.Errorf("skip_subjects[%d]: invalid regex pattern %q: %w", i, pattern, compileErr)
+		}
+
+		config.Settings.skipSubjectRegexes = append(config.Settings.skipSubjectRegexes, re)
+	}
+
+	// Validate and cache protected_refs patterns
+	config.Settings.protectedRefRegexes = make([]*regexp.Regexp, 0, len(config.Settings.ProtectedRefs))
+	for i, pattern := range config.Settings.ProtectedRefs {
+		re, compileErr := regexp.Compile(pattern)
+		if compileErr != nil {
+			return fmt.Errorf("protected_refs[%d]: invalid regex pattern %q: %w", i, pattern, compileErr)
+		}
+
+		config.Settings.protectedRefRegexes = append(config.Settings.protectedRefRegexes, re)
+	}
+
+	return nil
+}
+
+// applyPatternAffixes sets the pattern_prefix and pattern_suffix settings the
+// patterns of the deny and require rules, including the rules of overrides, are
+// compiled with, unless they set raw_pattern. The configured patterns are kept
+// unchanged for the reports.
+func applyPatternAffixes(config *Config) {
+	prefix, suffix := config.Settings.PatternPrefix, config.Settings.PatternSuffix
+	if prefix == "" && suffix == "" {
+		return
+	}
+
+	rules := make([]*Rule, 0, len(config.Rules))
+	for i := range config.Rules {
+		rules = append(rules, &config.Rules[i])
+	}
+
+	for i := range config.Overrides {
+		for j := range config.Overrides[i].Rules {
+			rules = append(rules, &config.Overrides[i].Rules[j])
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.RawPattern || (rule.Type != RuleTypeDeny && rule.Type != RuleTypeRequire) {
+			continue
+		}
+
+		rule.patternPrefix, rule.patternSuffix = prefix, suffix
+	}
+}
+
+// wrapPattern wraps a pattern of rule in its pattern prefix and suffix, if any.
+// The pattern is grouped, so its alternations and inline flags do not extend to
+// the prefix and suffix. A pattern anchored at the start of the text can never
+// match after a prefix, so it is rejected.
+func wrapPattern(rule *Rule, pattern string) (string, error) {
+	if rule.patternPrefix == "" && rule.patternSuffix == "" {
+		return pattern, nil
+	}
+
+	if rule.patternPrefix != "" && (strings.HasPrefix(pattern, "^") || strings.HasPrefix(pattern, `\A`)) {
+		return "", fmt.Errorf("pattern %q is anchored and can not match after pattern_prefix, "+
+			"remove the anchor or set raw_pattern", pattern)
+	}
+
+	return rule.patternPrefix + "(?:" + pattern + ")" + rule.patternSuffix, nil
+}
+
+// expandConfigEnv expands the environment variable references in the patterns of
+// all rules, including the rules of overrides.
+func expandConfigEnv(config *Config) error {
+	for i := range config.Rules {
+		err := expandRuleEnv(&config.Rules[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range config.Overrides {
+		for j := range config.Overrides[i].Rules {
+			err := expandRuleEnv(&config.Overrides[i].Rules[j])
+			if err != nil {
+				return fmt.Errorf("overrides[%d]: %w", i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// expandRuleEnv expands the environment variable references in the patterns of a
+// rule. The patterns are compiled afterwards by validateRule.
+func expandRuleEnv(rule *Rule) error {
+	type patternField struct {
+		name  string
+		value *string
+	}
+
+	fields := []patternField{
+		{name: "pattern", value: &rule.Pattern},
+		{name: "branch_ticket_pattern", value: &rule.BranchTicketPattern},
+		{name: "prefix_pattern", value: &rule.PrefixPattern},
+		{name: "title_pattern", value: &rule.TitlePattern},
+		{name: "ignore_pattern", value: &rule.IgnorePattern},
+		{name: "value_pattern", value: &rule.ValuePattern},
+	}
+
+	for i := range rule.Patterns {
+		fields = append(fields, patternField{name: fmt.Sprintf("patterns[%d]", i), value: &rule.Patterns[i]})
+	}
+
+	for i := range rule.Exceptions {
+		fields = append(fields, patternField{name: fmt.Sprintf("exceptions[%d]", i), value: &rule.Exceptions[i]})
+	}
+
+	for _, field := range fields {
+		expanded, err := expandEnv(*field.value)
+		if err != nil {
+			return fmt.Errorf("rule %q: %s: %w", rule.Name, field.name, err)
+		}
+
+		*field.value = expanded
+	}
+
+	return nil
+}
+
+// expandEnv replaces the "${VAR}" references in s with the values of the
+// environment variables. Unset variables are reported as an error instead of
+// silently producing a broken pattern; variables set to an empty value are
+// expanded to the empty string.
+func expandEnv(s string) (string, error) {
+	var unset []string
+
+	expanded := envReferenceRegex.ReplaceAllStringFunc(s, func(reference string) string {
+		name := envReferenceRegex.FindStringSubmatch(reference)[1]
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			unset = append(unset, name)
+		}
+
+		return value
+	})
+
+	if len(unset) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(unset, ", "))
+	}
+
+	return expanded, nil
+}
+
+// checkDuplicateRuleNames returns an error naming the first rule of rules whose
+// name is used by another rule of rules or of existing, as reports could not tell
+// them apart.
+func checkDuplicateRuleNames(rules []Rule, existing []Rule) error {
+	names := make(map[string]struct{}, len(rules)+len(existing))
+	for _, rule := range existing {
+		names[rule.Name] = struct{}{}
+	}
+
+	for _, rule := range rules {
+		if _, ok := names[rule.Name]; ok {
+			return fmt.Errorf("rule %q: duplicate rule name", rule.Name)
+		}
+
+		names[rule.Name] = struct{}{}
 	}
 
 	return nil
 }
+
+// validateRule validates a single rule and caches its compiled pattern.
+// The index is only used to identify rules without a name in error messages.
+func validateRule(index int, rule *Rule, baseDir string) error {
+	// Validate rule name
+	if rule.Name == "" {
+		return fmt.Errorf("rule %d: name is required", index)
+	}
+
+	// Validate severity, defaulting to error
+	switch rule.Severity {
+	case "":
+		rule.Severity = SeverityError
+
+	case SeverityError, SeverityWarning:
+
+	default:
+		return fmt.Errorf("rule %q: severity must be 'error' or 'warning', got %q", rule.Name, rule.Severity)
+	}
+
+	// Validate applies_to types
+	for _, ccType := range rule.AppliesTo {
+		if !isConventionalCommitType(ccType) {
+			return fmt.Errorf(
+				"rule %q: applies_to must only contain Conventional Commits types "+
+					"(build, chore, ci, docs, feat, fix, perf, refactor, revert, style, test), got %q",
+				rule.Name,
+				ccType,
+			)
+		}
+	}
+
+	if rule.RawPattern && rule.Type != RuleTypeDeny && rule.Type != RuleTypeRequire {
+		return fmt.Errorf("rule %q: raw_pattern is only supported for deny and require rules", rule.Name)
+	}
+
+	// Validate type specific settings
+	switch rule.Type {
+	case RuleTypeDeny, RuleTypeRequire:
+		return validatePatternRule(rule)
+
+	case RuleTypeFixReferencesCause:
+		return nil
+
+	case RuleTypeAuthorEmailAllowlist:
+		return validateAuthorEmailAllowlistRule(rule, baseDir)
+
+	case RuleTypeNoRepeatedWords:
+		return validateNoRepeatedWordsRule(rule)
+
+	case RuleTypeRevertRequiresApproval:
+		return validateRevertRequiresApprovalRule(rule)
+
+	case RuleTypeTemplateMatch:
+		return validateTemplateMatchRule(rule)
+
+	case RuleTypeNoQuestionSubject:
+		return validateNoQuestionSubjectRule(rule)
+
+	case RuleTypeSubjectNotBranchName:
+		switch rule.Strictness {
+		case "":
+			rule.Strictness = strictnessExact
+
+		case strictnessExact, strictnessContains:
+
+		default:
+			return fmt.Errorf("rule %q: strictness must be 'exact' or 'contains', got %q", rule.Name, rule.Strictness)
+		}
+
+		return nil
+
+	case RuleTypeMinLength, RuleTypeMaxWords, RuleTypeMinWords:
+		return validateLengthRule(rule)
+
+	case RuleTypeRequireBodyWhen:
+		return validateRequireBodyWhenRule(rule)
+
+	case RuleTypeImperativeSubject:
+		return validateImperativeSubjectRule(rule)
+
+	case RuleTypeNoTrailingPunctuation:
+		return validateNoTrailingPunctuationRule(rule)
+
+	case RuleTypeRequireIssueRef:
+		return validateRequireIssueRefRule(rule)
+
+	case RuleTypeCharset:
+		return validateCharsetRule(rule)
+
+	case RuleTypeBodyWrap:
+		return validateBodyWrapRule(rule)
+
+	case RuleTypeDCO:
+		switch rule.Signer {
+		case "":
+			rule.Signer = signerAuthor
+
+		case signerAuthor, signerCommitter:
+
+		default:
+			return fmt.Errorf("rule %q: signer must be 'author' or 'committer', got %q", rule.Name, rule.Signer)
+		}
+
+		return nil
+
+	case RuleTypeNoMergeCommits, RuleTypeCCLowercase:
+		return nil
+
+	case RuleTypeRequireTrailer:
+		return validateRequireTrailerRule(rule)
+
+	case RuleTypeBlankLineAfterTitle, RuleTypeNoTrailingWhitespace:
+		if rule.Pattern != "" || len(rule.Patterns) > 0 {
+			return fmt.Errorf("rule %q: pattern is not supported by %s rules", rule.Name, rule.Type)
+		}
+
+		return nil
+
+	case RuleTypeMaxTrailerRepeats, RuleTypeMaxBodyLines:
+		if rule.Limit <= 0 {
+			return fmt.Errorf("rule %q: limit must be greater than 0, got %d", rule.Name, rule.Limit)
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf(
+			"rule %q: type must be 'deny' or 'require' or a built-in rule type, got %q",
+			rule.Name,
+			rule.Type,
+		)
+	}
+}
+
+// isConventionalCommitType reports whether ccType is one of the commonly used
+// Conventional Commits types (as defined by @commitlint/config-conventional).
+func isConventionalCommitType(ccType string) bool {
+	switch ccType {
+	case "build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test":
+		return true
+
+	default:
+		return false
+	}
+}
+
+// validatePatternRule validates the scope and pattern of a deny or require rule
+// and caches the compiled pattern.
+func validatePatternRule(rule *Rule) error {
+	// Validate scope
+	switch rule.Scope {
+	case ScopeTitle, ScopeBody, ScopeBodyLine, ScopeBodySection, ScopeFooter, ScopeMessage, ScopeTitleAndBody,
+		ScopeCCType, ScopeCCScope, ScopeCCDescription, ScopeTrailer, ScopeTrailerBlock, ScopeFooterText:
+
+	default:
+		return fmt.Errorf(
+			"rule %q: scope must be 'title', 'body', 'footer', or 'message' "+
+				"(or 'title_and_body', 'body_line', 'body_section', 'trailer', 'trailer_block', 'footer_text', "+
+				"'cc_type', 'cc_scope', 'cc_description'), got %q",
+			rule.Name,
+			rule.Scope,
+		)
+	}
+
+	// Validate trailer key, only meaningful for the trailer scope
+	switch {
+	case rule.Scope == ScopeTrailer && rule.TrailerKey == "":
+		return fmt.Errorf("rule %q: trailer_key is required for scope 'trailer'", rule.Name)
+
+	case rule.Scope != ScopeTrailer && rule.TrailerKey != "":
+		return fmt.Errorf("rule %q: trailer_key is only supported for scope 'trailer'", rule.Name)
+
+	case strings.ContainsAny(rule.TrailerKey, ": \t"):
+		return fmt.Errorf("rule %q: trailer_key must not contain colons or whitespace, got %q", rule.Name, rule.TrailerKey)
+	}
+
+	err := validateRulePatterns(rule)
+	if err != nil {
+		return err
+	}
+
+	// Validate and cache exception patterns, only meaningful for deny rules
+	if len(rule.Exceptions) > 0 && rule.Type != RuleTypeDeny {
+		return fmt.Errorf("rule %q: exceptions are only supported for deny rules", rule.Name)
+	}
+
+	rule.exceptionRegexes = make([]*regexp.Regexp, 0, len(rule.Exceptions))
+	for i, exception := range rule.Exceptions {
+		exceptionRe, compileErr := compilePattern(rule, exception)
+		if compileErr != nil {
+			return fmt.Errorf("rule %q: exceptions[%d]: invalid regex pattern %q: %w", rule.Name, i, exception, compileErr)
+		}
+
+		rule.exceptionRegexes = append(rule.exceptionRegexes, exceptionRe)
+	}
+
+	// Validate branch ticket pattern, only meaningful for require rules
+	if rule.BranchTicketPattern != "" {
+		if rule.Type != RuleTypeRequire {
+			return fmt.Errorf("rule %q: branch_ticket_pattern is only supported for require rules", rule.Name)
+		}
+
+		branchRe, err := regexp.Compile(rule.BranchTicketPattern)
+		if err != nil {
+			return fmt.Errorf("rule %q: invalid branch_ticket_pattern: %w", rule.Name, err)
+		}
+
+		rule.branchTicketRegex = branchRe
+	}
+
+	return nil
+}
+
+// validateRulePatterns validates the pattern or the patterns and the match mode
+// of a deny or require rule and caches the compiled patterns. The match mode
+// defaults to "any" for deny and "all" for require rules.
+func validateRulePatterns(rule *Rule) error {
+	switch {
+	case rule.Pattern == "" && len(rule.Patterns) == 0:
+		return fmt.Errorf("rule %q: pattern is required", rule.Name)
+
+	case rule.Pattern != "" && len(rule.Patterns) > 0:
+		return fmt.Errorf("rule %q: pattern and patterns can not be combined", rule.Name)
+	}
+
+	switch rule.Match {
+	case "":
+		rule.Match = matchAll
+		if rule.Type == RuleTypeDeny {
+			rule.Match = matchAny
+		}
+
+	case matchAny, matchAll:
+
+	default:
+		return fmt.Errorf("rule %q: match must be 'any' or 'all', got %q", rule.Name, rule.Match)
+	}
+
+	patterns := rulePatterns(*rule)
+	rule.patternRegexes = make([]*regexp.Regexp, 0, len(patterns))
+
+	for i, pattern := range patterns {
+		if pattern == "" {
+			return fmt.Errorf("rule %q: patterns[%d]: must not be empty", rule.Name, i)
+		}
+
+		wrapped, err := wrapPattern(rule, pattern)
+		if err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+
+		re, err := compilePattern(rule, wrapped)
+		if err != nil {
+			if len(rule.Patterns) > 0 {
+				return fmt.Errorf("rule %q: patterns[%d]: invalid regex pattern: %w", rule.Name, i, err)
+			}
+
+			return fmt.Errorf("rule %q: invalid regex pattern: %w", rule.Name, err)
+		}
+
+		rule.patternRegexes = append(rule.patternRegexes, re)
+	}
+
+	return nil
+}
+
+// rulePatterns returns the patterns of a deny or require rule, i.e. its Patterns
+// or its single Pattern.
+func rulePatterns(rule Rule) []string {
+	if len(rule.Patterns) > 0 {
+		return rule.Patterns
+	}
+
+	return []string{rule.Pattern}
+}
+
+// validateAuthorEmailAllowlistRule loads the allowlist file of an
+// author_email_allowlist rule once and caches it as a set.
+func validateAuthorEmailAllowlistRule(rule *Rule, baseDir string) error {
+	if rule.AllowlistFile == "" {
+		return fmt.Errorf("rule %q: allowlist_file is required", rule.Name)
+	}
+
+	path := rule.AllowlistFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("rule %q: failed to read allowlist file: %w", rule.Name, err)
+	}
+
+	// One email per line, empty lines and lines starting with '#' are ignored
+	rule.allowlist = make(map[string]struct{})
+	for line := range strings.Lines(string(data)) {
+		email := strings.TrimSpace(line)
+		if email == "" || strings.HasPrefix(email, "#") {
+			continue
+		}
+
+		rule.allowlist[strings.ToLower(email)] = struct{}{}
+	}
+
+	return nil
+}
+
+// compilePattern compiles a pattern of a rule, prepending the "(?i)" flag if
+// ignore_case is set and the pattern does not enable it already, and the "(?s)"
+// flag if dotall is set.
+func compilePattern(rule *Rule, pattern string) (*regexp.Regexp, error) {
+	if rule.IgnoreCase && !inlineIgnoreCaseRegex.MatchString(pattern) {
+		pattern = "(?i)" + pattern
+	}
+
+	if rule.DotAll {
+		pattern = "(?s)" + pattern
+	}
+
+	return regexp.Compile(pattern)
+}
+
+// validateNoRepeatedWordsRule validates the scope of a no_repeated_words rule,
+// defaulting to the title.
+func validateNoRepeatedWordsRule(rule *Rule) error {
+	switch rule.Scope {
+	case "":
+		rule.Scope = ScopeTitle
+
+	case ScopeTitle, ScopeBody, ScopeMessage:
+
+	default:
+		return fmt.Errorf("rule %q: scope must be 'title', 'body', or 'message', got %q", rule.Name, rule.Scope)
+	}
+
+	return nil
+}
+
+// validateLengthRule validates the scope and limit of a min_length, max_words, or
+// min_words rule, defaulting to the title.
+func validateLengthRule(rule *Rule) error {
+	switch rule.Scope {
+	case "":
+		rule.Scope = ScopeTitle
+
+	case ScopeTitle, ScopeBody, ScopeFooter, ScopeMessage, ScopeCCType, ScopeCCScope, ScopeCCDescription:
+
+	default:
+		return fmt.Errorf(
+			"rule %q: scope must be 'title', 'body', 'footer', or 'message' "+
+				"(or 'cc_type', 'cc_scope', 'cc_description'), got %q",
+			rule.Name,
+			rule.Scope,
+		)
+	}
+
+	if rule.Limit <= 0 {
+		return fmt.Errorf("rule %q: limit must be greater than 0, got %d", rule.Name, rule.Limit)
+	}
+
+	return nil
+}
+
+// validateTemplateMatchRule parses the template of a template_match rule once
+// and caches its sections.
+func validateTemplateMatchRule(rule *Rule) error {
+	if strings.TrimSpace(rule.Template) == "" {
+		return fmt.Errorf("rule %q: template is required", rule.Name)
+	}
+
+	sections, err := parseTemplate(rule.Template)
+	if err != nil {
+		return fmt.Errorf("rule %q: invalid template: %w", rule.Name, err)
+	}
+
+	rule.templateSections = sections
+
+	return nil
+}
+
+// validateNoQuestionSubjectRule compiles the pattern of a no_question_subject
+// rule, defaulting to titles ending with a question mark.
+func validateNoQuestionSubjectRule(rule *Rule) error {
+	if rule.Pattern == "" {
+		rule.Pattern = defaultQuestionSubjectPattern
+	}
+
+	re, err := compilePattern(rule, rule.Pattern)
+	if err != nil {
+		return fmt.Errorf("rule %q: invalid regex pattern: %w", rule.Name, err)
+	}
+
+	rule.regex = re
+
+	return nil
+}
+
+// validateRequireBodyWhenRule validates a require_body_when rule and caches its
+// compiled title pattern.
+func validateRequireBodyWhenRule(rule *Rule) error {
+	if rule.TitlePattern == "" {
+		return fmt.Errorf("rule %q: title_pattern is required", rule.Name)
+	}
+
+	re, err := compilePattern(rule, rule.TitlePattern)
+	if err != nil {
+		return fmt.Errorf("rule %q: invalid title_pattern: %w", rule.Name, err)
+	}
+
+	rule.regex = re
+
+	return nil
+}
+
+// validateRequireIssueRefRule validates the scope and the prefix pattern of a
+// require_issue_ref rule, defaulting to the title and "[A-Z]+-\d+", and caches the
+// compiled pattern matching the issue references as whole words.
+func validateRequireIssueRefRule(rule *Rule) error {
+	switch rule.Scope {
+	case "":
+		rule.Scope = ScopeTitle
+
+	case ScopeTitle, ScopeFooter:
+
+	default:
+		return fmt.Errorf("rule %q: scope must be 'title' or 'footer', got %q", rule.Name, rule.Scope)
+	}
+
+	if rule.PrefixPattern == "" {
+		rule.PrefixPattern = defaultIssueRefPrefixPattern
+	}
+
+	prefixRegex, err := regexp.Compile(rule.PrefixPattern)
+	if err != nil {
+		return fmt.Errorf("rule %q: invalid prefix_pattern: %w", rule.Name, err)
+	}
+
+	// A pattern matching empty text would accept messages without any reference
+	if prefixRegex.MatchString("") {
+		return fmt.Errorf("rule %q: prefix_pattern must not match empty text, got %q", rule.Name, rule.PrefixPattern)
+	}
+
+	rule.regex, err = compilePattern(rule, `\b(?:`+rule.PrefixPattern+`)\b`)
+	if err != nil {
+		return fmt.Errorf("rule %q: invalid prefix_pattern: %w", rule.Name, err)
+	}
+
+	return nil
+}
+
+// validateNoTrailingPunctuationRule validates the punctuation set of a
+// no_trailing_punctuation rule, defaulting to ".,;:!?".
+func validateNoTrailingPunctuationRule(rule *Rule) error {
+	if rule.Punctuation == "" {
+		rule.Punctuation = defaultTrailingPunctuation
+		return nil
+	}
+
+	for _, r := range rule.Punctuation {
+		if !unicode.IsPunct(r) && !unicode.IsSymbol(r) {
+			return fmt.Errorf("rule %q: punctuation must only contain punctuation characters, got %q", rule.Name, r)
+		}
+	}
+
+	return nil
+}
+
+// validateCharsetRule validates the scope and the allowed character classes of a
+// charset rule, defaulting to the title, and caches a pattern matching a single
+// allowed character.
+func validateCharsetRule(rule *Rule) error {
+	switch rule.Scope {
+	case "":
+		rule.Scope = ScopeTitle
+
+	case ScopeTitle, ScopeBody, ScopeFooter, ScopeMessage:
+
+	default:
+		return fmt.Errorf("rule %q: scope must be 'title', 'body', 'footer', or 'message', got %q", rule.Name, rule.Scope)
+	}
+
+	if len(rule.Allow) == 0 {
+		return fmt.Errorf("rule %q: allow is required", rule.Name)
+	}
+
+	classes := make([]string, 0, len(rule.Allow))
+	for i, class := range rule.Allow {
+		if class == "" {
+			return fmt.Errorf("rule %q: allow[%d]: must not be empty", rule.Name, i)
+		}
+
+		if _, err := regexp.Compile(class); err != nil {
+			return fmt.Errorf("rule %q: allow[%d]: invalid regex pattern: %w", rule.Name, i, err)
+		}
+
+		classes = append(classes, "(?:"+class+")")
+	}
+
+	re, err := compilePattern(rule, `\A(?:`+strings.Join(classes, "|")+`)\z`)
+	if err != nil {
+		return fmt.Errorf("rule %q: invalid allow: %w", rule.Name, err)
+	}
+
+	rule.regex = re
+
+	return nil
+}
+
+// validateBodyWrapRule validates the limit of a body_wrap rule and caches its
+// compiled ignore pattern, if any.
+func validateBodyWrapRule(rule *Rule) error {
+	if rule.Limit <= 0 {
+		return fmt.Errorf("rule %q: limit must be greater than 0, got %d", rule.Name, rule.Limit)
+	}
+
+	if rule.IgnorePattern == "" {
+		return nil
+	}
+
+	re, err := compilePattern(rule, rule.IgnorePattern)
+	if err != nil {
+		return fmt.Errorf("rule %q: invalid ignore_pattern: %w", rule.Name, err)
+	}
+
+	rule.regex = re
+
+	return nil
+}
+
+// validateImperativeSubjectRule validates the allow list of an imperative_subject
+// rule and caches it as a set of lowercased words.
+func validateImperativeSubjectRule(rule *Rule) error {
+	rule.allowedWords = make(map[string]struct{}, len(rule.Allow))
+	for i, word := range rule.Allow {
+		if word == "" || strings.IndexFunc(word, isNotWordChar) >= 0 {
+			return fmt.Errorf("rule %q: allow[%d]: must be a single word, got %q", rule.Name, i, word)
+		}
+
+		rule.allowedWords[strings.ToLower(word)] = struct{}{}
+	}
+
+	return nil
+}
+
+// validateRevertRequiresApprovalRule validates a revert_requires_approval rule,
+// defaulting the trailer key to "Approved-by".
+func validateRevertRequiresApprovalRule(rule *Rule) error {
+	if len(rule.ProtectedBranches) == 0 {
+		return fmt.Errorf("rule %q: protected_branches is required", rule.Name)
+	}
+
+	if rule.TrailerKey == "" {
+		rule.TrailerKey = defaultApprovalTrailerKey
+	}
+
+	if strings.ContainsAny(rule.TrailerKey, ": \t") {
+		return fmt.Errorf("rule %q: trailer_key must not contain colons or whitespace, got %q", rule.Name, rule.TrailerKey)
+	}
+
+	return nil
+}
+
+// validateRequireTrailerRule validates a require_trailer rule and caches its
+// compiled title and value patterns.
+func validateRequireTrailerRule(rule *Rule) error {
+	if rule.TrailerKey == "" {
+		return fmt.Errorf("rule %q: trailer_key is required", rule.Name)
+	}
+
+	if strings.ContainsAny(rule.TrailerKey, ": \t") {
+		return fmt.Errorf("rule %q: trailer_key must not contain colons or whitespace, got %q", rule.Name, rule.TrailerKey)
+	}
+
+	if rule.Pattern != "" || len(rule.Patterns) > 0 {
+		return fmt.Errorf("rule %q: pattern is not supported by require_trailer rules, use value_pattern", rule.Name)
+	}
+
+	if rule.TitlePattern != "" {
+		re, err := compilePattern(rule, rule.TitlePattern)
+		if err != nil {
+			return fmt.Errorf("rule %q: invalid title_pattern: %w", rule.Name, err)
+		}
+
+		rule.regex = re
+	}
+
+	if rule.ValuePattern != "" {
+		re, err := compilePattern(rule, rule.ValuePattern)
+		if err != nil {
+			return fmt.Errorf("rule %q: invalid value_pattern: %w", rule.Name, err)
+		}
+
+		rule.valueRegex = re
+	}
+
+	return nil
+}
+
+// configForRef returns a copy of config whose rules include the rules of all
+// overrides matching ref. The returned config has no overrides left, so applying
+// it a second time is a no-op.
+func configForRef(config *Config, ref string) *Config {
+	refConfig := *config
+	refConfig.Overrides = nil
+	refConfig.Rules = append([]Rule{}, config.Rules...)
+
+	for _, override := range config.Overrides {
+		for _, overrideRef := range override.Refs {
+			if refMatches(overrideRef, ref) {
+				refConfig.Rules = append(refConfig.Rules, override.Rules...)
+				break
+			}
+		}
+	}
+
+	return &refConfig
+}
+
+// isProtectedRef reports whether a ref is validated in pre-push hook mode given
+// the compiled protected_refs patterns, matched against its full and short name.
+// Without patterns, all refs are protected.
+func isProtectedRef(ref string, patterns []*regexp.Regexp) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	short := plumbing.ReferenceName(ref).Short()
+	for _, re := range patterns {
+		if re.MatchString(ref) || re.MatchString(short) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// refMatches reports whether two ref names denote the same ref. Full ref names
+// (refs/heads/main) and short names (main) are considered equal.
+func refMatches(a string, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+
+	return plumbing.ReferenceName(a).Short() == plumbing.ReferenceName(b).Short()
+}
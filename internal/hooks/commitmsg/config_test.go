@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"testing"
 
 	"github.com/breml/githooks/internal/hooks/commitmsg"
@@ -108,11 +109,13 @@ settings:
   skip_authors:
     - 'renovate\[bot\]'
     - 'dependabot'
+  skip_subjects:
+    - '^Revert "'
 `,
 			wantErr: false,
 			validate: func(t *testing.T, config *commitmsg.Config) {
 				t.Helper()
-				if !config.Settings.FailFast {
+				if config.Settings.FailFast == nil || !*config.Settings.FailFast {
 					t.Error("expected FailFast to be true")
 				}
 
@@ -127,6 +130,35 @@ settings:
 				if len(config.Settings.SkipAuthors) != 2 {
 					t.Errorf("expected 2 skip_authors, got %d", len(config.Settings.SkipAuthors))
 				}
+
+				if len(config.Settings.SkipSubjects) != 1 {
+					t.Errorf("expected 1 skip_subjects, got %d", len(config.Settings.SkipSubjects))
+				}
+			},
+		},
+		{
+			name: "severity defaults to error",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+    pattern: 'test'
+  - name: nag
+    type: deny
+    scope: title
+    pattern: 'nag'
+    severity: warning
+`,
+			wantErr: false,
+			validate: func(t *testing.T, config *commitmsg.Config) {
+				t.Helper()
+				if config.Rules[0].Severity != commitmsg.SeverityError {
+					t.Errorf("expected default severity 'error', got %q", config.Rules[0].Severity)
+				}
+
+				if config.Rules[1].Severity != commitmsg.SeverityWarning {
+					t.Errorf("expected severity 'warning', got %q", config.Rules[1].Severity)
+				}
 			},
 		},
 	}
@@ -147,87 +179,724 @@ func TestLoadConfig_Invalid(t *testing.T) {
 		validate    func(*testing.T, *commitmsg.Config)
 	}{
 		{
-			name: "invalid YAML",
+			name: "invalid YAML",
+			configYAML: `rules:
+  - name: test
+    type: deny
+  invalid yaml here
+`,
+			wantErr:     true,
+			errContains: "failed to parse config YAML",
+		},
+		{
+			name:        "no rules defined",
+			configYAML:  `rules: []`,
+			wantErr:     true,
+			errContains: "no rules defined",
+		},
+		{
+			name: "missing rule name",
+			configYAML: `rules:
+  - type: deny
+    scope: title
+    pattern: 'test'
+`,
+			wantErr:     true,
+			errContains: "name is required",
+		},
+		{
+			name: "invalid rule type",
+			configYAML: `rules:
+  - name: test
+    type: invalid
+    scope: title
+    pattern: 'test'
+`,
+			wantErr:     true,
+			errContains: "type must be 'deny' or 'require'",
+		},
+		{
+			name: "invalid scope",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: invalid
+    pattern: 'test'
+`,
+			wantErr:     true,
+			errContains: "scope must be 'title', 'body', 'footer', or 'message'",
+		},
+		{
+			name: "missing pattern",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+`,
+			wantErr:     true,
+			errContains: "pattern is required",
+		},
+		{
+			name: "invalid regex pattern",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+    pattern: '(?i[invalid'
+`,
+			wantErr:     true,
+			errContains: "invalid regex pattern",
+		},
+		{
+			name: "invalid skip_authors pattern",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+    pattern: 'test'
+settings:
+  skip_authors:
+    - '[invalid'
+`,
+			wantErr:     true,
+			errContains: "skip_authors",
+		},
+		{
+			name: "invalid skip_committers pattern",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+    pattern: 'test'
+settings:
+  skip_committers:
+    - '[invalid'
+`,
+			wantErr:     true,
+			errContains: "skip_committers[0]",
+		},
+		{
+			name: "invalid skip_subjects pattern",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+    pattern: 'test'
+settings:
+  skip_subjects:
+    - '[invalid'
+`,
+			wantErr:     true,
+			errContains: "skip_subjects",
+		},
+		{
+			name: "invalid protected_refs pattern",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+    pattern: 'test'
+settings:
+  protected_refs:
+    - '[invalid'
+`,
+			wantErr:     true,
+			errContains: "protected_refs",
+		},
+		{
+			name: "invalid severity",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+    pattern: 'test'
+    severity: fatal
+`,
+			wantErr:     true,
+			errContains: "severity must be 'error' or 'warning'",
+		},
+		{
+			name: "override without refs",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+    pattern: 'test'
+overrides:
+  - rules:
+      - name: strict
+        type: deny
+        scope: title
+        pattern: 'wip'
+`,
+			wantErr:     true,
+			errContains: "overrides[0]: at least one ref is required",
+		},
+		{
+			name: "override with invalid rule",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+    pattern: 'test'
+overrides:
+  - refs: [main]
+    rules:
+      - name: strict
+        type: deny
+        scope: title
+        pattern: '[invalid'
+`,
+			wantErr:     true,
+			errContains: "overrides[0]: rule \"strict\": invalid regex pattern",
+		},
+		{
+			name: "author_email_allowlist without allowlist_file",
+			configYAML: `rules:
+  - name: allowed-authors
+    type: author_email_allowlist
+`,
+			wantErr:     true,
+			errContains: "allowlist_file is required",
+		},
+		{
+			name: "author_email_allowlist with missing allowlist_file",
+			configYAML: `rules:
+  - name: allowed-authors
+    type: author_email_allowlist
+    allowlist_file: missing.txt
+`,
+			wantErr:     true,
+			errContains: "failed to read allowlist file",
+		},
+		{
+			name: "branch_ticket_pattern on deny rule",
+			configYAML: `rules:
+  - name: no-ticket
+    type: deny
+    scope: title
+    pattern: 'PROJ-\d+'
+    branch_ticket_pattern: '^PROJ-\d+'
+`,
+			wantErr:     true,
+			errContains: "branch_ticket_pattern is only supported for require rules",
+		},
+		{
+			name: "invalid branch_ticket_pattern",
+			configYAML: `rules:
+  - name: require-ticket
+    type: require
+    scope: message
+    pattern: 'PROJ-\d+'
+    branch_ticket_pattern: '[invalid'
+`,
+			wantErr:     true,
+			errContains: "invalid branch_ticket_pattern",
+		},
+		{
+			name: "applies_to with unknown type",
+			configYAML: `rules:
+  - name: require-feat-scope
+    type: require
+    scope: cc_scope
+    pattern: '.+'
+    applies_to: [feature]
+`,
+			wantErr:     true,
+			errContains: `applies_to must only contain Conventional Commits types`,
+		},
+		{
+			name: "no_repeated_words with unsupported scope",
+			configYAML: `rules:
+  - name: no-repeated-words
+    type: no_repeated_words
+    scope: footer
+`,
+			wantErr:     true,
+			errContains: "scope must be 'title', 'body', or 'message'",
+		},
+		{
+			name: "revert_requires_approval without protected_branches",
+			configYAML: `rules:
+  - name: revert-approval
+    type: revert_requires_approval
+`,
+			wantErr:     true,
+			errContains: "protected_branches is required",
+		},
+		{
+			name: "max_trailer_repeats without limit",
+			configYAML: `rules:
+  - name: max-trailers
+    type: max_trailer_repeats
+`,
+			wantErr:     true,
+			errContains: "limit must be greater than 0",
+		},
+		{
+			name: "template_match without template",
+			configYAML: `rules:
+  - name: pr-template
+    type: template_match
+`,
+			wantErr:     true,
+			errContains: "template is required",
+		},
+		{
+			name: "template_match with hole before header",
+			configYAML: `rules:
+  - name: pr-template
+    type: template_match
+    template: "{{summary}}"
+`,
+			wantErr:     true,
+			errContains: `hole "summary" must follow a header line`,
+		},
+		{
+			name: "trailer scope without trailer_key",
+			configYAML: `rules:
+  - name: require-signoff
+    type: require
+    scope: trailer
+    pattern: '.+'
+`,
+			wantErr:     true,
+			errContains: "trailer_key is required for scope 'trailer'",
+		},
+		{
+			name: "trailer_key with other scope",
+			configYAML: `rules:
+  - name: require-signoff
+    type: require
+    scope: footer
+    trailer_key: Signed-off-by
+    pattern: '.+'
+`,
+			wantErr:     true,
+			errContains: "trailer_key is only supported for scope 'trailer'",
+		},
+		{
+			name: "no_question_subject with invalid pattern",
+			configYAML: `rules:
+  - name: no-questions
+    type: no_question_subject
+    pattern: '[invalid'
+`,
+			wantErr:     true,
+			errContains: "invalid regex pattern",
+		},
+		{
+			name: "subject_not_branch_name with invalid strictness",
+			configYAML: `rules:
+  - name: subject-not-branch
+    type: subject_not_branch_name
+    strictness: fuzzy
+`,
+			wantErr:     true,
+			errContains: "strictness must be 'exact' or 'contains'",
+		},
+		{
+			name: "invalid exception pattern",
+			configYAML: `rules:
+  - name: no-all-caps
+    type: deny
+    scope: title
+    pattern: '^[^a-z]+$'
+    exceptions: ['[invalid']
+`,
+			wantErr:     true,
+			errContains: "exceptions[0]: invalid regex pattern",
+		},
+		{
+			name: "exceptions on require rule",
+			configYAML: `rules:
+  - name: require-ticket
+    type: require
+    scope: title
+    pattern: 'PROJ-\d+'
+    exceptions: ['^chore']
+`,
+			wantErr:     true,
+			errContains: "exceptions are only supported for deny rules",
+		},
+		{
+			name: "min_length without limit",
+			configYAML: `rules:
+  - name: title-min-length
+    type: min_length
+`,
+			wantErr:     true,
+			errContains: "limit must be greater than 0",
+		},
+		{
+			name: "min_length with body_line scope",
+			configYAML: `rules:
+  - name: title-min-length
+    type: min_length
+    scope: body_line
+    limit: 10
+`,
+			wantErr:     true,
+			errContains: "scope must be",
+		},
+		{
+			name: "require_body_when without title_pattern",
+			configYAML: `rules:
+  - name: test
+    type: require_body_when
+`,
+			wantErr:     true,
+			errContains: "title_pattern is required",
+		},
+		{
+			name: "require_body_when with invalid title_pattern",
+			configYAML: `rules:
+  - name: test
+    type: require_body_when
+    title_pattern: '[invalid'
+`,
+			wantErr:     true,
+			errContains: "invalid title_pattern",
+		},
+		{
+			name: "imperative_subject with invalid allow word",
+			configYAML: `rules:
+  - name: test
+    type: imperative_subject
+    allow: ['fix typo']
+`,
+			wantErr:     true,
+			errContains: "must be a single word",
+		},
+		{
+			name: "unknown preset in extends",
+			configYAML: `extends: [conventional-commits, gitmoji]
+rules: []
+`,
+			wantErr:     true,
+			errContains: `extends[1]: unknown preset "gitmoji"`,
+		},
+		{
+			name: "duplicate rule name",
+			configYAML: `rules:
+  - name: prevent-wip
+    type: deny
+    scope: title
+    pattern: '(?i)wip'
+  - name: prevent-wip
+    type: deny
+    scope: body
+    pattern: '(?i)wip'
+`,
+			wantErr:     true,
+			errContains: `rule "prevent-wip": duplicate rule name`,
+		},
+		{
+			name: "override rule name duplicates top-level rule",
+			configYAML: `rules:
+  - name: prevent-wip
+    type: deny
+    scope: title
+    pattern: '(?i)wip'
+overrides:
+  - refs: [main]
+    rules:
+      - name: prevent-wip
+        type: deny
+        scope: message
+        pattern: '(?i)wip'
+`,
+			wantErr:     true,
+			errContains: `overrides[0]: rule "prevent-wip": duplicate rule name`,
+		},
+		{
+			name: "no_trailing_punctuation with letters in punctuation",
+			configYAML: `rules:
+  - name: test
+    type: no_trailing_punctuation
+    punctuation: '.s'
+`,
+			wantErr:     true,
+			errContains: "punctuation must only contain punctuation characters",
+		},
+		{
+			name: "negative max_commits",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+    pattern: 'test'
+settings:
+  max_commits: -1
+`,
+			wantErr:     true,
+			errContains: "max_commits must not be negative",
+		},
+		{
+			name: "negative report_max_lines",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+    pattern: 'test'
+settings:
+  report_full_message: true
+  report_max_lines: -1
+`,
+			wantErr:     true,
+			errContains: "report_max_lines must not be negative",
+		},
+		{
+			name: "require_issue_ref with body scope",
+			configYAML: `rules:
+  - name: test
+    type: require_issue_ref
+    scope: body
+`,
+			wantErr:     true,
+			errContains: "scope must be 'title' or 'footer'",
+		},
+		{
+			name: "require_issue_ref with invalid prefix_pattern",
+			configYAML: `rules:
+  - name: test
+    type: require_issue_ref
+    prefix_pattern: '[A-Z+-\d+'
+`,
+			wantErr:     true,
+			errContains: "invalid prefix_pattern",
+		},
+		{
+			name: "require_issue_ref with prefix_pattern matching empty text",
+			configYAML: `rules:
+  - name: test
+    type: require_issue_ref
+    prefix_pattern: '\d*'
+`,
+			wantErr:     true,
+			errContains: "prefix_pattern must not match empty text",
+		},
+		{
+			name: "charset without allow",
+			configYAML: `rules:
+  - name: test
+    type: charset
+`,
+			wantErr:     true,
+			errContains: "allow is required",
+		},
+		{
+			name: "charset with invalid allow pattern",
+			configYAML: `rules:
+  - name: test
+    type: charset
+    allow: ['[ -~']
+`,
+			wantErr:     true,
+			errContains: "allow[0]: invalid regex pattern",
+		},
+		{
+			name: "charset with cc_type scope",
+			configYAML: `rules:
+  - name: test
+    type: charset
+    scope: cc_type
+    allow: ['[a-z]']
+`,
+			wantErr:     true,
+			errContains: "scope must be 'title', 'body', 'footer', or 'message'",
+		},
+		{
+			name: "body_wrap without limit",
+			configYAML: `rules:
+  - name: test
+    type: body_wrap
+`,
+			wantErr:     true,
+			errContains: "limit must be greater than 0",
+		},
+		{
+			name: "body_wrap with invalid ignore_pattern",
+			configYAML: `rules:
+  - name: test
+    type: body_wrap
+    limit: 72
+    ignore_pattern: '[invalid'
+`,
+			wantErr:     true,
+			errContains: "invalid ignore_pattern",
+		},
+		{
+			name: "pattern and patterns",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+    pattern: 'wip'
+    patterns: ['todo']
+`,
+			wantErr:     true,
+			errContains: "pattern and patterns can not be combined",
+		},
+		{
+			name: "invalid regex in patterns",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+    patterns: ['wip', '[invalid']
+`,
+			wantErr:     true,
+			errContains: "patterns[1]: invalid regex pattern",
+		},
+		{
+			name: "invalid match mode",
+			configYAML: `rules:
+  - name: test
+    type: deny
+    scope: title
+    patterns: ['wip', 'todo']
+    match: some
+`,
+			wantErr:     true,
+			errContains: "match must be 'any' or 'all'",
+		},
+		{
+			name: "blank_line_after_title with pattern",
+			configYAML: `rules:
+  - name: test
+    type: blank_line_after_title
+    pattern: '^$'
+`,
+			wantErr:     true,
+			errContains: "pattern is not supported by blank_line_after_title rules",
+		},
+		{
+			name: "dco with invalid signer",
+			configYAML: `rules:
+  - name: test
+    type: dco
+    signer: reviewer
+`,
+			wantErr:     true,
+			errContains: "signer must be 'author' or 'committer'",
+		},
+		{
+			name: "max_words without limit",
+			configYAML: `rules:
+  - name: title-max-words
+    type: max_words
+`,
+			wantErr:     true,
+			errContains: "limit must be greater than 0",
+		},
+		{
+			name: "min_words with negative limit",
 			configYAML: `rules:
-  - name: test
-    type: deny
-  invalid yaml here
+  - name: body-min-words
+    type: min_words
+    scope: body
+    limit: -1
 `,
 			wantErr:     true,
-			errContains: "failed to parse config YAML",
+			errContains: "limit must be greater than 0",
 		},
 		{
-			name:        "no rules defined",
-			configYAML:  `rules: []`,
+			name: "max_words with body_line scope",
+			configYAML: `rules:
+  - name: title-max-words
+    type: max_words
+    scope: body_line
+    limit: 10
+`,
 			wantErr:     true,
-			errContains: "no rules defined",
+			errContains: "scope must be",
 		},
 		{
-			name: "missing rule name",
+			name: "require_trailer without trailer_key",
 			configYAML: `rules:
-  - type: deny
-    scope: title
-    pattern: 'test'
+  - name: pair-programming
+    type: require_trailer
 `,
 			wantErr:     true,
-			errContains: "name is required",
+			errContains: "trailer_key is required",
 		},
 		{
-			name: "invalid rule type",
+			name: "require_trailer with pattern",
 			configYAML: `rules:
-  - name: test
-    type: invalid
-    scope: title
-    pattern: 'test'
+  - name: pair-programming
+    type: require_trailer
+    trailer_key: Co-authored-by
+    pattern: '.+'
 `,
 			wantErr:     true,
-			errContains: "type must be 'deny' or 'require'",
+			errContains: "use value_pattern",
 		},
 		{
-			name: "invalid scope",
+			name: "require_trailer with invalid value_pattern",
 			configYAML: `rules:
-  - name: test
-    type: deny
-    scope: invalid
-    pattern: 'test'
+  - name: pair-programming
+    type: require_trailer
+    trailer_key: Co-authored-by
+    value_pattern: '[invalid'
 `,
 			wantErr:     true,
-			errContains: "scope must be 'title', 'body', 'footer', or 'message'",
+			errContains: "invalid value_pattern",
 		},
 		{
-			name: "missing pattern",
+			name: "max_body_lines without limit",
 			configYAML: `rules:
-  - name: test
-    type: deny
-    scope: title
+  - name: body-max-lines
+    type: max_body_lines
 `,
 			wantErr:     true,
-			errContains: "pattern is required",
+			errContains: "limit must be greater than 0",
 		},
 		{
-			name: "invalid regex pattern",
+			name: "no_trailing_whitespace with pattern",
 			configYAML: `rules:
-  - name: test
+  - name: no-trailing-whitespace
+    type: no_trailing_whitespace
+    pattern: '\s$'
+`,
+			wantErr:     true,
+			errContains: "pattern is not supported by no_trailing_whitespace rules",
+		},
+		{
+			name: "raw_pattern on built-in rule type",
+			configYAML: `rules:
+  - name: question
+    type: no_question_subject
+    raw_pattern: true
+`,
+			wantErr:     true,
+			errContains: "raw_pattern is only supported for deny and require rules",
+		},
+		{
+			name: "invalid pattern_prefix",
+			configYAML: `settings:
+  pattern_prefix: '(['
+rules:
+  - name: prevent-wip
     type: deny
     scope: title
-    pattern: '(?i[invalid'
+    pattern: 'WIP'
 `,
 			wantErr:     true,
 			errContains: "invalid regex pattern",
 		},
 		{
-			name: "invalid skip_authors pattern",
-			configYAML: `rules:
-  - name: test
-    type: deny
+			name: "anchored pattern with pattern_prefix",
+			configYAML: `settings:
+  pattern_prefix: '^(?:\[skip ci\] )?'
+rules:
+  - name: cc-title
+    type: require
     scope: title
-    pattern: 'test'
-settings:
-  skip_authors:
-    - '[invalid'
+    pattern: '^feat'
 `,
 			wantErr:     true,
-			errContains: "skip_authors",
+			errContains: "is anchored and can not match after pattern_prefix",
 		},
 	}
 
@@ -285,6 +954,8 @@ func runLoadConfigTest(t *testing.T, tt struct {
 }
 
 func TestLoadConfig_MissingFile(t *testing.T) {
+	setUserConfigDir(t)
+
 	tmpDir := t.TempDir()
 
 	_, err := commitmsg.LoadConfig(tmpDir)
@@ -297,6 +968,314 @@ func TestLoadConfig_MissingFile(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_UserConfigFallback(t *testing.T) {
+	userConfigDir := setUserConfigDir(t)
+
+	userConfig := filepath.Join(userConfigDir, "commit-msg-lint", "config.yml")
+
+	err := os.MkdirAll(filepath.Dir(userConfig), 0o755)
+	if err != nil {
+		t.Fatalf("failed to create user config dir: %v", err)
+	}
+
+	err = os.WriteFile(userConfig, []byte("rules:\n  - name: user-rule\n    type: deny\n    scope: title\n    pattern: wip\n"), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write user config: %v", err)
+	}
+
+	// Without a repository config, the user config is loaded
+	tmpDir := t.TempDir()
+
+	config, err := commitmsg.LoadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(config.Rules) != 1 || config.Rules[0].Name != "user-rule" {
+		t.Errorf("rules = %v, want the rule of the user config", config.Rules)
+	}
+
+	// The repository config takes precedence
+	err = os.WriteFile(filepath.Join(tmpDir, commitmsg.DefaultConfigFile), []byte(`rules:
+  - name: repo-rule
+    type: deny
+    scope: title
+    pattern: wip
+`), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write repository config: %v", err)
+	}
+
+	config, err = commitmsg.LoadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(config.Rules) != 1 || config.Rules[0].Name != "repo-rule" {
+		t.Errorf("rules = %v, want the rule of the repository config", config.Rules)
+	}
+}
+
+// setUserConfigDir points the user config directory to a new temporary directory
+// and returns it, so tests do not pick up the config of the user running them.
+func setUserConfigDir(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("AppData", filepath.Join(home, "AppData"))
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatalf("failed to get user config dir: %v", err)
+	}
+
+	return dir
+}
+
+func TestLoadConfig_Includes(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"shared/base.yml": `rules:
+  - name: prevent-wip
+    type: deny
+    scope: title
+    pattern: '(?i)wip'
+  - name: allowed-authors
+    type: author_email_allowlist
+    allowlist_file: emails.txt
+settings:
+  main_ref: trunk
+  skip_authors: ['renovate\[bot\]']
+  disabled: true
+  fail_fast: true
+  lint_tags: true
+`,
+		"shared/emails.txt": "test@example.com\n",
+		commitmsg.DefaultConfigFile: `includes: [shared/base.yml]
+rules:
+  - name: no-fixup
+    type: deny
+    scope: title
+    pattern: '^fixup!'
+settings:
+  main_ref: main
+  disabled: false
+  fail_fast: false
+`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+
+		err = os.WriteFile(path, []byte(content), 0o644)
+		if err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	config, err := commitmsg.LoadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, rule := range config.Rules {
+		names = append(names, rule.Name)
+	}
+
+	if !slices.Equal(names, []string{"prevent-wip", "allowed-authors", "no-fixup"}) {
+		t.Errorf("rules = %v, want included rules before local rules", names)
+	}
+
+	if config.Settings.MainRef != "main" {
+		t.Errorf("main_ref = %q, want local setting %q", config.Settings.MainRef, "main")
+	}
+
+	if len(config.Settings.SkipAuthors) != 1 {
+		t.Errorf("skip_authors = %v, want included setting", config.Settings.SkipAuthors)
+	}
+
+	// Local false overrides an included true
+	if config.Settings.Disabled == nil || *config.Settings.Disabled {
+		t.Error("expected disabled to be false from the local config")
+	}
+
+	if config.Settings.FailFast == nil || *config.Settings.FailFast {
+		t.Error("expected fail_fast to be false from the local config")
+	}
+
+	if config.Settings.LintTags == nil || !*config.Settings.LintTags {
+		t.Error("expected lint_tags to be true from the included config")
+	}
+}
+
+func TestLoadConfig_Extends(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	content := `extends: [conventional-commits, signed-off]
+rules:
+  - name: no-fixup
+    type: deny
+    scope: title
+    pattern: '^fixup!'
+`
+
+	err := os.WriteFile(filepath.Join(tmpDir, commitmsg.DefaultConfigFile), []byte(content), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	config, err := commitmsg.LoadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, rule := range config.Rules {
+		names = append(names, rule.Name)
+	}
+
+	if !slices.Equal(names, []string{"conventional-commits", "signed-off", "no-fixup"}) {
+		t.Errorf("rules = %v, want preset rules before local rules", names)
+	}
+
+	tests := []struct {
+		message   string
+		wantRules []string
+	}{
+		{
+			message:   "feat(api): add endpoint\n\nSigned-off-by: Test User <test@example.com>",
+			wantRules: nil,
+		},
+		{
+			message:   "Add endpoint\n\nSigned-off-by: Test User <test@example.com>",
+			wantRules: []string{"conventional-commits"},
+		},
+		{
+			message:   "fix: handle empty input",
+			wantRules: []string{"signed-off"},
+		},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, violation := range commitmsg.Lint(config, tt.message) {
+			got = append(got, violation.Rule.Name)
+		}
+
+		if !slices.Equal(got, tt.wantRules) {
+			t.Errorf("Lint(%q) violated rules = %v, want %v", tt.message, got, tt.wantRules)
+		}
+	}
+}
+
+func TestLoadConfigFromBytes(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantRules   int
+		errContains string
+	}{
+		{
+			name: "valid config with preset",
+			data: `extends: [signed-off]
+rules:
+  - name: prevent-wip
+    type: deny
+    scope: title
+    pattern: '(?i)wip'
+`,
+			wantRules:   2,
+			errContains: "",
+		},
+		{
+			name:        "invalid YAML",
+			data:        "rules: [",
+			wantRules:   0,
+			errContains: "failed to parse config YAML",
+		},
+		{
+			name: "invalid rule",
+			data: `rules:
+  - name: prevent-wip
+    type: deny
+    scope: title
+    pattern: '[invalid'
+`,
+			wantRules:   0,
+			errContains: "invalid config",
+		},
+		{
+			name: "includes are not supported",
+			data: `includes: [shared.yml]
+rules:
+  - name: prevent-wip
+    type: deny
+    scope: title
+    pattern: '(?i)wip'
+`,
+			wantRules:   0,
+			errContains: "includes are not supported",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			config, err := commitmsg.LoadConfigFromBytes([]byte(testCase.data))
+			if testCase.errContains != "" {
+				if err == nil || !contains(err.Error(), testCase.errContains) {
+					t.Fatalf("LoadConfigFromBytes() error = %v, want it to contain %q", err, testCase.errContains)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("LoadConfigFromBytes() returned unexpected error: %v", err)
+			}
+
+			if len(config.Rules) != testCase.wantRules {
+				t.Errorf("LoadConfigFromBytes() returned %d rules, want %d", len(config.Rules), testCase.wantRules)
+			}
+
+			// The patterns are compiled like for config files
+			violations := commitmsg.EvaluateRules(config.Rules, commitmsg.ParseCommitMessage("WIP: debugging"))
+			if len(violations) == 0 {
+				t.Error("EvaluateRules() returned no violations, want the prevent-wip rule violated")
+			}
+		})
+	}
+}
+
+func TestLoadConfig_IncludeCycle(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		commitmsg.DefaultConfigFile: "includes: [base.yml]\nrules: []\n",
+		"base.yml":                  "includes: [" + commitmsg.DefaultConfigFile + "]\nrules: []\n",
+	}
+
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0o644)
+		if err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	_, err := commitmsg.LoadConfig(tmpDir)
+	if err == nil || !contains(err.Error(), "include cycle") {
+		t.Errorf("LoadConfig() error = %v, want include cycle error", err)
+	}
+}
+
 func TestValidateConfig_RegexCaching(t *testing.T) {
 	// Test that LoadConfig compiles regex patterns
 	// (regex field is unexported, so we test indirectly via LoadConfig)
@@ -427,6 +1406,85 @@ func TestValidateConfig_UnicodePattern(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_ExpandEnv(t *testing.T) {
+	tests := []struct {
+		name          string
+		settings      string
+		setEnv        bool
+		message       string
+		wantViolation bool
+		errContains   string
+	}{
+		{
+			name:          "reference is expanded",
+			settings:      "settings:\n  expand_env: true\n",
+			setEnv:        true,
+			message:       "feat: add form\n\nRefs: WEB-42",
+			wantViolation: false,
+		},
+		{
+			name:          "expanded pattern is required",
+			settings:      "settings:\n  expand_env: true\n",
+			setEnv:        true,
+			message:       "feat: add form\n\nRefs: API-42",
+			wantViolation: true,
+		},
+		{
+			name:        "unset variable",
+			settings:    "settings:\n  expand_env: true\n",
+			setEnv:      false,
+			errContains: `rule "team-ticket": pattern: environment variable TEAM_PREFIX is not set`,
+		},
+		{
+			name:          "reference is literal without expand_env",
+			settings:      "",
+			setEnv:        true,
+			message:       "feat: add form\n\nRefs: WEB-42",
+			wantViolation: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setEnv {
+				t.Setenv("TEAM_PREFIX", "WEB")
+			}
+
+			tmpDir := t.TempDir()
+
+			configYAML := `rules:
+  - name: team-ticket
+    type: require
+    scope: footer
+    pattern: '${TEAM_PREFIX}-\d+$'
+` + tt.settings
+
+			err := os.WriteFile(filepath.Join(tmpDir, commitmsg.DefaultConfigFile), []byte(configYAML), 0o644)
+			if err != nil {
+				t.Fatalf("failed to write config file: %v", err)
+			}
+
+			config, err := commitmsg.LoadConfig(tmpDir)
+			if tt.errContains != "" {
+				if err == nil || !contains(err.Error(), tt.errContains) {
+					t.Fatalf("LoadConfig() error = %v, want it to contain %q", err, tt.errContains)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			violations := commitmsg.Lint(config, tt.message)
+			if (len(violations) > 0) != tt.wantViolation {
+				t.Errorf("Lint(%q) violations = %v, want violation %v", tt.message, violations, tt.wantViolation)
+			}
+		})
+	}
+}
+
 func contains(s string, substr string) bool {
 	return regexp.MustCompile(regexp.QuoteMeta(substr)).MatchString(s)
 }
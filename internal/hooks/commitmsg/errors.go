@@ -1,71 +1,396 @@
 package commitmsg
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
+const (
+	// defaultReportMaxLines caps the reported commit message lines if
+	// report_full_message is set without report_max_lines.
+	defaultReportMaxLines = 20
+
+	// reportIndent indents the lines of a fully reported commit message.
+	reportIndent = "    "
+)
+
+// ViolationError is returned by Run when commit messages violate error-level
+// rules.
+type ViolationError struct {
+	msg string
+}
+
+// Error returns the violation report.
+func (e *ViolationError) Error() string {
+	return e.msg
+}
+
+// violationErrorf creates a ViolationError with a formatted message.
+func violationErrorf(format string, args ...any) error {
+	return &ViolationError{msg: fmt.Sprintf(format, args...)}
+}
+
+// ConfigError is returned by Run when commit messages can not be validated at
+// all, e.g. because of invalid arguments, an invalid configuration, or a missing
+// git repository.
+type ConfigError struct {
+	Err error
+}
+
+// Error returns the message of the underlying error.
+func (e *ConfigError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// OutputError is returned by Run when the report can not be written to the file
+// given with --output.
+type OutputError struct {
+	Path string
+	Err  error
+}
+
+// Error returns the path of the report file and the underlying error.
+func (e *OutputError) Error() string {
+	return fmt.Sprintf("failed to write report to %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *OutputError) Unwrap() error {
+	return e.Err
+}
+
+// joinViolationErrors combines the violation errors of several refs into a
+// single ViolationError listing all of them. It returns nil if errs is empty.
+func joinViolationErrors(errs []*ViolationError) error {
+	switch len(errs) {
+	case 0:
+		return nil
+
+	case 1:
+		return errs[0]
+
+	default:
+		msgs := make([]string, 0, len(errs))
+		for _, err := range errs {
+			msgs = append(msgs, strings.TrimRight(err.msg, "\n"))
+		}
+
+		return violationErrorf("%s", strings.Join(msgs, "\n\n"))
+	}
+}
+
+// classifyError wraps every error that is not a ViolationError in a ConfigError.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var violationErr *ViolationError
+	if errors.As(err, &violationErr) {
+		return err
+	}
+
+	return &ConfigError{Err: err}
+}
+
 // formatViolationError creates a detailed error message for rule violations.
-func formatViolationError(commit *object.Commit, ref string, violations []RuleViolation) error {
+// The commit message is reported like formatCommitReport does.
+func formatViolationError(
+	p palette,
+	commit *object.Commit,
+	ref string,
+	violations []RuleViolation,
+	messageLines int,
+) error {
+	return violationErrorf("%s", formatCommitReport(p, commit, ref, violations, messageLines))
+}
+
+// commitViolations holds the violations found in a single commit.
+type commitViolations struct {
+	commit     *object.Commit
+	violations []RuleViolation
+}
+
+// formatCommitsViolationError creates a detailed error message for the rule
+// violations of all failed commits in ref. A single failed commit is reported like
+// formatViolationError does.
+func formatCommitsViolationError(p palette, ref string, failed []commitViolations, messageLines int) error {
+	if len(failed) == 1 {
+		return formatViolationError(p, failed[0].commit, ref, failed[0].violations, messageLines)
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("%d commits in %s %s validation:\n", len(failed), ref, p.severity(SeverityError, "failed")))
+
+	for _, f := range failed {
+		sb.WriteString("\n")
+		sb.WriteString(formatCommitReport(p, f.commit, ref, f.violations, messageLines))
+	}
+
+	return violationErrorf("%s", sb.String())
+}
+
+// formatCommitReport creates a detailed report for the rule violations of a commit.
+// Error-level violations and warnings are listed in separate groups. The commit
+// message is reported with up to messageLines lines, or only its first line if
+// messageLines is zero.
+func formatCommitReport(
+	p palette,
+	commit *object.Commit,
+	ref string,
+	violations []RuleViolation,
+	messageLines int,
+) string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("Commit %s in %s failed validation:\n", commit.Hash.String()[:7], ref))
-	sb.WriteString(fmt.Sprintf("Commit message: %s\n\n", getFirstLine(commit.Message)))
+	hash := p.report(violations, commit.Hash.String()[:7])
+	if hasErrors(violations) {
+		sb.WriteString(fmt.Sprintf("Commit %s in %s %s validation:\n", hash, ref, p.severity(SeverityError, "failed")))
+	} else {
+		sb.WriteString(fmt.Sprintf("Commit %s in %s has %s:\n", hash, ref, p.severity(SeverityWarning, "warnings")))
+	}
+
+	writeCommitMessage(&sb, commit.Message, messageLines)
+	writeViolations(p, &sb, violations, commit.Hash.String())
+
+	return sb.String()
+}
+
+// reportedMessageLines returns the maximum number of commit message lines to
+// report according to the report_full_message and report_max_lines settings, or
+// zero if only the first line is reported.
+func reportedMessageLines(settings Settings) int {
+	if !isEnabled(settings.ReportFullMessage) {
+		return 0
+	}
+
+	return cmp.Or(settings.ReportMaxLines, defaultReportMaxLines)
+}
+
+// writeCommitMessage writes the commit message to the report: only its first line
+// if maxLines is zero, otherwise up to maxLines lines, indented.
+func writeCommitMessage(sb *strings.Builder, message string, maxLines int) {
+	if maxLines == 0 {
+		sb.WriteString(fmt.Sprintf("Commit message: %s\n\n", getFirstLine(message)))
+		return
+	}
+
+	sb.WriteString("Commit message:\n")
+
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+	for i, line := range lines {
+		if i == maxLines {
+			sb.WriteString(fmt.Sprintf("    ... (%d more lines)\n", len(lines)-maxLines))
+			break
+		}
+
+		sb.WriteString(strings.TrimRight(reportIndent+line, " \t") + "\n")
+	}
+
+	sb.WriteString("\n")
+}
+
+// writeViolations writes the numbered list of violations of the commit with the
+// given hash (empty for a commit message file), grouped by severity.
+func writeViolations(p palette, sb *strings.Builder, violations []RuleViolation, commitHash string) {
+	var errs, warnings []RuleViolation
+	for _, v := range violations {
+		if v.Severity == SeverityError {
+			errs = append(errs, v)
+		} else {
+			warnings = append(warnings, v)
+		}
+	}
+
+	if len(errs) > 0 {
+		sb.WriteString("Rule violations:\n")
+		writeViolationList(p, sb, errs, commitHash)
+	}
+
+	if len(warnings) > 0 {
+		if len(errs) > 0 {
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString("Warnings:\n")
+		writeViolationList(p, sb, warnings, commitHash)
+	}
+}
 
-	sb.WriteString("Rule violations:\n")
+// writeViolationList writes a numbered list of violations with their rule details.
+func writeViolationList(p palette, sb *strings.Builder, violations []RuleViolation, commitHash string) {
 	for i, v := range violations {
-		sb.WriteString(fmt.Sprintf("  %d. [%s] %s\n", i+1, v.Rule.Name, getViolationMessage(v)))
+		sb.WriteString(fmt.Sprintf("  %d. [%s] %s\n",
+			i+1, p.severity(v.Severity, v.Rule.Name), getViolationMessage(v, commitHash)))
+
+		if v.Detail != "" {
+			sb.WriteString(fmt.Sprintf("     %s\n", v.Detail))
+			continue
+		}
 
 		if v.Rule.Type == RuleTypeDeny {
-			sb.WriteString(fmt.Sprintf("     Pattern %q was found in %s (deny rule)\n", v.Rule.Pattern, v.Rule.Scope))
+			sb.WriteString(fmt.Sprintf("     Pattern %q was found in %s at offset %d: %q (deny rule)\n",
+				cmp.Or(v.MatchedPattern, v.Rule.Pattern), v.Rule.Scope, v.MatchOffset, v.MatchedText))
 		} else {
-			sb.WriteString(
-				fmt.Sprintf("     Pattern %q was not found in %s (require rule)\n", v.Rule.Pattern, v.Rule.Scope),
-			)
+			sb.WriteString(fmt.Sprintf("     Pattern %q was not found in %s (require rule)\n",
+				cmp.Or(v.MatchedPattern, v.Rule.Pattern), v.Rule.Scope))
 		}
 	}
-
-	return fmt.Errorf("%s", sb.String())
 }
 
 // getViolationMessage returns a custom message or generates a default based on rule type.
-func getViolationMessage(v RuleViolation) string {
+// The placeholders of a custom message are expanded, see expandMessage.
+func getViolationMessage(v RuleViolation, commitHash string) string {
 	if v.Rule.Message != "" {
-		return v.Rule.Message
+		return expandMessage(v.Rule.Message, v, commitHash)
 	}
 
 	// Default message based on rule type
-	if v.Rule.Type == RuleTypeDeny {
+	switch v.Rule.Type {
+	case RuleTypeDeny:
 		return fmt.Sprintf("Pattern must not match in %s", v.Rule.Scope)
+
+	case RuleTypeRequire:
+		return fmt.Sprintf("Pattern must match in %s", v.Rule.Scope)
+
+	case RuleTypeFixReferencesCause:
+		return "Fix commits must reference the commit that introduced the bug"
+
+	case RuleTypeAuthorEmailAllowlist:
+		return "Commit author email must be allowlisted"
+
+	case RuleTypeNoRepeatedWords:
+		return fmt.Sprintf("Words must not be repeated in %s", v.Rule.Scope)
+
+	case RuleTypeRevertRequiresApproval:
+		return "Reverts on protected branches must be approved"
+
+	case RuleTypeRequireTrailer:
+		return fmt.Sprintf("Commit message must have a %s trailer", v.Rule.TrailerKey)
+
+	case RuleTypeNoQuestionSubject:
+		return "Commit title must not be a question"
+
+	case RuleTypeSubjectNotBranchName:
+		return "Commit title must not restate the branch name"
+
+	case RuleTypeTemplateMatch:
+		return "Commit message must follow the template"
+
+	case RuleTypeMinLength:
+		return fmt.Sprintf("Commit %s must be at least %d characters long", v.Rule.Scope, v.Rule.Limit)
+
+	case RuleTypeMaxWords:
+		return fmt.Sprintf("Commit %s must have at most %d words", v.Rule.Scope, v.Rule.Limit)
+
+	case RuleTypeMinWords:
+		return fmt.Sprintf("Commit %s must have at least %d words", v.Rule.Scope, v.Rule.Limit)
+
+	case RuleTypeMaxBodyLines:
+		return fmt.Sprintf("Commit body must have at most %d lines", v.Rule.Limit)
+
+	case RuleTypeMaxTrailerRepeats:
+		return fmt.Sprintf("Trailers must not be repeated more than %d times", v.Rule.Limit)
+
+	case RuleTypeRequireBodyWhen:
+		return "Commit message must have a body"
+
+	case RuleTypeImperativeSubject:
+		return "Commit title must be in imperative mood"
+
+	case RuleTypeNoTrailingPunctuation:
+		return "Commit title must not end with punctuation"
+
+	case RuleTypeRequireIssueRef:
+		return fmt.Sprintf("Commit %s must reference an issue", v.Rule.Scope)
+
+	case RuleTypeCharset:
+		return fmt.Sprintf("Commit %s must only contain allowed characters", v.Rule.Scope)
+
+	case RuleTypeBodyWrap:
+		return fmt.Sprintf("Commit body must be wrapped at %d characters", v.Rule.Limit)
+
+	case RuleTypeDCO:
+		return fmt.Sprintf("Commit must be signed off by its %s", v.Rule.Signer)
+
+	case RuleTypeCCLowercase:
+		return "Conventional Commits type must be lowercase"
+
+	case RuleTypeNoMergeCommits:
+		return "Merge commits are not allowed, rebase instead"
+
+	case RuleTypeBlankLineAfterTitle:
+		return "Commit title must be followed by a blank line"
+
+	case RuleTypeNoTrailingWhitespace:
+		if v.Rule.ForbidLeadingWhitespace {
+			return "Commit message lines must not start or end with whitespace"
+		}
+
+		return "Commit message lines must not end with whitespace"
+
+	default:
+		return "Rule violated"
+	}
+}
+
+// expandMessage replaces the placeholders of a custom rule message with the
+// details of the violation: {scope}, {pattern} (the violated pattern), {match}
+// (the text matched by a deny rule), and {hash} (the short commit hash, empty for
+// a commit message file). Unknown placeholders are left as they are.
+func expandMessage(message string, v RuleViolation, commitHash string) string {
+	if !strings.Contains(message, "{") {
+		return message
 	}
 
-	return fmt.Sprintf("Pattern must match in %s", v.Rule.Scope)
+	shortHash := commitHash
+	if commitHash != "" {
+		shortHash = commitHash[:7]
+	}
+
+	return strings.NewReplacer(
+		"{scope}", string(v.Rule.Scope),
+		"{pattern}", cmp.Or(v.MatchedPattern, v.Rule.Pattern),
+		"{match}", v.MatchedText,
+		"{hash}", shortHash,
+	).Replace(message)
 }
 
 // formatMessageViolationError creates a detailed error message for rule violations
 // found in a commit message file, without requiring a commit object.
 // Used in commit-msg hook mode where the commit has not yet been created.
-func formatMessageViolationError(msgFilePath string, violations []RuleViolation) error {
-	var sb strings.Builder
-
-	sb.WriteString(fmt.Sprintf("Commit message in %s failed validation:\n\n", msgFilePath))
+func formatMessageViolationError(p palette, msgFilePath string, violations []RuleViolation) error {
+	return violationErrorf("%s", formatMessageReport(p, msgFilePath, violations))
+}
 
-	sb.WriteString("Rule violations:\n")
-	for i, v := range violations {
-		sb.WriteString(fmt.Sprintf("  %d. [%s] %s\n", i+1, v.Rule.Name, getViolationMessage(v)))
+// formatMessageReport creates a detailed report for the rule violations found
+// in a commit message file.
+func formatMessageReport(p palette, msgFilePath string, violations []RuleViolation) string {
+	var sb strings.Builder
 
-		if v.Rule.Type == RuleTypeDeny {
-			sb.WriteString(fmt.Sprintf("     Pattern %q was found in %s (deny rule)\n", v.Rule.Pattern, v.Rule.Scope))
-		} else {
-			sb.WriteString(
-				fmt.Sprintf("     Pattern %q was not found in %s (require rule)\n", v.Rule.Pattern, v.Rule.Scope),
-			)
-		}
+	if hasErrors(violations) {
+		sb.WriteString(
+			fmt.Sprintf("Commit message in %s %s validation:\n\n", msgFilePath, p.severity(SeverityError, "failed")),
+		)
+	} else {
+		sb.WriteString(
+			fmt.Sprintf("Commit message in %s has %s:\n\n", msgFilePath, p.severity(SeverityWarning, "warnings")),
+		)
 	}
 
-	return fmt.Errorf("%s", sb.String())
+	writeViolations(p, &sb, violations, "")
+
+	return sb.String()
 }
 
 // getFirstLine extracts and returns the first line of a commit message.
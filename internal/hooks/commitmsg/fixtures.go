@@ -0,0 +1,144 @@
+package commitmsg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// testSubcommand is the name of the subcommand validating the config against the
+// sample messages of a fixture file.
+const testSubcommand = "test"
+
+const (
+	// expectPass is the expected result of a sample message passing the rules.
+	expectPass = "pass"
+	// expectFail is the expected result of a sample message violating the rules.
+	expectFail = "fail"
+)
+
+// testFixtures is the content of a fixture file of the test subcommand.
+type testFixtures struct {
+	Messages []testFixture `yaml:"messages"`
+}
+
+// testFixture is a sample commit message with the expected result of its
+// validation.
+type testFixture struct {
+	// Name identifies the message in the report, defaults to its first line.
+	Name    string `yaml:"name,omitempty"`
+	Message string `yaml:"message"`
+	// Expect is the expected result, "pass" or "fail".
+	Expect string `yaml:"expect"`
+}
+
+// isTestSubcommand reports whether args invoke the test subcommand, i.e. "test"
+// followed by optional flags and a YAML fixture file. Requiring the file
+// extension keeps pre-push hook mode working for a remote named "test", as git
+// passes the remote URL as second argument.
+func isTestSubcommand(args []string) bool {
+	const minTestArgs = 3
+
+	if len(args) < minTestArgs || args[1] != testSubcommand {
+		return false
+	}
+
+	ext := filepath.Ext(args[len(args)-1])
+
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// loadTestFixtures reads and validates the sample messages of a fixture file.
+func loadTestFixtures(path string) ([]testFixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture file: %w", err)
+	}
+
+	var fixtures testFixtures
+	err = yaml.Unmarshal(data, &fixtures)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fixture file %s: %w", path, err)
+	}
+
+	if len(fixtures.Messages) == 0 {
+		return nil, fmt.Errorf("no messages defined in fixture file %s", path)
+	}
+
+	for i, fixture := range fixtures.Messages {
+		if strings.TrimSpace(fixture.Message) == "" {
+			return nil, fmt.Errorf("%s: messages[%d]: message is required", path, i)
+		}
+
+		switch fixture.Expect {
+		case expectPass, expectFail:
+
+		default:
+			return nil, fmt.Errorf("%s: messages[%d]: expect must be 'pass' or 'fail', got %q", path, i, fixture.Expect)
+		}
+	}
+
+	return fixtures.Messages, nil
+}
+
+// runTestMode validates the sample messages of the fixture file with the rules of
+// config, like messages passed with --text. A message fails if it has a violation
+// of at least the severity failOn. Messages whose result differs from their
+// expectation are reported as a diff of the expected and the actual result.
+func runTestMode(config *Config, fixturePath string, failOn Severity, stderr io.Writer) error {
+	fixtures, err := loadTestFixtures(fixturePath)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+
+	unmet := 0
+	for i, fixture := range fixtures {
+		violations := Lint(config, fixture.Message)
+
+		actual := expectPass
+		if failsAt(violations, failOn) {
+			actual = expectFail
+		}
+
+		if actual == fixture.Expect {
+			continue
+		}
+
+		unmet++
+
+		sb.WriteString(fmt.Sprintf("\nmessages[%d] %q:\n", i, fixtureName(fixture)))
+		sb.WriteString(fmt.Sprintf("-expect: %s\n", fixture.Expect))
+		sb.WriteString(fmt.Sprintf("+actual: %s\n", actual))
+
+		for _, v := range violations {
+			sb.WriteString(fmt.Sprintf("+  [%s] %s\n", v.Rule.Name, getViolationMessage(v, "")))
+		}
+	}
+
+	_, _ = fmt.Fprintf(stderr, "Checked %d messages, %d unmet expectations\n", len(fixtures), unmet)
+
+	if unmet == 0 {
+		return nil
+	}
+
+	return violationErrorf(
+		"%d of %d messages in %s did not meet their expectation:\n%s",
+		unmet, len(fixtures), fixturePath, sb.String(),
+	)
+}
+
+// fixtureName returns the name of the fixture if set, otherwise the first line of
+// its message.
+func fixtureName(fixture testFixture) string {
+	if fixture.Name != "" {
+		return fixture.Name
+	}
+
+	return getFirstLine(fixture.Message)
+}
@@ -0,0 +1,169 @@
+package commitmsg_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/breml/githooks/internal/hooks/commitmsg"
+)
+
+func TestRunTestSubcommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, tmpDir, `rules:
+  - name: prevent-wip
+    type: deny
+    scope: title
+    pattern: '(?i)wip'
+  - name: issue-ref
+    type: require
+    scope: message
+    pattern: '#\d+'
+    severity: warning
+`)
+	t.Chdir(tmpDir)
+
+	tests := []struct {
+		name       string
+		fixtures   string
+		args       []string
+		wantErr    string
+		wantStderr string
+	}{
+		{
+			name: "all expectations met",
+			fixtures: `messages:
+  - message: "Add feature"
+    expect: pass
+  - name: wip
+    message: "WIP: debugging"
+    expect: fail
+`,
+			args:       nil,
+			wantErr:    "",
+			wantStderr: "Checked 2 messages, 0 unmet expectations",
+		},
+		{
+			name: "unmet expectations are reported as diff",
+			fixtures: `messages:
+  - message: "Add feature"
+    expect: fail
+  - name: wip
+    message: "WIP: debugging"
+    expect: pass
+`,
+			args: nil,
+			wantErr: "2 of 2 messages in fixtures.yml did not meet their expectation:\n\n" +
+				"messages[0] \"Add feature\":\n-expect: fail\n+actual: pass\n+  [issue-ref] ",
+			wantStderr: "Checked 2 messages, 2 unmet expectations",
+		},
+		{
+			name: "warnings fail with fail-on warning",
+			fixtures: `messages:
+  - message: "Add feature"
+    expect: fail
+`,
+			args:       []string{"--fail-on", "warning"},
+			wantErr:    "",
+			wantStderr: "Checked 1 messages, 0 unmet expectations",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := os.WriteFile(filepath.Join(tmpDir, "fixtures.yml"), []byte(testCase.fixtures), 0o600)
+			if err != nil {
+				t.Fatalf("failed to write fixture file: %v", err)
+			}
+
+			args := append([]string{"commit-msg-lint", "test"}, testCase.args...)
+			args = append(args, "fixtures.yml")
+
+			var stderr bytes.Buffer
+			err = commitmsg.RunWith(commitmsg.RunOptions{
+				Args:        args,
+				Stdin:       strings.NewReader(""),
+				Stdout:      io.Discard,
+				Stderr:      &stderr,
+				ErrorPrefix: "",
+			})
+
+			if !strings.Contains(stderr.String(), testCase.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), testCase.wantStderr)
+			}
+
+			if testCase.wantErr == "" {
+				if err != nil {
+					t.Fatalf("RunWith() returned unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			var violationErr *commitmsg.ViolationError
+			if !errors.As(err, &violationErr) {
+				t.Fatalf("RunWith() error = %v, want a *ViolationError", err)
+			}
+
+			if !strings.Contains(err.Error(), testCase.wantErr) {
+				t.Errorf("RunWith() error = %q, want it to contain %q", err.Error(), testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunTestSubcommandInvalidFixtures(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, tmpDir, defaultWIPConfig)
+	t.Chdir(tmpDir)
+
+	tests := []struct {
+		name        string
+		fixtures    string
+		errContains string
+	}{
+		{
+			name:        "no messages",
+			fixtures:    "messages: []\n",
+			errContains: "no messages defined",
+		},
+		{
+			name:        "invalid expectation",
+			fixtures:    "messages:\n  - message: \"Add feature\"\n    expect: maybe\n",
+			errContains: "expect must be 'pass' or 'fail'",
+		},
+		{
+			name:        "empty message",
+			fixtures:    "messages:\n  - message: \"\"\n    expect: pass\n",
+			errContains: "message is required",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := os.WriteFile(filepath.Join(tmpDir, "fixtures.yaml"), []byte(testCase.fixtures), 0o600)
+			if err != nil {
+				t.Fatalf("failed to write fixture file: %v", err)
+			}
+
+			err = commitmsg.RunWith(commitmsg.RunOptions{
+				Args:        []string{"commit-msg-lint", "test", "fixtures.yaml"},
+				Stdin:       strings.NewReader(""),
+				Stdout:      io.Discard,
+				Stderr:      io.Discard,
+				ErrorPrefix: "",
+			})
+			if err == nil {
+				t.Fatal("RunWith() expected error, got nil")
+			}
+
+			if !strings.Contains(err.Error(), testCase.errContains) {
+				t.Errorf("RunWith() error = %q, want it to contain %q", err.Error(), testCase.errContains)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package commitmsg
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// gitlintGeneralSection is the section of a gitlint config holding the general
+// options instead of rule options.
+const gitlintGeneralSection = "general"
+
+// Defaults of the supported gitlint rule options.
+const (
+	gitlintDefaultTitleMaxLength = 72
+	gitlintDefaultBodyMinLength  = 20
+	gitlintDefaultTitleWords     = "WIP"
+)
+
+// ImportGitlintConfig reads a gitlint INI config (.gitlint) and maps the supported
+// gitlint rules (title-max-length, body-min-length, title-must-not-contain-word)
+// onto a validated Config. Like in gitlint, these rules are enabled by default,
+// the options of their sections override the defaults. Rules listed in the
+// "ignore" option of the general section are skipped. The names of the ignored,
+// unsupported rules are returned as well.
+func ImportGitlintConfig(path string) (*Config, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read gitlint config: %w", err)
+	}
+
+	sections, order, err := parseINI(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse gitlint config: %w", err)
+	}
+
+	var disabled []string
+	for name := range strings.SplitSeq(sections[gitlintGeneralSection]["ignore"], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			disabled = append(disabled, gitlintRuleName(name))
+		}
+	}
+
+	config := &Config{Includes: nil, Extends: nil, Rules: nil, Overrides: nil, Settings: Settings{}}
+	var ignored []string
+	var imported []string
+
+	for _, section := range order {
+		if section == gitlintGeneralSection {
+			continue
+		}
+
+		name := gitlintRuleName(section)
+		if slices.Contains(disabled, name) {
+			continue
+		}
+
+		rule, supported, convertErr := convertGitlintRule(name, sections[section])
+		if convertErr != nil {
+			return nil, nil, fmt.Errorf("gitlint rule %q: %w", section, convertErr)
+		}
+
+		if !supported {
+			ignored = append(ignored, section)
+			continue
+		}
+
+		config.Rules = append(config.Rules, rule)
+		imported = append(imported, name)
+	}
+
+	// The supported rules without a section are enabled with their default options
+	for _, name := range []string{"title-max-length", "title-must-not-contain-word", "body-min-length"} {
+		if slices.Contains(disabled, name) || slices.Contains(imported, name) {
+			continue
+		}
+
+		rule, _, convertErr := convertGitlintRule(name, nil)
+		if convertErr != nil {
+			return nil, nil, fmt.Errorf("gitlint rule %q: %w", name, convertErr)
+		}
+
+		config.Rules = append(config.Rules, rule)
+	}
+
+	err = validateConfig(config, filepath.Dir(path))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid imported gitlint config: %w", err)
+	}
+
+	return config, ignored, nil
+}
+
+// gitlintRuleName returns the name of a gitlint rule given by name or id.
+func gitlintRuleName(nameOrID string) string {
+	switch nameOrID {
+	case "T1":
+		return "title-max-length"
+
+	case "T5":
+		return "title-must-not-contain-word"
+
+	case "B5":
+		return "body-min-length"
+
+	default:
+		return nameOrID
+	}
+}
+
+// convertGitlintRule converts a gitlint rule with its options into a rule and
+// reports whether the gitlint rule is supported.
+func convertGitlintRule(name string, options map[string]string) (Rule, bool, error) {
+	var rule Rule
+	rule.Name = "gitlint-" + name
+	rule.Severity = SeverityError
+
+	switch name {
+	case "title-max-length":
+		limit, err := gitlintIntOption(options, "line-length", gitlintDefaultTitleMaxLength)
+		if err != nil {
+			return Rule{}, false, err
+		}
+
+		rule.Type = RuleTypeDeny
+		rule.Scope = ScopeTitle
+		rule.Pattern = fmt.Sprintf(`^[^\n]{%d,}`, limit+1)
+		rule.Message = fmt.Sprintf("Title exceeds max length (%d)", limit)
+
+	case "body-min-length":
+		limit, err := gitlintIntOption(options, "min-length", gitlintDefaultBodyMinLength)
+		if err != nil {
+			return Rule{}, false, err
+		}
+
+		// Everything after the first line is the body, which may be missing
+		rule.Type = RuleTypeRequire
+		rule.Scope = ScopeMessage
+		rule.Pattern = fmt.Sprintf(`(?s)\A[^\n]*(?:\z|\n\s*\z|\n\s*\S.{%d,}\z)`, max(limit-1, 0))
+		rule.Message = fmt.Sprintf("Body message is too short (min length %d)", limit)
+
+	case "title-must-not-contain-word":
+		words := options["words"]
+		if words == "" {
+			words = gitlintDefaultTitleWords
+		}
+
+		var quoted []string
+		for word := range strings.SplitSeq(words, ",") {
+			if word = strings.TrimSpace(word); word != "" {
+				quoted = append(quoted, regexp.QuoteMeta(word))
+			}
+		}
+
+		if len(quoted) == 0 {
+			return Rule{}, false, fmt.Errorf("words must not be empty, got %q", words)
+		}
+
+		rule.Type = RuleTypeDeny
+		rule.Scope = ScopeTitle
+		rule.Pattern = `(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`
+		rule.Message = fmt.Sprintf("Title contains one of the words %q", words)
+
+	default:
+		return Rule{}, false, nil
+	}
+
+	return rule, true, nil
+}
+
+// gitlintIntOption returns the length option key, or def if unset.
+func gitlintIntOption(options map[string]string, key string, def int) (int, error) {
+	value, ok := options[key]
+	if !ok {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n <= 0 || n > maxImportedLength {
+		return 0, fmt.Errorf("%s must be a length between 1 and %d, got %q", key, maxImportedLength, value)
+	}
+
+	return n, nil
+}
+
+// parseINI parses a simple INI file into the key-value pairs per section and the
+// sections in order of appearance. Lines starting with "#" or ";" are comments.
+func parseINI(data []byte) (map[string]map[string]string, []string, error) {
+	sections := map[string]map[string]string{}
+	var order []string
+
+	current := ""
+	lineNum := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lineNum++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := sections[current]; !ok {
+				sections[current] = map[string]string{}
+				order = append(order, current)
+			}
+
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || current == "" {
+			return nil, nil, fmt.Errorf("line %d: expected a section or key=value, got %q", lineNum, line)
+		}
+
+		sections[current][strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sections, order, nil
+}
@@ -0,0 +1,170 @@
+package commitmsg_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/breml/githooks/internal/hooks/commitmsg"
+)
+
+const testGitlintConfig = `# Sample gitlint config
+[general]
+ignore=body-is-missing
+verbosity = 2
+
+[title-max-length]
+line-length=50
+
+[B5]
+min-length=10
+
+[title-must-not-contain-word]
+words=wip, fixup
+
+[body-max-line-length]
+line-length=100
+`
+
+func writeGitlintConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), ".gitlint")
+	err := os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write gitlint config: %v", err)
+	}
+
+	return path
+}
+
+func TestImportGitlintConfig(t *testing.T) {
+	config, ignored, err := commitmsg.ImportGitlintConfig(writeGitlintConfig(t, testGitlintConfig))
+	if err != nil {
+		t.Fatalf("ImportGitlintConfig() returned unexpected error: %v", err)
+	}
+
+	if !slices.Equal(ignored, []string{"body-max-line-length"}) {
+		t.Errorf("ignored = %v, want [body-max-line-length]", ignored)
+	}
+
+	want := []struct {
+		name     string
+		ruleType commitmsg.RuleType
+		scope    commitmsg.Scope
+	}{
+		{"gitlint-title-max-length", commitmsg.RuleTypeDeny, commitmsg.ScopeTitle},
+		{"gitlint-body-min-length", commitmsg.RuleTypeRequire, commitmsg.ScopeMessage},
+		{"gitlint-title-must-not-contain-word", commitmsg.RuleTypeDeny, commitmsg.ScopeTitle},
+	}
+
+	if len(config.Rules) != len(want) {
+		t.Fatalf("got %d rules, want %d", len(config.Rules), len(want))
+	}
+
+	for i, w := range want {
+		rule := config.Rules[i]
+		if rule.Name != w.name || rule.Type != w.ruleType || rule.Scope != w.scope {
+			t.Errorf("rule %d = %s/%s/%s, want %s/%s/%s", i, rule.Name, rule.Type, rule.Scope, w.name, w.ruleType, w.scope)
+		}
+	}
+
+	tests := []struct {
+		message   string
+		wantRules []string
+	}{
+		{message: "Add login form", wantRules: nil},
+		{message: "Add login form\n\nWith a remember me option.", wantRules: nil},
+		{message: "Add login form " + strings.Repeat("x", 40), wantRules: []string{"gitlint-title-max-length"}},
+		{message: "Add login form\n\nShort.", wantRules: []string{"gitlint-body-min-length"}},
+		{message: "WIP login form", wantRules: []string{"gitlint-title-must-not-contain-word"}},
+		{message: "Add wiping of sessions", wantRules: nil},
+	}
+
+	for _, testCase := range tests {
+		var got []string
+		for _, v := range commitmsg.Lint(config, testCase.message) {
+			got = append(got, v.Rule.Name)
+		}
+
+		if !slices.Equal(got, testCase.wantRules) {
+			t.Errorf("Lint(%q) violated %v, want %v", testCase.message, got, testCase.wantRules)
+		}
+	}
+}
+
+func TestImportGitlintConfig_IgnoredInGeneral(t *testing.T) {
+	config, _, err := commitmsg.ImportGitlintConfig(writeGitlintConfig(t, `[general]
+ignore=T1, body-min-length
+[title-max-length]
+line-length=10
+[title-must-not-contain-word]
+`))
+	if err != nil {
+		t.Fatalf("ImportGitlintConfig() returned unexpected error: %v", err)
+	}
+
+	if len(config.Rules) != 1 || config.Rules[0].Name != "gitlint-title-must-not-contain-word" {
+		t.Errorf("rules = %v, want only the default title-must-not-contain-word rule", config.Rules)
+	}
+}
+
+func TestImportGitlintConfig_GeneralOnly(t *testing.T) {
+	config, ignored, err := commitmsg.ImportGitlintConfig(writeGitlintConfig(t, `[general]
+verbosity = 2
+`))
+	if err != nil {
+		t.Fatalf("ImportGitlintConfig() returned unexpected error: %v", err)
+	}
+
+	if len(ignored) != 0 {
+		t.Errorf("ignored = %v, want none", ignored)
+	}
+
+	tests := []struct {
+		message   string
+		wantRules []string
+	}{
+		{message: "Add login form", wantRules: nil},
+		{message: "Add login form\n\nWith a remember me option.", wantRules: nil},
+		{message: "Add login form " + strings.Repeat("x", 60), wantRules: []string{"gitlint-title-max-length"}},
+		{message: "WIP login form", wantRules: []string{"gitlint-title-must-not-contain-word"}},
+		{message: "Add login form\n\nShort.", wantRules: []string{"gitlint-body-min-length"}},
+	}
+
+	for _, testCase := range tests {
+		var got []string
+		for _, v := range commitmsg.Lint(config, testCase.message) {
+			got = append(got, v.Rule.Name)
+		}
+
+		if !slices.Equal(got, testCase.wantRules) {
+			t.Errorf("Lint(%q) violated %v, want %v", testCase.message, got, testCase.wantRules)
+		}
+	}
+}
+
+func TestRunImportGitlint(t *testing.T) {
+	path := writeGitlintConfig(t, testGitlintConfig)
+	t.Chdir(t.TempDir())
+
+	var stderr bytes.Buffer
+
+	err := commitmsg.RunWith(commitmsg.RunOptions{
+		Args:        []string{"commit-msg-lint", "--import-gitlint", path, "--text", "WIP: login"},
+		Stdin:       nil,
+		Stdout:      nil,
+		Stderr:      &stderr,
+		ErrorPrefix: "",
+	})
+	if err == nil || !strings.Contains(err.Error(), "gitlint-title-must-not-contain-word") {
+		t.Errorf("RunWith() error = %v, want title-must-not-contain-word violation", err)
+	}
+
+	if !strings.Contains(stderr.String(), `Ignoring unsupported gitlint rule "body-max-line-length"`) {
+		t.Errorf("stderr = %q, want warning about unsupported rule", stderr.String())
+	}
+}
@@ -0,0 +1,362 @@
+package commitmsg
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// outputFormat selects how violations are reported.
+type outputFormat string
+
+const (
+	// formatText reports violations as human-readable text (default).
+	formatText outputFormat = "text"
+	// formatJSON reports violations and a summary as a JSON object on stdout.
+	formatJSON outputFormat = "json"
+	// formatGitLab reports violations as a GitLab Code Quality report on stdout.
+	formatGitLab outputFormat = "gitlab"
+)
+
+// gitLabSeverity maps rule severities to GitLab Code Quality severities.
+const (
+	gitLabSeverityMajor = "major"
+	gitLabSeverityMinor = "minor"
+)
+
+// ruleListPadding is the number of spaces between the columns of the rule list.
+const ruleListPadding = 2
+
+// jsonViolation is the JSON representation of a single rule violation.
+type jsonViolation struct {
+	Commit   string   `json:"commit"`
+	Ref      string   `json:"ref"`
+	Rule     string   `json:"rule"`
+	Type     RuleType `json:"type"`
+	Scope    Scope    `json:"scope"`
+	Pattern  string   `json:"pattern"`
+	Matched  bool     `json:"matched"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// jsonRule is the JSON representation of a configured rule listed with
+// --list-rules.
+type jsonRule struct {
+	Name     string   `json:"name"`
+	Type     RuleType `json:"type"`
+	Scope    Scope    `json:"scope,omitempty"`
+	Pattern  string   `json:"pattern,omitempty"`
+	Patterns []string `json:"patterns,omitempty"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// gitLabIssue is a single entry of a GitLab Code Quality report.
+// See https://docs.gitlab.com/ci/testing/code_quality/#code-quality-report-format.
+type gitLabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitLabLocation `json:"location"`
+}
+
+// gitLabLocation is the location of a GitLab Code Quality issue.
+type gitLabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitLabLines `json:"lines"`
+}
+
+// gitLabLines is the line range of a GitLab Code Quality issue.
+type gitLabLines struct {
+	Begin int `json:"begin"`
+}
+
+// jsonReport is the JSON representation of the result of a run.
+type jsonReport struct {
+	Violations []jsonViolation `json:"violations"`
+	Summary    runSummary      `json:"summary"`
+}
+
+// runSummary counts the commit messages validated in a run.
+type runSummary struct {
+	// Checked is the number of validated commit messages, skipped commits are not
+	// counted.
+	Checked int `json:"checked"`
+	// Failed is the number of commit messages with error-level violations.
+	Failed int `json:"failed"`
+	// Warnings is the number of warning-level violations.
+	Warnings int `json:"warnings"`
+
+	// ranges is the number of validated commit ranges. The summary line is only
+	// written after validating commit ranges, not for a single commit message.
+	ranges int
+}
+
+// record counts a validated commit message with its reported violations.
+func (s *runSummary) record(violations []RuleViolation) {
+	s.Checked++
+
+	if hasErrors(violations) {
+		s.Failed++
+	}
+
+	for _, v := range violations {
+		if v.Severity == SeverityWarning {
+			s.Warnings++
+		}
+	}
+}
+
+// String returns the summary line, e.g. "Checked 14 commits, 3 failed, 2 warnings".
+func (s runSummary) String() string {
+	return fmt.Sprintf("Checked %d %s, %d failed, %d %s",
+		s.Checked, plural(s.Checked, "commit"), s.Failed, s.Warnings, plural(s.Warnings, "warning"))
+}
+
+// plural returns word with an "s" appended unless count is one.
+func plural(count int, word string) string {
+	if count == 1 {
+		return word
+	}
+
+	return word + "s"
+}
+
+// withSummary writes the summary line to the report after commit ranges were
+// validated in text format mode. The summary of a run with error-level violations
+// is appended to the violation report instead, so it is the last line of the
+// output in any case. Other errors are returned unchanged.
+func (r *runner) withSummary(err error) error {
+	if r.format != formatText || r.summary.ranges == 0 {
+		return err
+	}
+
+	var violationErr *ViolationError
+
+	switch {
+	case err == nil:
+		_, _ = fmt.Fprintln(r.report, r.summary)
+		return nil
+
+	case errors.As(err, &violationErr):
+		return violationErrorf("%s\n\n%s", strings.TrimRight(violationErr.msg, "\n"), r.summary)
+
+	default:
+		return err
+	}
+}
+
+// reportFile is the file given with --output, which receives the report instead
+// of stderr or stdout. Reports are written without checking for errors, so the
+// first write error is kept and reported by close.
+type reportFile struct {
+	path string
+	file *os.File
+	err  error
+}
+
+// createReportFile creates or truncates the report file at path.
+func createReportFile(path string) (*reportFile, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, &OutputError{Path: path, Err: err}
+	}
+
+	return &reportFile{path: path, file: file, err: nil}, nil
+}
+
+// Write writes p to the file unless an earlier write failed.
+func (f *reportFile) Write(p []byte) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+
+	n, err := f.file.Write(p)
+	if err != nil {
+		f.err = err
+	}
+
+	return n, err
+}
+
+// close writes the report of a failed validation in text format mode, which is
+// otherwise printed from the returned error, to the file and closes it. It
+// returns an OutputError if the report could not be written, otherwise runErr,
+// with the report of a ViolationError replaced by a reference to the file.
+func (f *reportFile) close(format outputFormat, runErr error) error {
+	var violationErr *ViolationError
+	if format == formatText && errors.As(runErr, &violationErr) {
+		_, _ = fmt.Fprintln(f, strings.TrimRight(violationErr.msg, "\n"))
+		runErr = violationErrorf("commit message validation failed, see the report in %s", f.path)
+	}
+
+	closeErr := f.file.Close()
+
+	err := cmp.Or(f.err, closeErr)
+	if err != nil {
+		return &OutputError{Path: f.path, Err: err}
+	}
+
+	return runErr
+}
+
+// machineReadable reports whether the format replaces the human-readable report.
+func (f outputFormat) machineReadable() bool {
+	return f == formatJSON || f == formatGitLab
+}
+
+// recordViolations collects violations for the machine-readable report. The commit hash
+// and ref are empty when validating a commit message file.
+func (r *runner) recordViolations(commitHash string, ref string, violations []RuleViolation) {
+	for _, v := range violations {
+		r.jsonViolations = append(r.jsonViolations, jsonViolation{
+			Commit:   commitHash,
+			Ref:      ref,
+			Rule:     v.Rule.Name,
+			Type:     v.Rule.Type,
+			Scope:    v.Rule.Scope,
+			Pattern:  cmp.Or(v.MatchedPattern, v.Rule.Pattern),
+			Matched:  v.Matched,
+			Severity: v.Severity,
+			Message:  getViolationMessage(v, commitHash),
+		})
+	}
+}
+
+// writeReport writes the collected violations to stdout in the machine-readable
+// output format. It does nothing in text format mode.
+func (r *runner) writeReport() error {
+	var report any
+
+	switch r.format {
+	case formatJSON:
+		violations := r.jsonViolations
+		if violations == nil {
+			violations = []jsonViolation{}
+		}
+
+		report = jsonReport{Violations: violations, Summary: r.summary}
+
+	case formatGitLab:
+		report = gitLabReport(r.jsonViolations)
+
+	default:
+		return nil
+	}
+
+	encoder := json.NewEncoder(r.stdout)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(report)
+	if err != nil {
+		return fmt.Errorf("failed to write %s report: %w", r.format, err)
+	}
+
+	return nil
+}
+
+// writeRuleList writes the rules to stdout, as a table in text format mode or as
+// a JSON array in JSON format mode. Custom messages are listed with their
+// placeholders, rules without one with the default message of their type.
+func writeRuleList(rules []Rule, format outputFormat, stdout io.Writer) error {
+	list := make([]jsonRule, 0, len(rules))
+	for _, rule := range rules {
+		list = append(list, jsonRule{
+			Name:     rule.Name,
+			Type:     rule.Type,
+			Scope:    rule.Scope,
+			Pattern:  rule.Pattern,
+			Patterns: rule.Patterns,
+			Severity: rule.Severity,
+			Message: cmp.Or(rule.Message, getViolationMessage(RuleViolation{
+				Rule:           rule,
+				Severity:       rule.Severity,
+				Matched:        false,
+				Detail:         "",
+				MatchedText:    "",
+				MatchOffset:    0,
+				MatchedPattern: "",
+			}, "")),
+		})
+	}
+
+	if format == formatJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+
+		err := encoder.Encode(list)
+		if err != nil {
+			return fmt.Errorf("failed to write rule list: %w", err)
+		}
+
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, ruleListPadding, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tTYPE\tSCOPE\tPATTERN\tSEVERITY\tMESSAGE")
+
+	for _, rule := range list {
+		pattern := cmp.Or(rule.Pattern, strings.Join(rule.Patterns, ", "), "-")
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			rule.Name, rule.Type, cmp.Or(string(rule.Scope), "-"), pattern, rule.Severity, rule.Message)
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to write rule list: %w", err)
+	}
+
+	return nil
+}
+
+// gitLabReport converts violations into GitLab Code Quality issues. Commits have
+// no file location, so the commit hash is used as a synthetic path.
+func gitLabReport(violations []jsonViolation) []gitLabIssue {
+	issues := make([]gitLabIssue, 0, len(violations))
+
+	for _, v := range violations {
+		severity := gitLabSeverityMajor
+		if v.Severity == SeverityWarning {
+			severity = gitLabSeverityMinor
+		}
+
+		description := fmt.Sprintf("[%s] %s", v.Rule, v.Message)
+
+		path := v.Commit
+		if path == "" {
+			path = "COMMIT_EDITMSG"
+		} else {
+			description = fmt.Sprintf("%s (commit %s)", description, v.Commit[:7])
+		}
+
+		issues = append(issues, gitLabIssue{
+			Description: description,
+			CheckName:   v.Rule,
+			Fingerprint: gitLabFingerprint(v.Commit, v.Rule),
+			Severity:    severity,
+			Location: gitLabLocation{
+				Path:  path,
+				Lines: gitLabLines{Begin: 1},
+			},
+		})
+	}
+
+	return issues
+}
+
+// gitLabFingerprint returns a stable fingerprint for a violation of rule in commit,
+// so GitLab can track the same issue across pipeline runs.
+func gitLabFingerprint(commitHash string, rule string) string {
+	sum := sha256.Sum256([]byte(commitHash + "\x00" + rule))
+
+	return hex.EncodeToString(sum[:])
+}
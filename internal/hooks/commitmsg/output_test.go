@@ -0,0 +1,989 @@
+package commitmsg_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/breml/githooks/internal/hooks/commitmsg"
+)
+
+// captureStdout is a test helper that returns everything fn writes to os.Stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+
+	origStdout := os.Stdout
+	os.Stdout = writer
+
+	defer func() {
+		os.Stdout = origStdout
+	}()
+
+	outCh := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		outCh <- string(data)
+	}()
+
+	fn()
+
+	_ = writer.Close()
+
+	return <-outCh
+}
+
+type jsonViolation struct {
+	Commit   string `json:"commit"`
+	Ref      string `json:"ref"`
+	Rule     string `json:"rule"`
+	Type     string `json:"type"`
+	Scope    string `json:"scope"`
+	Pattern  string `json:"pattern"`
+	Matched  bool   `json:"matched"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+type jsonSummary struct {
+	Checked  int `json:"checked"`
+	Failed   int `json:"failed"`
+	Warnings int `json:"warnings"`
+}
+
+type jsonReport struct {
+	Violations []jsonViolation `json:"violations"`
+	Summary    jsonSummary     `json:"summary"`
+}
+
+func TestRunFormatJSON(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{message: "feat: add feature", files: map[string]string{"file1.txt": "content1"}},
+		{message: "WIP: debugging", files: map[string]string{"file2.txt": "content2"}},
+	})
+	writeConfigFile(t, tmpDir, defaultWIPConfig)
+	t.Chdir(tmpDir)
+
+	t.Run("violation is reported as JSON", func(t *testing.T) {
+		var runErr error
+		out := captureStdout(t, func() {
+			runErr = commitmsg.Run(strings.NewReader(""), []string{
+				"commit-msg-lint", "--format", "json", "--head-ref", hashes[1].String(),
+			})
+		})
+
+		if runErr == nil {
+			t.Fatal("Run() error = nil, want error for error-level violation")
+		}
+
+		if strings.Contains(runErr.Error(), "Rule violations:") {
+			t.Errorf("Run() error contains human-readable report in JSON mode: %q", runErr.Error())
+		}
+
+		var report jsonReport
+		err := json.Unmarshal([]byte(out), &report)
+		if err != nil {
+			t.Fatalf("failed to parse JSON output %q: %v", out, err)
+		}
+
+		violations := report.Violations
+		if len(violations) != 1 {
+			t.Fatalf("got %d violations, want 1: %+v", len(violations), violations)
+		}
+
+		want := jsonViolation{
+			Commit:   hashes[1].String(),
+			Ref:      fmt.Sprintf("main..%s", hashes[1].String()),
+			Rule:     "prevent-wip",
+			Type:     "deny",
+			Scope:    "title",
+			Pattern:  `(?i)(?:^|[\s\(\)])(wip)(?:[\s\(\):]|$)`,
+			Matched:  true,
+			Severity: "error",
+			Message:  "WIP commits are not allowed",
+		}
+		if violations[0] != want {
+			t.Errorf("violation = %+v, want %+v", violations[0], want)
+		}
+
+		wantSummary := jsonSummary{Checked: 2, Failed: 1, Warnings: 0}
+		if report.Summary != wantSummary {
+			t.Errorf("summary = %+v, want %+v", report.Summary, wantSummary)
+		}
+	})
+
+	t.Run("clean range reports empty violations", func(t *testing.T) {
+		var runErr error
+		out := captureStdout(t, func() {
+			runErr = commitmsg.Run(strings.NewReader(""), []string{
+				"commit-msg-lint", "--format", "json", "--head-ref", hashes[0].String(),
+			})
+		})
+
+		if runErr != nil {
+			t.Fatalf("Run() error = %v, want nil", runErr)
+		}
+
+		var report jsonReport
+		err := json.Unmarshal([]byte(out), &report)
+		if err != nil {
+			t.Fatalf("failed to parse JSON output %q: %v", out, err)
+		}
+
+		if report.Violations == nil || len(report.Violations) != 0 {
+			t.Errorf("output = %q, want empty violations array", out)
+		}
+
+		if report.Summary.Checked != 1 {
+			t.Errorf("summary = %+v, want 1 checked commit", report.Summary)
+		}
+	})
+
+	t.Run("commits since a date are reported as JSON", func(t *testing.T) {
+		var runErr error
+		out := captureStdout(t, func() {
+			runErr = commitmsg.Run(nil, []string{"commit-msg-lint", "--format", "json", "--since", "2000-01-01"})
+		})
+
+		if runErr == nil {
+			t.Fatal("Run() error = nil, want error for error-level violation")
+		}
+
+		var report jsonReport
+		err := json.Unmarshal([]byte(out), &report)
+		if err != nil {
+			t.Fatalf("failed to parse JSON output %q: %v", out, err)
+		}
+
+		violations := report.Violations
+		if len(violations) != 1 || violations[0].Commit != hashes[1].String() || violations[0].Ref != "HEAD" {
+			t.Errorf("violations = %+v, want the WIP commit in HEAD", violations)
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		err := commitmsg.Run(strings.NewReader(""), []string{"commit-msg-lint", "--format", "xml"})
+		if err == nil || !strings.Contains(err.Error(), "invalid --format") {
+			t.Errorf("Run() error = %v, want invalid --format error", err)
+		}
+	})
+}
+
+type gitLabIssue struct {
+	Description string `json:"description"`
+	CheckName   string `json:"check_name"`
+	Fingerprint string `json:"fingerprint"`
+	Severity    string `json:"severity"`
+	Location    struct {
+		Path  string `json:"path"`
+		Lines struct {
+			Begin int `json:"begin"`
+		} `json:"lines"`
+	} `json:"location"`
+}
+
+func TestRunFormatGitLab(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{message: "WIP: debugging", files: map[string]string{"file1.txt": "content1"}},
+	})
+	writeConfigFile(t, tmpDir, defaultWIPConfig)
+	t.Chdir(tmpDir)
+
+	runGitLab := func() []gitLabIssue {
+		t.Helper()
+
+		var runErr error
+		out := captureStdout(t, func() {
+			runErr = commitmsg.Run(strings.NewReader(""), []string{
+				"commit-msg-lint", "--format", "gitlab", "--head-ref", hashes[0].String(),
+			})
+		})
+
+		if runErr == nil {
+			t.Fatal("Run() error = nil, want error for error-level violation")
+		}
+
+		var issues []gitLabIssue
+		err := json.Unmarshal([]byte(out), &issues)
+		if err != nil {
+			t.Fatalf("failed to parse GitLab report %q: %v", out, err)
+		}
+
+		return issues
+	}
+
+	first := runGitLab()
+	if len(first) != 1 {
+		t.Fatalf("got %d issues, want 1: %+v", len(first), first)
+	}
+
+	issue := first[0]
+	if issue.CheckName != "prevent-wip" {
+		t.Errorf("check_name = %q, want %q", issue.CheckName, "prevent-wip")
+	}
+
+	if !strings.Contains(issue.Description, "WIP commits are not allowed") {
+		t.Errorf("description = %q, want it to contain the rule message", issue.Description)
+	}
+
+	if issue.Severity != "major" {
+		t.Errorf("severity = %q, want %q", issue.Severity, "major")
+	}
+
+	if issue.Location.Path != hashes[0].String() || issue.Location.Lines.Begin != 1 {
+		t.Errorf("location = %+v, want path %s line 1", issue.Location, hashes[0].String())
+	}
+
+	if len(issue.Fingerprint) != 64 {
+		t.Errorf("fingerprint = %q, want 64 hex characters", issue.Fingerprint)
+	}
+
+	second := runGitLab()
+	if len(second) != 1 || second[0].Fingerprint != issue.Fingerprint {
+		t.Errorf("fingerprint not stable across runs: %q vs %+v", issue.Fingerprint, second)
+	}
+}
+
+func TestRunWith(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{message: "WIP: debugging", files: map[string]string{"file1.txt": "content1"}},
+	})
+	writeConfigFile(t, tmpDir, defaultWIPConfig+`  - name: issue-ref
+    type: require
+    scope: message
+    pattern: '#\d+'
+    severity: warning
+`)
+	t.Chdir(tmpDir)
+
+	var stdout, stderr bytes.Buffer
+
+	err := commitmsg.RunWith(commitmsg.RunOptions{
+		Args:        []string{"commit-msg-lint", "--head-ref", hashes[0].String()},
+		Stdin:       strings.NewReader(""),
+		Stdout:      &stdout,
+		Stderr:      &stderr,
+		ErrorPrefix: "commit-msg-lint: ",
+	})
+	if err == nil {
+		t.Fatal("RunWith() returned no error, want violations")
+	}
+
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q, want no output in text format", stdout.String())
+	}
+
+	got := stderr.String()
+	for _, want := range []string{
+		"commit-msg-lint: Commit " + hashes[0].String()[:7],
+		"[prevent-wip] WIP commits are not allowed",
+		"Warnings:",
+		"[issue-ref]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("stderr = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestRunWithQuiet(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{message: "feat: add feature", files: map[string]string{"file1.txt": "content1"}},
+		{message: "WIP: debugging", files: map[string]string{"file2.txt": "content2"}},
+	})
+	writeConfigFile(t, tmpDir, defaultWIPConfig+`  - name: issue-ref
+    type: require
+    scope: message
+    pattern: '#\d+'
+    severity: warning
+`)
+	t.Chdir(tmpDir)
+
+	tests := []struct {
+		name          string
+		args          []string
+		wantViolation bool
+		wantConfig    bool
+	}{
+		{
+			name:          "violations",
+			args:          []string{"commit-msg-lint", "--quiet", "--head-ref", hashes[1].String()},
+			wantViolation: true,
+			wantConfig:    false,
+		},
+		{
+			name:          "warnings only",
+			args:          []string{"commit-msg-lint", "--quiet", "--head-ref", hashes[0].String()},
+			wantViolation: false,
+			wantConfig:    false,
+		},
+		{
+			name:          "missing config file",
+			args:          []string{"commit-msg-lint", "--quiet", "--config", "missing.yml", "--head-ref", "HEAD"},
+			wantViolation: false,
+			wantConfig:    true,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			err := commitmsg.RunWith(commitmsg.RunOptions{
+				Args:        testCase.args,
+				Stdin:       strings.NewReader(""),
+				Stdout:      &stdout,
+				Stderr:      &stderr,
+				ErrorPrefix: "Error: ",
+			})
+
+			var violationErr *commitmsg.ViolationError
+			if errors.As(err, &violationErr) != testCase.wantViolation {
+				t.Errorf("RunWith() error = %v, want violation error %v", err, testCase.wantViolation)
+			}
+
+			var configErr *commitmsg.ConfigError
+			if errors.As(err, &configErr) != testCase.wantConfig {
+				t.Errorf("RunWith() error = %v, want config error %v", err, testCase.wantConfig)
+			}
+
+			if stdout.Len() != 0 || stderr.Len() != 0 {
+				t.Errorf("stdout = %q, stderr = %q, want no output", stdout.String(), stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunReportFullMessage(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{
+			message: "WIP: debugging\n\nFirst body line.\n\nSecond body line.\nThird body line.",
+			files:   map[string]string{"file1.txt": "content1"},
+		},
+	})
+	t.Chdir(tmpDir)
+
+	tests := []struct {
+		name        string
+		settings    string
+		wantContain []string
+		wantMissing []string
+	}{
+		{
+			name:        "first line only by default",
+			settings:    "",
+			wantContain: []string{"Commit message: WIP: debugging\n"},
+			wantMissing: []string{"First body line."},
+		},
+		{
+			name:     "full message",
+			settings: "settings:\n  report_full_message: true\n",
+			wantContain: []string{
+				"Commit message:\n    WIP: debugging\n\n    First body line.\n\n    Second body line.\n    Third body line.\n\n",
+			},
+			wantMissing: []string{"more lines"},
+		},
+		{
+			name:        "capped full message",
+			settings:    "settings:\n  report_full_message: true\n  report_max_lines: 3\n",
+			wantContain: []string{"Commit message:\n    WIP: debugging\n\n    First body line.\n    ... (3 more lines)\n\n"},
+			wantMissing: []string{"Second body line."},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			writeConfigFile(t, tmpDir, defaultWIPConfig+testCase.settings)
+
+			var stderr bytes.Buffer
+
+			err := commitmsg.RunWith(commitmsg.RunOptions{
+				Args:        []string{"commit-msg-lint", "--head-ref", hashes[0].String()},
+				Stdin:       strings.NewReader(""),
+				Stdout:      io.Discard,
+				Stderr:      &stderr,
+				ErrorPrefix: "",
+			})
+			if err == nil {
+				t.Fatal("RunWith() returned no error, want violations")
+			}
+
+			got := stderr.String()
+			for _, want := range testCase.wantContain {
+				if !strings.Contains(got, want) {
+					t.Errorf("stderr = %q, want it to contain %q", got, want)
+				}
+			}
+
+			for _, unwanted := range testCase.wantMissing {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("stderr = %q, want it not to contain %q", got, unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{message: "feat: add feature", files: map[string]string{"file1.txt": "content1"}},
+		{message: "fix: handle error (#12)", files: map[string]string{"file2.txt": "content2"}},
+		{message: "WIP: debugging", files: map[string]string{"file3.txt": "content3"}},
+	})
+	writeConfigFile(t, tmpDir, defaultWIPConfig+`  - name: issue-ref
+    type: require
+    scope: message
+    pattern: '#\d+'
+    severity: warning
+`)
+	t.Chdir(tmpDir)
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantSummary string
+	}{
+		{
+			name:        "failed range",
+			args:        []string{"commit-msg-lint", "--head-ref", hashes[2].String()},
+			wantSummary: "Checked 3 commits, 1 failed, 2 warnings\n",
+		},
+		{
+			name:        "range with warnings only",
+			args:        []string{"commit-msg-lint", "--head-ref", hashes[1].String()},
+			wantSummary: "Checked 2 commits, 0 failed, 1 warning\n",
+		},
+		{
+			name:        "no summary for a single message",
+			args:        []string{"commit-msg-lint", "--text", "feat: add feature (#12)"},
+			wantSummary: "",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+
+			_ = commitmsg.RunWith(commitmsg.RunOptions{
+				Args:        testCase.args,
+				Stdin:       strings.NewReader(""),
+				Stdout:      io.Discard,
+				Stderr:      &stderr,
+				ErrorPrefix: "Error: ",
+			})
+
+			got := stderr.String()
+			if testCase.wantSummary == "" {
+				if strings.Contains(got, "Checked ") {
+					t.Errorf("stderr = %q, want no summary line", got)
+				}
+
+				return
+			}
+
+			// The summary is the last line, also after the report of failed commits
+			if !strings.HasSuffix(got, testCase.wantSummary) {
+				t.Errorf("stderr = %q, want it to end with summary %q", got, testCase.wantSummary)
+			}
+		})
+	}
+}
+
+func TestRunVerbose(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{message: "feat: add feature", files: map[string]string{"file1.txt": "content1"}},
+		{message: "Revert \"feat: add feature\"", files: map[string]string{"file1.txt": "content2"}},
+		{message: "WIP: debugging", files: map[string]string{"file2.txt": "content3"}},
+	})
+	writeConfigFile(t, tmpDir, defaultWIPConfig+`settings:
+  skip_subjects:
+    - '^Revert "'
+`)
+	t.Chdir(tmpDir)
+
+	input := fmt.Sprintf("refs/heads/feature %s refs/heads/feature %s\n", hashes[2].String(), gitZeroHash)
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantLines []string
+	}{
+		{
+			name: "verbose",
+			args: []string{"commit-msg-lint", "--verbose"},
+			wantLines: []string{
+				"Checking refs/heads/feature pushed to refs/heads/feature (",
+				"fail " + hashes[2].String()[:7] + " WIP: debugging",
+				"skip " + hashes[1].String()[:7] + " Revert \"feat: add feature\"",
+				"pass " + hashes[0].String()[:7] + " feat: add feature",
+			},
+		},
+		{
+			name:      "quiet by default",
+			args:      []string{"commit-msg-lint"},
+			wantLines: nil,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+
+			err := commitmsg.RunWith(commitmsg.RunOptions{
+				Args:        testCase.args,
+				Stdin:       strings.NewReader(input),
+				Stdout:      io.Discard,
+				Stderr:      &stderr,
+				ErrorPrefix: "",
+			})
+			if err == nil {
+				t.Fatal("RunWith() returned no error, want violations")
+			}
+
+			// The progress lines are written before the report of the failed commit
+			report := err.Error()
+			progress := strings.TrimSuffix(stderr.String(), report+"\n")
+
+			for _, want := range testCase.wantLines {
+				if !strings.Contains(progress, want) {
+					t.Errorf("stderr = %q, want it to contain %q", progress, want)
+				}
+			}
+
+			if testCase.wantLines == nil && progress != "" {
+				t.Errorf("stderr = %q, want no progress output", progress)
+			}
+		})
+	}
+}
+
+func TestRunDebug(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{message: "Add feature", files: map[string]string{"file1.txt": "content1"}},
+		{message: "fixup! Add feature", files: map[string]string{"file2.txt": "content2"}},
+	})
+	writeConfigFile(t, tmpDir, `settings:
+  skip_fixup_commits: true
+rules:
+  - name: prevent-wip
+    type: deny
+    scope: title
+    pattern: '(?i)wip'
+`)
+	t.Chdir(tmpDir)
+
+	var stderr bytes.Buffer
+
+	err := commitmsg.RunWith(commitmsg.RunOptions{
+		Args: []string{
+			"commit-msg-lint", "--debug", "--base-ref", hashes[0].String(), "--head-ref", hashes[1].String(),
+		},
+		Stdin:       strings.NewReader(""),
+		Stdout:      io.Discard,
+		Stderr:      &stderr,
+		ErrorPrefix: "",
+	})
+	if err != nil {
+		t.Fatalf("RunWith() returned unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		`msg="loaded config" path=` + filepath.Join(tmpDir, commitmsg.DefaultConfigFile),
+		`msg="resolved refs" base_ref=` + hashes[0].String() + " base=" + hashes[0].String(),
+		`msg="walked commit range" range=` + hashes[0].String() + ".." + hashes[1].String() + " commits=1",
+		`msg="skipped commit" commit=` + hashes[1].String() + " reason=skip_fixup_commits",
+	} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr = %q, want it to contain %q", stderr.String(), want)
+		}
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{message: "WIP: first", files: map[string]string{"file1.txt": "content1"}},
+		{message: "WIP: second", files: map[string]string{"file2.txt": "content2"}},
+	})
+	writeConfigFile(t, tmpDir, defaultWIPConfig)
+	t.Chdir(tmpDir)
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "text report on stderr",
+			args:       []string{"commit-msg-lint", "--dry-run", "--head-ref", hashes[1].String()},
+			wantStdout: "",
+			wantStderr: "2 commits in main.." + hashes[1].String() + " failed validation",
+		},
+		{
+			name:       "json report on stdout",
+			args:       []string{"commit-msg-lint", "--dry-run", "--format", "json", "--head-ref", hashes[1].String()},
+			wantStdout: `"rule": "prevent-wip"`,
+			wantStderr: "",
+		},
+		{
+			name:       "text argument",
+			args:       []string{"commit-msg-lint", "--dry-run", "--text", "WIP: debugging"},
+			wantStdout: "",
+			wantStderr: "Commit message in the --text argument failed validation",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			err := commitmsg.RunWith(commitmsg.RunOptions{
+				Args:        testCase.args,
+				Stdin:       strings.NewReader(""),
+				Stdout:      &stdout,
+				Stderr:      &stderr,
+				ErrorPrefix: "",
+			})
+			if err != nil {
+				t.Fatalf("RunWith() returned unexpected error: %v", err)
+			}
+
+			if !strings.Contains(stdout.String(), testCase.wantStdout) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout.String(), testCase.wantStdout)
+			}
+
+			if !strings.Contains(stderr.String(), testCase.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), testCase.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRunFailOn(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{message: "Add feature", files: map[string]string{"file1.txt": "content1"}},
+	})
+	writeConfigFile(t, tmpDir, `rules:
+  - name: issue-ref
+    type: require
+    scope: message
+    pattern: '#\d+'
+    severity: warning
+`)
+	t.Chdir(tmpDir)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "warnings pass by default",
+			args:    []string{"commit-msg-lint", "--head-ref", hashes[0].String()},
+			wantErr: "",
+		},
+		{
+			name:    "warnings fail with fail-on warning",
+			args:    []string{"commit-msg-lint", "--fail-on", "warning", "--head-ref", hashes[0].String()},
+			wantErr: "has warnings",
+		},
+		{
+			name:    "warnings fail text argument with fail-on warning",
+			args:    []string{"commit-msg-lint", "--fail-on", "warning", "--text", "Add feature"},
+			wantErr: "Commit message in the --text argument has warnings",
+		},
+		{
+			name:    "json report with fail-on warning",
+			args:    []string{"commit-msg-lint", "--fail-on", "warning", "--format", "json", "--text", "Add feature"},
+			wantErr: "failed validation",
+		},
+		{
+			name:    "dry-run takes precedence over fail-on",
+			args:    []string{"commit-msg-lint", "--dry-run", "--fail-on", "warning", "--head-ref", hashes[0].String()},
+			wantErr: "",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := commitmsg.RunWith(commitmsg.RunOptions{
+				Args:        testCase.args,
+				Stdin:       strings.NewReader(""),
+				Stdout:      io.Discard,
+				Stderr:      io.Discard,
+				ErrorPrefix: "",
+			})
+
+			if testCase.wantErr == "" {
+				if err != nil {
+					t.Fatalf("RunWith() returned unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			var violationErr *commitmsg.ViolationError
+			if !errors.As(err, &violationErr) {
+				t.Fatalf("RunWith() error = %v, want a *ViolationError", err)
+			}
+
+			if !strings.Contains(err.Error(), testCase.wantErr) {
+				t.Errorf("RunWith() error = %q, want it to contain %q", err.Error(), testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunOutput(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{message: "WIP: first", files: map[string]string{"file1.txt": "content1"}},
+	})
+	writeConfigFile(t, tmpDir, defaultWIPConfig)
+	t.Chdir(tmpDir)
+
+	reportPath := filepath.Join(tmpDir, "report.txt")
+
+	tests := []struct {
+		name       string
+		format     string
+		wantReport string
+		wantStdout string
+	}{
+		{
+			name:       "text report",
+			format:     "text",
+			wantReport: "Commit " + hashes[0].String()[:7] + " in main.." + hashes[0].String(),
+			wantStdout: "",
+		},
+		{
+			name:       "json report",
+			format:     "json",
+			wantReport: `"rule": "prevent-wip"`,
+			wantStdout: "",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			// The report file is truncated, stale content must not survive
+			err := os.WriteFile(reportPath, []byte("stale report\n"), 0o600)
+			if err != nil {
+				t.Fatalf("failed to write report file: %v", err)
+			}
+
+			var stdout, stderr bytes.Buffer
+
+			err = commitmsg.RunWith(commitmsg.RunOptions{
+				Args: []string{
+					"commit-msg-lint", "--output", reportPath, "--format", testCase.format,
+					"--head-ref", hashes[0].String(),
+				},
+				Stdin:       strings.NewReader(""),
+				Stdout:      &stdout,
+				Stderr:      &stderr,
+				ErrorPrefix: "",
+			})
+
+			var violationErr *commitmsg.ViolationError
+			if !errors.As(err, &violationErr) {
+				t.Fatalf("RunWith() error = %v, want a *ViolationError", err)
+			}
+
+			report, readErr := os.ReadFile(reportPath)
+			if readErr != nil {
+				t.Fatalf("failed to read report file: %v", readErr)
+			}
+
+			if !strings.Contains(string(report), testCase.wantReport) {
+				t.Errorf("report = %q, want it to contain %q", report, testCase.wantReport)
+			}
+
+			if strings.Contains(string(report), "stale report") {
+				t.Errorf("report = %q, want the file to be truncated", report)
+			}
+
+			if stdout.String() != testCase.wantStdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), testCase.wantStdout)
+			}
+
+			if strings.Contains(stderr.String(), "prevent-wip") {
+				t.Errorf("stderr = %q, want the report only in the file", stderr.String())
+			}
+		})
+	}
+
+	t.Run("write error", func(t *testing.T) {
+		err := commitmsg.RunWith(commitmsg.RunOptions{
+			Args: []string{
+				"commit-msg-lint", "--output", filepath.Join(tmpDir, "missing", "report.txt"),
+				"--head-ref", hashes[0].String(),
+			},
+			Stdin:       strings.NewReader(""),
+			Stdout:      io.Discard,
+			Stderr:      io.Discard,
+			ErrorPrefix: "",
+		})
+
+		var outputErr *commitmsg.OutputError
+		if !errors.As(err, &outputErr) {
+			t.Fatalf("RunWith() error = %v, want an *OutputError", err)
+		}
+	})
+}
+
+func TestColorReport(t *testing.T) {
+	rules := createRulesFromYAML(t, `rules:
+  - name: prevent-wip
+    type: deny
+    scope: title
+    pattern: '(?i)wip'
+  - name: issue-ref
+    type: require
+    scope: message
+    pattern: '#\d+'
+    severity: warning
+`)
+	violations := commitmsg.EvaluateRules(rules, commitmsg.ParseCommitMessage("WIP: debugging"))
+
+	plain := commitmsg.FormatMessageReportForTesting(false, "the --text argument", violations)
+	if strings.Contains(plain, "\x1b[") {
+		t.Errorf("plain report contains escape sequences: %q", plain)
+	}
+
+	colored := commitmsg.FormatMessageReportForTesting(true, "the --text argument", violations)
+	for _, want := range []string{"\x1b[31mfailed\x1b[0m", "[\x1b[31mprevent-wip\x1b[0m]", "[\x1b[33missue-ref\x1b[0m]"} {
+		if !strings.Contains(colored, want) {
+			t.Errorf("colored report = %q, want it to contain %q", colored, want)
+		}
+	}
+
+	// Output to a pipe or file is never colorized
+	var buf bytes.Buffer
+	if commitmsg.ColorEnabledForTesting(&buf, false) {
+		t.Error("colors enabled for a buffer, want disabled")
+	}
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "report.txt"))
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	defer file.Close()
+
+	if commitmsg.ColorEnabledForTesting(file, false) {
+		t.Error("colors enabled for a regular file, want disabled")
+	}
+}
+
+func TestRunListRules(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, tmpDir, defaultWIPConfig+`  - name: body-wrap
+    type: body_wrap
+    limit: 72
+    severity: warning
+`)
+	t.Chdir(tmpDir)
+
+	t.Run("text", func(t *testing.T) {
+		var stdout bytes.Buffer
+
+		err := commitmsg.RunWith(commitmsg.RunOptions{
+			Args:        []string{"commit-msg-lint", "--list-rules"},
+			Stdin:       strings.NewReader(""),
+			Stdout:      &stdout,
+			Stderr:      io.Discard,
+			ErrorPrefix: "Error: ",
+		})
+		if err != nil {
+			t.Fatalf("RunWith() error = %v, want nil", err)
+		}
+
+		want := `NAME         TYPE       SCOPE  PATTERN                                 SEVERITY  MESSAGE
+prevent-wip  deny       title  (?i)(?:^|[\s\(\)])(wip)(?:[\s\(\):]|$)  error     WIP commits are not allowed
+body-wrap    body_wrap  -      -                                       warning   Commit body must be wrapped at 72 characters
+`
+		if stdout.String() != want {
+			t.Errorf("stdout = %q, want %q", stdout.String(), want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var stdout bytes.Buffer
+
+		err := commitmsg.RunWith(commitmsg.RunOptions{
+			Args:        []string{"commit-msg-lint", "--list-rules", "--format", "json"},
+			Stdin:       strings.NewReader(""),
+			Stdout:      &stdout,
+			Stderr:      io.Discard,
+			ErrorPrefix: "Error: ",
+		})
+		if err != nil {
+			t.Fatalf("RunWith() error = %v, want nil", err)
+		}
+
+		var rules []map[string]any
+
+		err = json.Unmarshal(stdout.Bytes(), &rules)
+		if err != nil {
+			t.Fatalf("failed to parse rule list %q: %v", stdout.String(), err)
+		}
+
+		if len(rules) != 2 || rules[0]["name"] != "prevent-wip" || rules[1]["severity"] != "warning" {
+			t.Errorf("rules = %v, want prevent-wip and the body-wrap warning", rules)
+		}
+
+		if _, ok := rules[1]["pattern"]; ok {
+			t.Errorf("rules[1] = %v, want no pattern for a built-in rule type", rules[1])
+		}
+	})
+}
+
+func TestRunMessagePlaceholders(t *testing.T) {
+	tmpDir, _, hashes := createTestRepo(t, []commit{
+		{message: "Update stuff", files: map[string]string{"file1.txt": "content1"}},
+	})
+	writeConfigFile(t, tmpDir, `rules:
+  - name: generic-subject
+    type: deny
+    scope: title
+    pattern: '(?i)\bstuff\b'
+    message: "Subject too generic: {match} in {scope} of {hash}, {unknown} stays"
+`)
+	t.Chdir(tmpDir)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "commit range",
+			args: []string{"commit-msg-lint", "--head-ref", hashes[0].String()},
+			want: "Subject too generic: stuff in title of " + hashes[0].String()[:7] + ", {unknown} stays",
+		},
+		{
+			name: "no commit hash for a single message",
+			args: []string{"commit-msg-lint", "--text", "Update stuff"},
+			want: "Subject too generic: stuff in title of , {unknown} stays",
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := commitmsg.Run(nil, testCase.args)
+			if err == nil || !strings.Contains(err.Error(), testCase.want) {
+				t.Errorf("Run() error = %v, want it to contain %q", err, testCase.want)
+			}
+		})
+	}
+}
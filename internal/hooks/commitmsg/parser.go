@@ -1,37 +1,129 @@
 package commitmsg
 
 import (
+	"regexp"
 	"strings"
 )
 
+// ccHeaderRegex matches a Conventional Commits header: type(scope)!: description.
+var ccHeaderRegex = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?: (.+)$`)
+
+// ccBreakingFooterRegex matches a BREAKING CHANGE footer.
+var ccBreakingFooterRegex = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+
+// trailerRegex matches a git trailer line: "Key: value".
+var trailerRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s*(.*)$`)
+
 // ParsedCommitMessage represents a commit message split into sections.
 type ParsedCommitMessage struct {
 	Raw    string
 	Title  string
 	Body   string
 	Footer string
+
+	// BodySections are the paragraphs of the body in order of appearance, which
+	// are joined by an empty line in Body.
+	BodySections []string
+
+	// Conventional Commits header fields, empty if the title is not
+	// Conventional Commits shaped.
+	CCType        string
+	CCScope       string
+	CCDescription string
+	// CCBreaking is true if the header has the "!" marker or the footer
+	// contains a BREAKING CHANGE note.
+	CCBreaking bool
+
+	// Trailers are the git trailers ("Key: value" lines) of the footer in order of
+	// appearance. A key may occur multiple times.
+	Trailers []Trailer
+
+	// TrailerBlock is the trailing block of the footer consisting only of trailer
+	// lines (and their folded continuation lines), FooterText the freeform footer
+	// text preceding it. If the footer is entirely freeform, TrailerBlock is empty
+	// and FooterText is the complete footer.
+	TrailerBlock string
+	FooterText   string
 }
 
+// Trailer is a single git trailer of a commit message.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// TrailerValues returns the values of all trailers with the given key
+// (case-insensitive) in order of appearance.
+func (m ParsedCommitMessage) TrailerValues(key string) []string {
+	var values []string
+
+	for _, trailer := range m.Trailers {
+		if strings.EqualFold(trailer.Key, key) {
+			values = append(values, trailer.Value)
+		}
+	}
+
+	return values
+}
+
+// messageParts is a set of commit message sections to populate when parsing.
+type messageParts uint8
+
+const (
+	// partTitle populates the Conventional Commits fields (the title itself is always populated).
+	partTitle messageParts = 1 << iota
+	// partBody populates the body.
+	partBody
+	// partFooter populates the footer.
+	partFooter
+
+	partAll = partTitle | partBody | partFooter
+)
+
 // ParseCommitMessage parses a commit message into title, body, and footer.
 //
 // Parsing rules:
 // - Sections are separated by empty lines (lines with only whitespace)
 // - Title: First section (always present)
 // - Footer: Last section (after final empty line), if 2+ sections exist
-// - Body: All middle sections (between title and footer), if 3+ sections exist.
+// - Body: All middle sections (between title and footer), if 3+ sections exist
+// - Body sections: The middle sections separately, as joined in the body
+// - Conventional Commits fields: From the first title line of the form "type(scope)!: description".
+// - Trailers: "Key: value" lines of the footer.
+// - Trailer block: The trailing trailer lines of the footer, footer text: the footer lines before them.
 func ParseCommitMessage(message string) ParsedCommitMessage {
+	return parseCommitMessage(message, partAll)
+}
+
+// parseCommitMessage parses a commit message like ParseCommitMessage, but only
+// populates the given parts. If neither body nor footer is needed, splitting
+// stops after the title section. The Raw message is always populated.
+func parseCommitMessage(message string, parts messageParts) ParsedCommitMessage {
 	// Normalize line endings
 	message = strings.ReplaceAll(message, "\r\n", "\n")
 	message = strings.TrimRight(message, "\n")
 
-	// Split into sections by empty lines
-	sections := splitIntoSections(message)
+	// Split into sections by empty lines, only the first one is needed for the title
+	maxSections := -1
+	if parts&(partBody|partFooter) == 0 {
+		maxSections = 1
+	}
+
+	sections := splitIntoSections(message, maxSections)
 
 	result := ParsedCommitMessage{
-		Raw:    message,
-		Title:  "",
-		Body:   "",
-		Footer: "",
+		Raw:           message,
+		Title:         "",
+		Body:          "",
+		Footer:        "",
+		BodySections:  nil,
+		CCType:        "",
+		CCScope:       "",
+		CCDescription: "",
+		CCBreaking:    false,
+		Trailers:      nil,
+		TrailerBlock:  "",
+		FooterText:    "",
 	}
 
 	if len(sections) == 0 {
@@ -42,40 +134,132 @@ func ParseCommitMessage(message string) ParsedCommitMessage {
 	result.Title = sections[0]
 
 	const twoSections = 2
-	if len(sections) == 1 {
-		// Only title, no body or footer
-		return result
-	}
+	switch {
+	case len(sections) == 1:
+		// Only title, no body or footer (or not needed)
 
-	if len(sections) == twoSections {
+	case len(sections) == twoSections:
 		// Title + Footer (no body)
 		result.Footer = sections[1]
-		return result
+
+	case parts&partBody == 0:
+		// 3+ sections, but only the footer is needed
+		result.Footer = sections[len(sections)-1]
+
+	default:
+		// 3+ sections: Title + Body + Footer
+		result.Footer = sections[len(sections)-1]
+
+		// Body is everything between title and footer
+		bodyParts := sections[1 : len(sections)-1]
+		result.Body = strings.Join(bodyParts, "\n\n")
+		result.BodySections = bodyParts
 	}
 
-	// 3+ sections: Title + Body + Footer
-	result.Footer = sections[len(sections)-1]
+	// Conventional Commits fields are derived from the title and footer
+	if parts&partTitle != 0 {
+		parseConventionalCommit(&result)
+	}
 
-	// Body is everything between title and footer
-	bodyParts := sections[1 : len(sections)-1]
-	result.Body = strings.Join(bodyParts, "\n\n")
+	if parts&partFooter != 0 {
+		result.Trailers = parseTrailers(result.Footer)
+		result.FooterText, result.TrailerBlock = splitFooter(result.Footer)
+	}
 
 	return result
 }
 
-// splitIntoSections splits a message by empty lines into sections.
-func splitIntoSections(message string) []string {
-	lines := strings.Split(message, "\n")
+// parseConventionalCommit populates the Conventional Commits fields of a parsed
+// message from its title (first line) and footer.
+func parseConventionalCommit(result *ParsedCommitMessage) {
+	header, _, _ := strings.Cut(result.Title, "\n")
+
+	match := ccHeaderRegex.FindStringSubmatch(header)
+	if match == nil {
+		return
+	}
+
+	result.CCType = match[1]
+	result.CCScope = match[2]
+	result.CCDescription = match[4]
+	result.CCBreaking = match[3] == "!" || ccBreakingFooterRegex.MatchString(result.Footer)
+}
+
+// parseTrailers extracts the git trailers from the footer. Lines starting with
+// whitespace continue the value of the preceding trailer (folded values) and are
+// joined with a single space. Other lines that are not of the form "Key: value"
+// are ignored.
+func parseTrailers(footer string) []Trailer {
+	var trailers []Trailer
+
+	// folding reports whether a continuation line belongs to the last trailer
+	folding := false
 
+	for line := range strings.SplitSeq(footer, "\n") {
+		if folding && line != "" && (line[0] == ' ' || line[0] == '\t') {
+			last := &trailers[len(trailers)-1]
+			last.Value = strings.TrimSpace(last.Value + " " + strings.TrimSpace(line))
+
+			continue
+		}
+
+		match := trailerRegex.FindStringSubmatch(line)
+		if match == nil {
+			folding = false
+			continue
+		}
+
+		trailers = append(trailers, Trailer{Key: match[1], Value: strings.TrimSpace(match[2])})
+		folding = true
+	}
+
+	return trailers
+}
+
+// splitFooter splits the footer into the freeform text and the trailer block,
+// i.e. the longest run of trailer lines at the end of the footer. Indented lines
+// within the block continue the preceding trailer (folded values).
+func splitFooter(footer string) (text string, trailerBlock string) {
+	lines := strings.Split(footer, "\n")
+
+	// blockStart is the index of the first line of the trailer block
+	blockStart := len(lines)
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := lines[i]
+		if trailerRegex.MatchString(line) {
+			blockStart = i
+			continue
+		}
+
+		// Continuation lines only belong to the block if a trailer precedes them
+		if line != "" && (line[0] == ' ' || line[0] == '\t') {
+			continue
+		}
+
+		break
+	}
+
+	return strings.Join(lines[:blockStart], "\n"), strings.Join(lines[blockStart:], "\n")
+}
+
+// splitIntoSections splits a message by empty lines into sections.
+// At most maxSections sections are returned, a negative value returns all.
+func splitIntoSections(message string, maxSections int) []string {
 	var sections []string
-	currentSection := make([]string, 0, len(lines))
+	var currentSection []string
 
-	for _, line := range lines {
+	// Lines are split lazily, so stopping early skips the rest of the message
+	for line := range strings.SplitSeq(message, "\n") {
 		if isEmptyLine(line) {
 			// Empty line marks section boundary
 			if len(currentSection) > 0 {
 				sections = append(sections, strings.Join(currentSection, "\n"))
 				currentSection = nil
+
+				if len(sections) == maxSections {
+					return sections
+				}
 			}
 
 			continue
@@ -92,6 +276,32 @@ func splitIntoSections(message string) []string {
 	return sections
 }
 
+// sectionStartLines returns the line numbers (1-based) of the first lines of the
+// sections of a message, split like splitIntoSections. Leading and repeated empty
+// lines are counted, so the numbers refer to the lines of the message.
+func sectionStartLines(message string) []int {
+	var starts []int
+
+	inSection := false
+	lineNumber := 0
+
+	for line := range strings.SplitSeq(message, "\n") {
+		lineNumber++
+
+		if isEmptyLine(line) {
+			inSection = false
+			continue
+		}
+
+		if !inSection {
+			starts = append(starts, lineNumber)
+			inSection = true
+		}
+	}
+
+	return starts
+}
+
 func isEmptyLine(line string) bool {
 	return strings.TrimSpace(line) == ""
 }
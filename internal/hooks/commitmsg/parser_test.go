@@ -1,6 +1,7 @@
 package commitmsg_test
 
 import (
+	"slices"
 	"strings"
 	"testing"
 
@@ -150,3 +151,285 @@ func TestParseCommitMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCommitMessageConventionalCommit(t *testing.T) {
+	tests := []struct {
+		name            string
+		message         string
+		wantType        string
+		wantScope       string
+		wantDescription string
+		wantBreaking    bool
+	}{
+		{
+			name:            "type and description",
+			message:         "feat: add login",
+			wantType:        "feat",
+			wantScope:       "",
+			wantDescription: "add login",
+			wantBreaking:    false,
+		},
+		{
+			name:            "type with scope",
+			message:         "fix(parser): handle empty input",
+			wantType:        "fix",
+			wantScope:       "parser",
+			wantDescription: "handle empty input",
+			wantBreaking:    false,
+		},
+		{
+			name:            "breaking marker",
+			message:         "refactor(api)!: drop v1 endpoints",
+			wantType:        "refactor",
+			wantScope:       "api",
+			wantDescription: "drop v1 endpoints",
+			wantBreaking:    true,
+		},
+		{
+			name:            "breaking change footer",
+			message:         "feat: new config format\n\nBREAKING CHANGE: old config files are not supported",
+			wantType:        "feat",
+			wantScope:       "",
+			wantDescription: "new config format",
+			wantBreaking:    true,
+		},
+		{
+			name:            "breaking change footer with hyphen",
+			message:         "feat: new config format\n\nBody text.\n\nBREAKING-CHANGE: old format dropped",
+			wantType:        "feat",
+			wantScope:       "",
+			wantDescription: "new config format",
+			wantBreaking:    true,
+		},
+		{
+			name:            "uppercase type is parsed as is",
+			message:         "Feat: add login",
+			wantType:        "Feat",
+			wantScope:       "",
+			wantDescription: "add login",
+			wantBreaking:    false,
+		},
+		{
+			name:            "not conventional commit shaped",
+			message:         "Add login\n\nBREAKING CHANGE: ignored without header",
+			wantType:        "",
+			wantScope:       "",
+			wantDescription: "",
+			wantBreaking:    false,
+		},
+		{
+			name:            "missing space after colon",
+			message:         "feat:add login",
+			wantType:        "",
+			wantScope:       "",
+			wantDescription: "",
+			wantBreaking:    false,
+		},
+		{
+			name:            "only first title line is considered",
+			message:         "feat: add login\nsecond line",
+			wantType:        "feat",
+			wantScope:       "",
+			wantDescription: "add login",
+			wantBreaking:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := commitmsg.ParseCommitMessage(tt.message)
+
+			if parsed.CCType != tt.wantType {
+				t.Errorf("CCType = %q, want %q", parsed.CCType, tt.wantType)
+			}
+
+			if parsed.CCScope != tt.wantScope {
+				t.Errorf("CCScope = %q, want %q", parsed.CCScope, tt.wantScope)
+			}
+
+			if parsed.CCDescription != tt.wantDescription {
+				t.Errorf("CCDescription = %q, want %q", parsed.CCDescription, tt.wantDescription)
+			}
+
+			if parsed.CCBreaking != tt.wantBreaking {
+				t.Errorf("CCBreaking = %v, want %v", parsed.CCBreaking, tt.wantBreaking)
+			}
+		})
+	}
+}
+
+func TestParseCommitMessageForRules(t *testing.T) {
+	const message = "feat(api)!: add login\n\nFirst para.\n\nSecond para.\n\nRefs: #123"
+
+	tests := []struct {
+		name       string
+		rules      []commitmsg.Rule
+		wantTitle  string
+		wantBody   string
+		wantFooter string
+		wantType   string
+	}{
+		{
+			name:       "title scope skips body and footer",
+			rules:      []commitmsg.Rule{{Type: commitmsg.RuleTypeDeny, Scope: commitmsg.ScopeTitle}},
+			wantTitle:  "feat(api)!: add login",
+			wantBody:   "",
+			wantFooter: "",
+			wantType:   "feat",
+		},
+		{
+			name:       "footer scope skips body",
+			rules:      []commitmsg.Rule{{Type: commitmsg.RuleTypeRequire, Scope: commitmsg.ScopeFooter}},
+			wantTitle:  "feat(api)!: add login",
+			wantBody:   "",
+			wantFooter: "Refs: #123",
+			wantType:   "",
+		},
+		{
+			name: "body and cc scopes",
+			rules: []commitmsg.Rule{
+				{Type: commitmsg.RuleTypeDeny, Scope: commitmsg.ScopeBody},
+				{Type: commitmsg.RuleTypeRequire, Scope: commitmsg.ScopeCCType},
+			},
+			wantTitle:  "feat(api)!: add login",
+			wantBody:   "First para.\n\nSecond para.",
+			wantFooter: "Refs: #123",
+			wantType:   "feat",
+		},
+		{
+			name:       "built-in rule needing footer",
+			rules:      []commitmsg.Rule{{Type: commitmsg.RuleTypeFixReferencesCause}},
+			wantTitle:  "feat(api)!: add login",
+			wantBody:   "",
+			wantFooter: "Refs: #123",
+			wantType:   "feat",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := commitmsg.ParseCommitMessageForRulesForTesting(message, tt.rules)
+
+			if parsed.Raw != message {
+				t.Errorf("Raw = %q, want %q", parsed.Raw, message)
+			}
+
+			if parsed.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", parsed.Title, tt.wantTitle)
+			}
+
+			if parsed.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", parsed.Body, tt.wantBody)
+			}
+
+			if parsed.Footer != tt.wantFooter {
+				t.Errorf("Footer = %q, want %q", parsed.Footer, tt.wantFooter)
+			}
+
+			if parsed.CCType != tt.wantType {
+				t.Errorf("CCType = %q, want %q", parsed.CCType, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestParseCommitMessageTrailers(t *testing.T) {
+	parsed := commitmsg.ParseCommitMessage("feat: add login\n\nBody text.\n\n" +
+		"Co-authored-by: Alice <alice@example.com>\n" +
+		"Not a trailer line\n" +
+		"co-authored-by: Bob <bob@example.com>\n" +
+		"Reviewed-by: Carol\n" +
+		"  <carol@example.com>\n" +
+		"Signed-off-by: Jane Doe <jane@example.com>")
+
+	want := []commitmsg.Trailer{
+		{Key: "Co-authored-by", Value: "Alice <alice@example.com>"},
+		{Key: "co-authored-by", Value: "Bob <bob@example.com>"},
+		{Key: "Reviewed-by", Value: "Carol <carol@example.com>"},
+		{Key: "Signed-off-by", Value: "Jane Doe <jane@example.com>"},
+	}
+
+	if len(parsed.Trailers) != len(want) {
+		t.Fatalf("Trailers = %v, want %v", parsed.Trailers, want)
+	}
+
+	for i := range want {
+		if parsed.Trailers[i] != want[i] {
+			t.Errorf("Trailers[%d] = %v, want %v", i, parsed.Trailers[i], want[i])
+		}
+	}
+
+	coAuthors := parsed.TrailerValues("Co-Authored-By")
+	if len(coAuthors) != 2 || coAuthors[1] != "Bob <bob@example.com>" {
+		t.Errorf("TrailerValues() = %q, want both co-authors", coAuthors)
+	}
+}
+
+func TestParseCommitMessageBodySections(t *testing.T) {
+	parsed := commitmsg.ParseCommitMessage("Add feature\n\nFirst paragraph.\nContinued.\n\n" +
+		"Second paragraph.\n\nFixes #123")
+
+	want := []string{"First paragraph.\nContinued.", "Second paragraph."}
+	if !slices.Equal(parsed.BodySections, want) {
+		t.Errorf("BodySections = %q, want %q", parsed.BodySections, want)
+	}
+
+	if parsed.Body != strings.Join(want, "\n\n") {
+		t.Errorf("Body = %q, want the body sections joined by an empty line", parsed.Body)
+	}
+}
+
+func TestParseCommitMessageTrailerBlock(t *testing.T) {
+	tests := []struct {
+		name             string
+		message          string
+		wantFooterText   string
+		wantTrailerBlock string
+	}{
+		{
+			name:             "trailers only",
+			message:          "feat: add login\n\nRefs: #123\nSigned-off-by: Jane Doe <jane@example.com>",
+			wantFooterText:   "",
+			wantTrailerBlock: "Refs: #123\nSigned-off-by: Jane Doe <jane@example.com>",
+		},
+		{
+			name: "freeform text followed by trailers",
+			message: "feat: add login\n\nThe old form is kept for now,\nsee the migration guide.\n" +
+				"Reviewed-by: Carol\n  <carol@example.com>\nRefs: #123",
+			wantFooterText:   "The old form is kept for now,\nsee the migration guide.",
+			wantTrailerBlock: "Reviewed-by: Carol\n  <carol@example.com>\nRefs: #123",
+		},
+		{
+			name:             "entirely freeform footer",
+			message:          "feat: add login\n\nRefs: #123\nThanks to everyone who tested it.",
+			wantFooterText:   "Refs: #123\nThanks to everyone who tested it.",
+			wantTrailerBlock: "",
+		},
+		{
+			name:             "indented line without preceding trailer",
+			message:          "feat: add login\n\nSee below\n  indented note",
+			wantFooterText:   "See below\n  indented note",
+			wantTrailerBlock: "",
+		},
+		{
+			name:             "no footer",
+			message:          "feat: add login",
+			wantFooterText:   "",
+			wantTrailerBlock: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := commitmsg.ParseCommitMessage(tt.message)
+
+			if parsed.FooterText != tt.wantFooterText {
+				t.Errorf("FooterText = %q, want %q", parsed.FooterText, tt.wantFooterText)
+			}
+
+			if parsed.TrailerBlock != tt.wantTrailerBlock {
+				t.Errorf("TrailerBlock = %q, want %q", parsed.TrailerBlock, tt.wantTrailerBlock)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package commitmsg
+
+import (
+	"fmt"
+)
+
+// Names of the built-in rule presets, which configs refer to in extends.
+const (
+	// PresetConventionalCommits requires Conventional Commits titles.
+	PresetConventionalCommits = "conventional-commits"
+	// PresetSignedOff requires a Signed-off-by trailer (Developer Certificate of Origin).
+	PresetSignedOff = "signed-off"
+	// PresetNoWIP denies work in progress commits.
+	PresetNoWIP = "no-wip"
+)
+
+// presetRules returns the rules of the built-in preset name and reports whether
+// the preset exists. A new slice is returned on every call, so callers may modify it.
+func presetRules(name string) ([]Rule, bool) {
+	switch name {
+	case PresetConventionalCommits:
+		return []Rule{
+			presetRule(
+				"conventional-commits",
+				RuleTypeRequire,
+				ScopeTitle,
+				`^(?:build|chore|ci|docs|feat|fix|perf|refactor|revert|style|test)(?:\([\w./-]+\))?!?: \S`,
+				"Use Conventional Commits format (e.g., 'feat: add feature')",
+			),
+		}, true
+
+	case PresetSignedOff:
+		rule := presetRule(
+			"signed-off",
+			RuleTypeRequire,
+			ScopeTrailer,
+			`^.+ <[^<>\s]+@[^<>\s]+>$`,
+			"Commits must be signed off (git commit --signoff)",
+		)
+		rule.TrailerKey = "Signed-off-by"
+
+		return []Rule{rule}, true
+
+	case PresetNoWIP:
+		return []Rule{
+			presetRule(
+				"no-wip",
+				RuleTypeDeny,
+				ScopeTitle,
+				`(?i)(?:^|[\s\(\)])(wip)(?:[\s\(\):]|$)`,
+				"WIP commits are not allowed",
+			),
+		}, true
+
+	default:
+		return nil, false
+	}
+}
+
+// presetRule creates an error-level rule of a preset.
+func presetRule(name string, ruleType RuleType, scope Scope, pattern string, message string) Rule {
+	var rule Rule
+	rule.Name = name
+	rule.Type = ruleType
+	rule.Scope = scope
+	rule.Pattern = pattern
+	rule.Message = message
+	rule.Severity = SeverityError
+
+	return rule
+}
+
+// expandExtends prepends the rules of the presets listed in the extends key of
+// config to its rules, in the listed order.
+func expandExtends(config *Config) error {
+	if len(config.Extends) == 0 {
+		return nil
+	}
+
+	var rules []Rule
+	for i, name := range config.Extends {
+		preset, ok := presetRules(name)
+		if !ok {
+			return fmt.Errorf(
+				"extends[%d]: unknown preset %q: must be %q, %q, or %q",
+				i, name, PresetConventionalCommits, PresetSignedOff, PresetNoWIP,
+			)
+		}
+
+		rules = append(rules, preset...)
+	}
+
+	config.Rules = append(rules, config.Rules...)
+
+	return nil
+}
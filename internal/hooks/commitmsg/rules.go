@@ -1,58 +1,457 @@
 package commitmsg
 
 import (
+	"fmt"
 	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 // RuleViolation represents a failed rule check.
 type RuleViolation struct {
-	Rule    Rule
-	Matched bool // For deny rules: true means pattern matched (violation)
+	Rule     Rule
+	Severity Severity
+	Matched  bool // For deny rules: true means pattern matched (violation)
 	// For require rules: false means pattern didn't match (violation)
+
+	// Detail describes the violation of a built-in rule type (empty for deny and require rules).
+	Detail string
+
+	// MatchedText is the text matched by a violated deny rule and MatchOffset its
+	// byte offset within the scope (empty and zero for other rules).
+	MatchedText string
+	MatchOffset int
+
+	// MatchedPattern is the pattern of a violated deny or require rule that caused
+	// the violation, i.e. the matching pattern of a deny rule or the pattern not
+	// found by a require rule (empty for other rules).
+	MatchedPattern string
 }
 
+// ruleContext provides information beyond the commit message that built-in rule
+// types may need. Fields are zero when the information is not available, e.g.
+// there is no commit object yet in commit-msg hook mode.
+type ruleContext struct {
+	repo   *git.Repository
+	commit *object.Commit
+	// branch is the short name of the branch the commit is validated for.
+	branch string
+	// targetBranch is the short name of the branch the commit is pushed to.
+	targetBranch string
+	// intermediate is set for the commits of a range except the newest one, which
+	// skips the rules with first_commit_only.
+	intermediate bool
+	// skippedRules are the names of the rules disabled by Lint-Skip trailers.
+	skippedRules []string
+}
+
+// lintSkipTrailerKey is the key of the trailers naming the rules to skip for a
+// commit, if the allow_skip_trailer setting is enabled.
+const lintSkipTrailerKey = "Lint-Skip"
+
 // EvaluateRules evaluates all rules against a parsed commit message.
 // Returns a slice of violations (empty if all rules pass).
 func EvaluateRules(rules []Rule, message ParsedCommitMessage) []RuleViolation {
+	return evaluateRules(rules, message, ruleContext{}, false)
+}
+
+// EvaluateRulesFailFast evaluates rules against a parsed commit message like
+// EvaluateRules, but stops at the first error-level violation. Warnings found
+// before it are returned as well.
+func EvaluateRulesFailFast(rules []Rule, message ParsedCommitMessage) []RuleViolation {
+	return evaluateRules(rules, message, ruleContext{}, true)
+}
+
+// Lint parses a commit message and evaluates the rules of config against it,
+// respecting the fail_fast and allow_skip_trailer settings.
+// No repository or commit is involved, so built-in rules depending on them are
+// skipped.
+func Lint(config *Config, message string) []RuleViolation {
+	parsed := ParseCommitMessage(message)
+
+	var ctx ruleContext
+	if isEnabled(config.Settings.AllowSkipTrailer) {
+		ctx.skippedRules = skippedRuleNames(parsed)
+	}
+
+	return evaluateRules(config.Rules, parsed, ctx, isEnabled(config.Settings.FailFast))
+}
+
+// LintResult is the result of linting a single commit message with LintMessages.
+type LintResult struct {
+	// Message is the linted commit message.
+	Message string
+	// Skipped reports whether the message was excluded from the validation by the
+	// skip_fixup_commits or skip_subjects settings.
+	Skipped bool
+	// Violations are the violated rules (empty if all rules pass).
+	Violations []RuleViolation
+}
+
+// Failed reports whether the message violates any error-level rule.
+func (r LintResult) Failed() bool {
+	return hasErrors(r.Violations)
+}
+
+// LintMessages lints each of the raw commit messages like Lint and returns a
+// result per message, in the given order. Messages excluded by the
+// skip_fixup_commits or skip_subjects settings are not linted and reported as
+// skipped. Like Lint, it is independent of any repository and I/O, e.g. for
+// embedding the linter in other tools.
+func LintMessages(config *Config, messages []string) []LintResult {
+	results := make([]LintResult, 0, len(messages))
+
+	for _, message := range messages {
+		result := LintResult{Message: message, Skipped: false, Violations: nil}
+
+		if (isEnabled(config.Settings.SkipFixupCommits) && isFixupCommit(message)) ||
+			shouldSkipSubject(message, config.Settings.skipSubjectRegexes) {
+			result.Skipped = true
+		} else {
+			result.Violations = Lint(config, message)
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// skippedRuleNames returns the names of the rules listed in the Lint-Skip
+// trailers of message. A trailer may list several names separated by commas.
+func skippedRuleNames(message ParsedCommitMessage) []string {
+	var names []string
+
+	for _, value := range message.TrailerValues(lintSkipTrailerKey) {
+		for name := range strings.SplitSeq(value, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// evaluateRules evaluates all rules against a parsed commit message using the
+// additional information from ctx. With failFast set, the evaluation stops at the
+// first error-level violation.
+func evaluateRules(rules []Rule, message ParsedCommitMessage, ctx ruleContext, failFast bool) []RuleViolation {
 	var violations []RuleViolation
 
 	for _, rule := range rules {
+		if !ruleApplies(rule, message) || (rule.FirstCommitOnly && ctx.intermediate) ||
+			slices.Contains(ctx.skippedRules, rule.Name) {
+			continue
+		}
+
+		violation, violated := checkRule(rule, message, ctx)
+		if !violated {
+			continue
+		}
+
+		violations = append(violations, violation)
+
+		if failFast && violation.Severity == SeverityError {
+			break
+		}
+	}
+
+	return violations
+}
+
+// ruleApplies reports whether a rule applies to the commit message based on the
+// Conventional Commits type of the message and the applies_to types of the rule.
+// Messages without a Conventional Commits type only match rules without applies_to.
+func ruleApplies(rule Rule, message ParsedCommitMessage) bool {
+	if len(rule.AppliesTo) == 0 {
+		return true
+	}
+
+	for _, ccType := range rule.AppliesTo {
+		if strings.EqualFold(ccType, message.CCType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkRule evaluates a single rule and reports whether it is violated.
+func checkRule(rule Rule, message ParsedCommitMessage, ctx ruleContext) (RuleViolation, bool) {
+	violation := RuleViolation{
+		Rule:     rule,
+		Severity: rule.Severity,
+		Matched:  false,
+		Detail:   "",
+
+		MatchedText:    "",
+		MatchOffset:    0,
+		MatchedPattern: "",
+	}
+
+	switch rule.Type {
+	case RuleTypeDeny, RuleTypeRequire:
+		// Require rules are satisfied by the branch name if it matches the branch ticket pattern
+		if rule.branchTicketRegex != nil && ctx.branch != "" && rule.branchTicketRegex.MatchString(ctx.branch) {
+			return violation, false
+		}
+
+		// Body line rules check each line separately
+		if rule.Scope == ScopeBodyLine {
+			violation.Detail = checkBodyLines(rule, message)
+			violation.Matched = rule.Type == RuleTypeDeny && violation.Detail != ""
+
+			return violation, violation.Detail != ""
+		}
+
+		// Body section rules check each paragraph separately
+		if rule.Scope == ScopeBodySection {
+			violation.Detail = checkBodySections(rule, message)
+			violation.Matched = rule.Type == RuleTypeDeny && violation.Detail != ""
+
+			return violation, violation.Detail != ""
+		}
+
+		// Trailer rules check each value of the trailer key separately
+		if rule.Scope == ScopeTrailer {
+			violation.Detail = checkTrailerValues(rule, message)
+			violation.Matched = rule.Type == RuleTypeDeny && violation.Detail != ""
+
+			return violation, violation.Detail != ""
+		}
+
 		// Get the text to check based on scope
 		text := getTextForScope(rule.Scope, message)
 
-		// Use cached regex
-		matched := rule.regex.MatchString(text)
+		// Use cached regexes, exceptions allow the text matched by a deny rule
+		matched, pattern, loc := matchPatterns(rule, text)
+		matched = matched && !matchesException(rule, text)
+		violation.Matched = matched
+		violation.MatchedPattern = pattern
+
+		if matched && rule.Type == RuleTypeDeny {
+			violation.MatchedText = text[loc[0]:loc[1]]
+			violation.MatchOffset = loc[0]
+		}
+
+		// Deny rules are violated if the pattern matches, require rules if it does not
+		return violation, (rule.Type == RuleTypeDeny) == matched
+
+	case RuleTypeFixReferencesCause:
+		violation.Detail = checkFixReferencesCause(rule, message, ctx)
+
+	case RuleTypeAuthorEmailAllowlist:
+		violation.Detail = checkAuthorEmailAllowlist(rule, ctx)
+
+	case RuleTypeDCO:
+		violation.Detail = checkDCO(rule, message, ctx)
+
+	case RuleTypeNoMergeCommits:
+		violation.Detail = checkNoMergeCommits(ctx)
+
+	case RuleTypeCCLowercase:
+		violation.Detail = checkCCLowercase(message)
+
+	case RuleTypeNoRepeatedWords:
+		violation.Detail = checkNoRepeatedWords(rule, message)
+
+	case RuleTypeRevertRequiresApproval:
+		violation.Detail = checkRevertRequiresApproval(rule, message, ctx)
+
+	case RuleTypeRequireTrailer:
+		violation.Detail = checkRequireTrailer(rule, message)
+
+	case RuleTypeMaxTrailerRepeats:
+		violation.Detail = checkMaxTrailerRepeats(rule, message)
+
+	case RuleTypeMinLength:
+		violation.Detail = checkMinLength(rule, message)
+
+	case RuleTypeMaxWords:
+		violation.Detail = checkMaxWords(rule, message)
+
+	case RuleTypeMaxBodyLines:
+		violation.Detail = checkMaxBodyLines(rule, message)
+
+	case RuleTypeMinWords:
+		violation.Detail = checkMinWords(rule, message)
+
+	case RuleTypeRequireBodyWhen:
+		violation.Detail = checkRequireBodyWhen(rule, message)
+
+	case RuleTypeImperativeSubject:
+		violation.Detail = checkImperativeSubject(rule, message)
+
+	case RuleTypeNoTrailingPunctuation:
+		violation.Detail = checkNoTrailingPunctuation(rule, message)
+
+	case RuleTypeRequireIssueRef:
+		violation.Detail = checkRequireIssueRef(rule, message)
+
+	case RuleTypeCharset:
+		violation.Detail = checkCharset(rule, message)
+
+	case RuleTypeBodyWrap:
+		violation.Detail = checkBodyWrap(rule, message)
+
+	case RuleTypeBlankLineAfterTitle:
+		violation.Detail = checkBlankLineAfterTitle(message)
+
+	case RuleTypeNoTrailingWhitespace:
+		violation.Detail = checkNoTrailingWhitespace(rule, message)
+
+	case RuleTypeTemplateMatch:
+		violation.Detail = checkTemplateMatch(rule, message)
+
+	case RuleTypeNoQuestionSubject:
+		violation.Detail = checkNoQuestionSubject(rule, message)
+
+	case RuleTypeSubjectNotBranchName:
+		violation.Detail = checkSubjectNotBranchName(rule, message, ctx)
+
+	default:
+		return violation, false
+	}
+
+	return violation, violation.Detail != ""
+}
+
+// checkBodyLines evaluates a deny or require rule against each non-empty body
+// line. Returns a description of the first violating line or an empty string.
+func checkBodyLines(rule Rule, message ParsedCommitMessage) string {
+	lineNum := 0
+	for line := range strings.SplitSeq(message.Body, "\n") {
+		lineNum++
+		if isEmptyLine(line) {
+			continue
+		}
+
+		matched, pattern, _ := matchPatterns(rule, line)
 
-		// Check if rule is violated
-		violated := false
-		if rule.Type == RuleTypeDeny && matched {
-			violated = true
+		if rule.Type == RuleTypeDeny && matched && !matchesException(rule, line) {
+			return fmt.Sprintf("Pattern %q was found in body line %d: %q (deny rule)", pattern, lineNum, line)
 		}
 
 		if rule.Type == RuleTypeRequire && !matched {
-			violated = true
+			return fmt.Sprintf("Pattern %q was not found in body line %d: %q (require rule)", pattern, lineNum, line)
 		}
+	}
+
+	return ""
+}
 
-		if violated {
-			violations = append(violations, RuleViolation{
-				Rule:    rule,
-				Matched: matched,
-			})
+// checkBodySections evaluates a deny or require rule against each paragraph of the
+// body separately. Returns a description of the first violation or an empty
+// string.
+func checkBodySections(rule Rule, message ParsedCommitMessage) string {
+	for i, section := range message.BodySections {
+		matched, pattern, _ := matchPatterns(rule, section)
+
+		if rule.Type == RuleTypeDeny && matched && !matchesException(rule, section) {
+			return fmt.Sprintf("Pattern %q was found in body section %d: %q (deny rule)", pattern, i+1, section)
+		}
+
+		if rule.Type == RuleTypeRequire && !matched {
+			return fmt.Sprintf("Pattern %q was not found in body section %d: %q (require rule)", pattern, i+1, section)
 		}
 	}
 
-	return violations
+	return ""
 }
 
-// shouldSkipAuthor checks if a commit author should be skipped based on patterns.
-func shouldSkipAuthor(name string, email string, patterns []string) bool {
-	for _, pattern := range patterns {
-		re, err := regexp.Compile(pattern)
-		if err != nil {
-			// Invalid pattern, skip it
-			continue
+// checkTrailerValues evaluates a deny or require rule against each value of the
+// trailers with the rule's trailer key. A require rule is also violated if there
+// is no such trailer. Returns a description of the first violation or an empty
+// string.
+func checkTrailerValues(rule Rule, message ParsedCommitMessage) string {
+	values := message.TrailerValues(rule.TrailerKey)
+
+	if rule.Type == RuleTypeRequire && len(values) == 0 {
+		return fmt.Sprintf("Trailer %q was not found in footer (require rule)", rule.TrailerKey)
+	}
+
+	for _, value := range values {
+		matched, pattern, _ := matchPatterns(rule, value)
+
+		if rule.Type == RuleTypeDeny && matched && !matchesException(rule, value) {
+			return fmt.Sprintf("Pattern %q was found in trailer %s: %q (deny rule)", pattern, rule.TrailerKey, value)
+		}
+
+		if rule.Type == RuleTypeRequire && !matched {
+			return fmt.Sprintf("Pattern %q was not found in trailer %s: %q (require rule)", pattern, rule.TrailerKey, value)
+		}
+	}
+
+	return ""
+}
+
+// matchPatterns matches the patterns of a deny or require rule against text
+// according to the match mode of the rule. Besides whether the patterns match, it
+// returns the pattern deciding the result with the location of its match: the
+// first matching pattern if they match, otherwise the first pattern not matching
+// (match mode "all") or the alternation of all patterns (match mode "any").
+func matchPatterns(rule Rule, text string) (bool, string, []int) {
+	patterns := rulePatterns(rule)
+
+	if rule.Match == matchAll {
+		var first []int
+
+		for i, re := range rule.patternRegexes {
+			loc := re.FindStringIndex(text)
+			if loc == nil {
+				return false, patterns[i], nil
+			}
+
+			if first == nil {
+				first = loc
+			}
 		}
 
+		return true, patterns[0], first
+	}
+
+	for i, re := range rule.patternRegexes {
+		loc := re.FindStringIndex(text)
+		if loc != nil {
+			return true, patterns[i], loc
+		}
+	}
+
+	return false, strings.Join(patterns, "|"), nil
+}
+
+// matchesException reports whether any of the exception patterns of a rule
+// matches text.
+func matchesException(rule Rule, text string) bool {
+	for _, re := range rule.exceptionRegexes {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasErrors reports whether any of the violations has error severity.
+func hasErrors(violations []RuleViolation) bool {
+	for _, v := range violations {
+		if v.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shouldSkipAuthor checks if a commit author (or committer) should be skipped
+// based on the compiled skip_authors (or skip_committers) patterns.
+func shouldSkipAuthor(name string, email string, patterns []*regexp.Regexp) bool {
+	for _, re := range patterns {
 		// Check if pattern matches either name or email
 		if re.MatchString(name) || re.MatchString(email) {
 			return true
@@ -62,21 +461,136 @@ func shouldSkipAuthor(name string, email string, patterns []string) bool {
 	return false
 }
 
+// shouldSkipSubject checks if a commit should be skipped based on the compiled
+// skip_subjects patterns matched against its subject (the first line of the
+// commit message).
+func shouldSkipSubject(message string, patterns []*regexp.Regexp) bool {
+	subject, _, _ := strings.Cut(strings.TrimLeft(message, "\n"), "\n")
+
+	for _, re := range patterns {
+		if re.MatchString(subject) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isFixupCommit reports whether the subject of a commit message starts with the
+// "fixup!" or "squash!" prefix of commits created for git rebase --autosquash.
+func isFixupCommit(message string) bool {
+	subject := strings.TrimLeft(message, "\n")
+
+	return strings.HasPrefix(subject, "fixup!") || strings.HasPrefix(subject, "squash!")
+}
+
 func getTextForScope(scope Scope, message ParsedCommitMessage) string {
 	switch scope {
 	case ScopeTitle:
 		return message.Title
 
-	case ScopeBody:
+	case ScopeBody, ScopeBodyLine, ScopeBodySection:
 		return message.Body
 
-	case ScopeFooter:
+	case ScopeFooter, ScopeTrailer:
 		return message.Footer
 
+	case ScopeTrailerBlock:
+		return message.TrailerBlock
+
+	case ScopeFooterText:
+		return message.FooterText
+
 	case ScopeMessage:
 		return message.Raw
 
+	case ScopeTitleAndBody:
+		if message.Body == "" {
+			return message.Title
+		}
+
+		return message.Title + "\n\n" + message.Body
+
+	case ScopeCCType:
+		return message.CCType
+
+	case ScopeCCScope:
+		return message.CCScope
+
+	case ScopeCCDescription:
+		return message.CCDescription
+
 	default:
 		return ""
 	}
 }
+
+// messagePartsForRules returns the commit message parts the rules depend on,
+// so parsing can skip the sections no rule looks at.
+func messagePartsForRules(rules []Rule) messageParts {
+	var parts messageParts
+
+	for _, rule := range rules {
+		// The Conventional Commits type is needed to decide whether the rule applies
+		if len(rule.AppliesTo) > 0 {
+			parts |= partTitle
+		}
+
+		switch rule.Type {
+		case RuleTypeDeny, RuleTypeRequire, RuleTypeNoRepeatedWords, RuleTypeMinLength, RuleTypeMaxWords,
+			RuleTypeMinWords:
+			parts |= messagePartsForScope(rule.Scope)
+
+		case RuleTypeFixReferencesCause, RuleTypeRevertRequiresApproval, RuleTypeRequireTrailer:
+			parts |= partTitle | partFooter
+
+		case RuleTypeRequireBodyWhen:
+			parts |= partAll
+
+		case RuleTypeMaxBodyLines, RuleTypeBodyWrap:
+			parts |= partBody
+
+		case RuleTypeMaxTrailerRepeats, RuleTypeDCO:
+			parts |= partFooter
+
+		case RuleTypeRequireIssueRef, RuleTypeCharset:
+			parts |= messagePartsForScope(rule.Scope)
+
+		case RuleTypeNoQuestionSubject, RuleTypeSubjectNotBranchName, RuleTypeImperativeSubject,
+			RuleTypeNoTrailingPunctuation, RuleTypeCCLowercase:
+			parts |= partTitle
+
+		case RuleTypeAuthorEmailAllowlist, RuleTypeTemplateMatch, RuleTypeBlankLineAfterTitle,
+			RuleTypeNoMergeCommits, RuleTypeNoTrailingWhitespace:
+
+		default:
+			parts |= partAll
+		}
+	}
+
+	return parts
+}
+
+// messagePartsForScope returns the commit message parts needed to evaluate a scope.
+func messagePartsForScope(scope Scope) messageParts {
+	switch scope {
+	case ScopeTitle, ScopeCCType, ScopeCCScope, ScopeCCDescription:
+		return partTitle
+
+	case ScopeBody, ScopeBodyLine, ScopeBodySection:
+		return partBody
+
+	case ScopeFooter, ScopeTrailer, ScopeTrailerBlock, ScopeFooterText:
+		return partFooter
+
+	case ScopeTitleAndBody:
+		return partTitle | partBody
+
+	case ScopeMessage:
+		// The raw message is always populated
+		return 0
+
+	default:
+		return partAll
+	}
+}
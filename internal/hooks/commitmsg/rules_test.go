@@ -3,6 +3,7 @@ package commitmsg_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/breml/githooks/internal/hooks/commitmsg"
@@ -384,6 +385,1279 @@ func TestEvaluateRules(t *testing.T) {
 			},
 			wantViolations: 0,
 		},
+		{
+			name: "cc_type scope - allowed type",
+			configYAML: `rules:
+  - name: allowed-types
+    type: require
+    scope: cc_type
+    pattern: '^(feat|fix|chore)$'
+`,
+			message:        commitmsg.ParseCommitMessage("feat(api): add endpoint"),
+			wantViolations: 0,
+		},
+		{
+			name: "cc_type scope - disallowed type",
+			configYAML: `rules:
+  - name: allowed-types
+    type: require
+    scope: cc_type
+    pattern: '^(feat|fix|chore)$'
+`,
+			message:        commitmsg.ParseCommitMessage("style: reformat"),
+			wantViolations: 1,
+		},
+		{
+			name: "cc_scope scope - scope required",
+			configYAML: `rules:
+  - name: require-scope
+    type: require
+    scope: cc_scope
+    pattern: '.+'
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add endpoint"),
+			wantViolations: 1,
+		},
+		{
+			name: "cc_description scope - lowercase description",
+			configYAML: `rules:
+  - name: lowercase-description
+    type: deny
+    scope: cc_description
+    pattern: '^[A-Z]'
+`,
+			message:        commitmsg.ParseCommitMessage("feat(api): Add endpoint"),
+			wantViolations: 1,
+		},
+		{
+			name: "applies_to - matching type is checked",
+			configYAML: `rules:
+  - name: require-feat-scope
+    type: require
+    scope: cc_scope
+    pattern: '.+'
+    applies_to: [feat]
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add endpoint"),
+			wantViolations: 1,
+		},
+		{
+			name: "applies_to - other type is skipped",
+			configYAML: `rules:
+  - name: require-feat-scope
+    type: require
+    scope: cc_scope
+    pattern: '.+'
+    applies_to: [feat]
+`,
+			message:        commitmsg.ParseCommitMessage("chore: bump dependencies"),
+			wantViolations: 0,
+		},
+		{
+			name: "applies_to - non conventional commit is skipped",
+			configYAML: `rules:
+  - name: require-feat-scope
+    type: require
+    scope: cc_scope
+    pattern: '.+'
+    applies_to: [feat, fix]
+`,
+			message:        commitmsg.ParseCommitMessage("Add endpoint"),
+			wantViolations: 0,
+		},
+		{
+			name: "no_repeated_words - repeated word in title",
+			configYAML: `rules:
+  - name: no-repeated-words
+    type: no_repeated_words
+`,
+			message:        commitmsg.ParseCommitMessage("Fix fix the bug"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				if !strings.Contains(violations[0].Detail, `"fix"`) {
+					t.Errorf("expected detail to report the repeated word, got %q", violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "no_repeated_words - no repeated word",
+			configYAML: `rules:
+  - name: no-repeated-words
+    type: no_repeated_words
+`,
+			message:        commitmsg.ParseCommitMessage("Fix the bug"),
+			wantViolations: 0,
+		},
+		{
+			name: "no_repeated_words - punctuation around words is ignored",
+			configYAML: `rules:
+  - name: no-repeated-words
+    type: no_repeated_words
+`,
+			message:        commitmsg.ParseCommitMessage("fix(parser): handle (the the) edge case"),
+			wantViolations: 1,
+		},
+		{
+			name: "no_repeated_words - sentence boundary is not a repetition",
+			configYAML: `rules:
+  - name: no-repeated-words
+    type: no_repeated_words
+    scope: body
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature\n\nIt is done. Done means tested.\n\nRefs: #1"),
+			wantViolations: 0,
+		},
+		{
+			name: "no_repeated_words - repeated word across lines in body",
+			configYAML: `rules:
+  - name: no-repeated-words
+    type: no_repeated_words
+    scope: body
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature\n\nThis adds the\nthe feature.\n\nRefs: #1"),
+			wantViolations: 1,
+		},
+		{
+			name: "body_line scope - long line denied",
+			configYAML: `rules:
+  - name: body-line-length
+    type: deny
+    scope: body_line
+    pattern: '^.{73,}'
+`,
+			message: commitmsg.ParseCommitMessage(
+				"Add feature\n\nShort line.\n\n" + strings.Repeat("x", 80) + "\n\nRefs: #1",
+			),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				if !strings.Contains(violations[0].Detail, "body line 3") {
+					t.Errorf("expected detail to report the violating line, got %q", violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "body_line scope - short lines pass",
+			configYAML: `rules:
+  - name: body-line-length
+    type: deny
+    scope: body_line
+    pattern: '^.{73,}'
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature\n\nShort line.\nAnother short line.\n\nRefs: #1"),
+			wantViolations: 0,
+		},
+		{
+			name: "body_line scope - every bullet must be capitalized",
+			configYAML: `rules:
+  - name: capitalized-bullets
+    type: require
+    scope: body_line
+    pattern: '^- [A-Z]'
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature\n\n- First change\n- second change\n\nRefs: #1"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				if !strings.Contains(violations[0].Detail, `"- second change"`) {
+					t.Errorf("expected detail to report the first violating line, got %q", violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "body_line scope - empty body has no lines to check",
+			configYAML: `rules:
+  - name: capitalized-bullets
+    type: require
+    scope: body_line
+    pattern: '^- [A-Z]'
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature"),
+			wantViolations: 0,
+		},
+		{
+			name: "max_trailer_repeats - too many co-authors",
+			configYAML: `rules:
+  - name: max-trailers
+    type: max_trailer_repeats
+    limit: 5
+`,
+			message: commitmsg.ParseCommitMessage("feat: add feature\n\n" +
+				strings.Repeat("Co-authored-by: Dev <dev@example.com>\n", 6) +
+				"Signed-off-by: Jane <jane@example.com>"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `Trailer "Co-authored-by" occurs 6 times`
+				if !strings.Contains(violations[0].Detail, want) {
+					t.Errorf("expected detail to contain %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "max_trailer_repeats - within limit",
+			configYAML: `rules:
+  - name: max-trailers
+    type: max_trailer_repeats
+    limit: 5
+`,
+			message: commitmsg.ParseCommitMessage("feat: add feature\n\n" +
+				strings.Repeat("Co-authored-by: Dev <dev@example.com>\n", 5)),
+			wantViolations: 0,
+		},
+		{
+			name: "template_match - all sections filled",
+			configYAML: `rules:
+  - name: pr-template
+    type: template_match
+    template: |
+      ## Summary
+      {{summary}}
+      ## Testing
+      {{testing}}
+`,
+			message: commitmsg.ParseCommitMessage("feat: add feature\n\n## Summary\nAdds the feature.\n\n" +
+				"## Testing\nUnit tests."),
+			wantViolations: 0,
+		},
+		{
+			name: "template_match - testing section missing",
+			configYAML: `rules:
+  - name: pr-template
+    type: template_match
+    template: |
+      ## Summary
+      {{summary}}
+      ## Testing
+      {{testing}}
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add feature\n\n## Summary\nAdds the feature."),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `Section header "## Testing" is missing`
+				if !strings.Contains(violations[0].Detail, want) {
+					t.Errorf("expected detail to contain %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "template_match - summary section empty",
+			configYAML: `rules:
+  - name: pr-template
+    type: template_match
+    template: |
+      ## Summary
+      {{summary}}
+      ## Testing
+      {{testing}}
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add feature\n\n## Summary\n\n## Testing\nUnit tests."),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `Section "## Summary" has no content for {{summary}}`
+				if !strings.Contains(violations[0].Detail, want) {
+					t.Errorf("expected detail to contain %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "trailer scope - co-author value is not an email",
+			configYAML: `rules:
+  - name: co-author-email
+    type: require
+    scope: trailer
+    trailer_key: Co-authored-by
+    pattern: '^.+ <[^@\s]+@[^@\s]+>$'
+`,
+			message: commitmsg.ParseCommitMessage("feat: add feature\n\n" +
+				"Co-authored-by: Alice <alice@example.com>\n" +
+				"Co-authored-by: Bob"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `in trailer Co-authored-by: "Bob"`
+				if !strings.Contains(violations[0].Detail, want) {
+					t.Errorf("expected detail to contain %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "trailer scope - folded value matches",
+			configYAML: `rules:
+  - name: co-author-email
+    type: require
+    scope: trailer
+    trailer_key: Co-authored-by
+    pattern: '^.+ <[^@\s]+@[^@\s]+>$'
+`,
+			message: commitmsg.ParseCommitMessage("feat: add feature\n\n" +
+				"Co-authored-by: Alice\n <alice@example.com>"),
+			wantViolations: 0,
+		},
+		{
+			name: "trailer scope - required trailer missing",
+			configYAML: `rules:
+  - name: require-signoff
+    type: require
+    scope: trailer
+    trailer_key: Signed-off-by
+    pattern: '.+'
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add feature\n\nRefs: #123"),
+			wantViolations: 1,
+		},
+		{
+			name: "trailer scope - deny matches any occurrence",
+			configYAML: `rules:
+  - name: no-bot-co-authors
+    type: deny
+    scope: trailer
+    trailer_key: Co-authored-by
+    pattern: '\[bot\]'
+`,
+			message: commitmsg.ParseCommitMessage("feat: add feature\n\n" +
+				"Co-authored-by: Alice <alice@example.com>\n" +
+				"Co-authored-by: renovate[bot] <bot@example.com>"),
+			wantViolations: 1,
+		},
+		{
+			name: "no_question_subject - question subject",
+			configYAML: `rules:
+  - name: no-questions
+    type: no_question_subject
+`,
+			message:        commitmsg.ParseCommitMessage("Why is this broken?\n\nSome details."),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `Title "Why is this broken?" is phrased as a question`
+				if !strings.Contains(violations[0].Detail, want) {
+					t.Errorf("expected detail to contain %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "no_question_subject - declarative subject",
+			configYAML: `rules:
+  - name: no-questions
+    type: no_question_subject
+`,
+			message:        commitmsg.ParseCommitMessage("Fix crash when config is missing"),
+			wantViolations: 0,
+		},
+		{
+			name: "no_question_subject - custom pattern",
+			configYAML: `rules:
+  - name: no-questions
+    type: no_question_subject
+    pattern: '(?i)^(why|how)\b'
+`,
+			message:        commitmsg.ParseCommitMessage("How to handle missing config"),
+			wantViolations: 1,
+		},
+		{
+			name: "ignore_case - matches mixed-case input",
+			configYAML: `rules:
+  - name: prevent-wip
+    type: deny
+    scope: title
+    pattern: '\bwip\b'
+    ignore_case: true
+`,
+			message:        commitmsg.ParseCommitMessage("WiP: debugging"),
+			wantViolations: 1,
+		},
+		{
+			name: "ignore_case - case-sensitive by default",
+			configYAML: `rules:
+  - name: prevent-wip
+    type: deny
+    scope: title
+    pattern: '\bwip\b'
+`,
+			message:        commitmsg.ParseCommitMessage("WiP: debugging"),
+			wantViolations: 0,
+		},
+		{
+			name: "ignore_case - pattern with inline flags",
+			configYAML: `rules:
+  - name: prevent-wip
+    type: deny
+    scope: message
+    pattern: '(?im)^wip$'
+    ignore_case: true
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add feature\n\nWIP"),
+			wantViolations: 1,
+		},
+		{
+			name: "exceptions - all-caps subject denied",
+			configYAML: `rules:
+  - name: no-all-caps
+    type: deny
+    scope: title
+    pattern: '^[^a-z]+$'
+    exceptions:
+      - '^(API|CLI|HTTP): '
+`,
+			message:        commitmsg.ParseCommitMessage("FIX THE BUILD"),
+			wantViolations: 1,
+		},
+		{
+			name: "exceptions - acronym prefix allowed",
+			configYAML: `rules:
+  - name: no-all-caps
+    type: deny
+    scope: title
+    pattern: '^[^a-z]+$'
+    exceptions:
+      - '^(API|CLI|HTTP): '
+`,
+			message:        commitmsg.ParseCommitMessage("API: V2 ENDPOINTS"),
+			wantViolations: 0,
+		},
+		{
+			name: "exceptions - body_line scope checks each line",
+			configYAML: `rules:
+  - name: no-todo
+    type: deny
+    scope: body_line
+    pattern: 'TODO'
+    exceptions:
+      - 'TODO\(#\d+\)'
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature\n\nTODO(#12) follow up\nTODO later\n\nRefs: #1"),
+			wantViolations: 1,
+		},
+		{
+			name: "min_length - title too short",
+			configYAML: `rules:
+  - name: title-min-length
+    type: min_length
+    scope: title
+    limit: 10
+`,
+			message:        commitmsg.ParseCommitMessage("fix"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := "Length of title is 3 characters, minimum is 10"
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "min_length - title long enough",
+			configYAML: `rules:
+  - name: title-min-length
+    type: min_length
+    limit: 10
+`,
+			message:        commitmsg.ParseCommitMessage("fix: handle empty config"),
+			wantViolations: 0,
+		},
+		{
+			name: "min_length - empty scope fails",
+			configYAML: `rules:
+  - name: description-min-length
+    type: min_length
+    scope: cc_description
+    limit: 1
+`,
+			message:        commitmsg.ParseCommitMessage("Update readme"),
+			wantViolations: 1,
+		},
+		{
+			name: "title_and_body scope ignores footer",
+			configYAML: `rules:
+  - name: no-ticket-ids
+    type: deny
+    scope: title_and_body
+    pattern: 'PROJ-\d+'
+`,
+			message: commitmsg.ParsedCommitMessage{
+				Raw:    "Fix login\n\nHandle expired sessions.\n\nRefs: PROJ-123",
+				Title:  "Fix login",
+				Body:   "Handle expired sessions.",
+				Footer: "Refs: PROJ-123",
+			},
+			wantViolations: 0,
+			checkViolation: nil,
+		},
+		{
+			name: "title_and_body scope matches across title and body",
+			configYAML: `rules:
+  - name: title-body-separator
+    type: deny
+    scope: title_and_body
+    pattern: 'login\n\nHandle'
+`,
+			message: commitmsg.ParsedCommitMessage{
+				Raw:    "Fix login\n\nHandle expired sessions.\n\nRefs: PROJ-123",
+				Title:  "Fix login",
+				Body:   "Handle expired sessions.",
+				Footer: "Refs: PROJ-123",
+			},
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				if violations[0].MatchOffset != 4 {
+					t.Errorf("expected match at offset 4 of the joined title and body, got %d", violations[0].MatchOffset)
+				}
+			},
+		},
+		{
+			name: "require_body_when - long title without body",
+			configYAML: `rules:
+  - name: body-for-long-titles
+    type: require_body_when
+    title_pattern: '^.{30,}'
+`,
+			message: commitmsg.ParseCommitMessage(
+				"feat: add caching of parsed configs\n\nSigned-off-by: Dev <dev@example.com>",
+			),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				if !strings.Contains(violations[0].Detail, "but the body is empty") {
+					t.Errorf("expected detail about the empty body, got %q", violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "require_body_when - long title with body paragraph",
+			configYAML: `rules:
+  - name: body-for-long-titles
+    type: require_body_when
+    title_pattern: '^.{30,}'
+`,
+			message: commitmsg.ParseCommitMessage(
+				"feat: add caching of parsed configs\n\nParsing is slow for large configs.",
+			),
+			wantViolations: 0,
+		},
+		{
+			name: "require_body_when - short title without body",
+			configYAML: `rules:
+  - name: body-for-long-titles
+    type: require_body_when
+    title_pattern: '^.{30,}'
+`,
+			message:        commitmsg.ParseCommitMessage("docs: fix typo"),
+			wantViolations: 0,
+		},
+		{
+			name: "imperative_subject - past tense",
+			configYAML: `rules:
+  - name: imperative
+    type: imperative_subject
+`,
+			message:        commitmsg.ParseCommitMessage("Added login form"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `First word "Added" of the title is not in imperative mood`
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "imperative_subject - gerund in Conventional Commits description",
+			configYAML: `rules:
+  - name: imperative
+    type: imperative_subject
+`,
+			message:        commitmsg.ParseCommitMessage("feat(ui): adding login form"),
+			wantViolations: 1,
+		},
+		{
+			name: "imperative_subject - imperative",
+			configYAML: `rules:
+  - name: imperative
+    type: imperative_subject
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add login form"),
+			wantViolations: 0,
+		},
+		{
+			name: "imperative_subject - built-in imperative verb",
+			configYAML: `rules:
+  - name: imperative
+    type: imperative_subject
+`,
+			message:        commitmsg.ParseCommitMessage("Embed version in binary"),
+			wantViolations: 0,
+		},
+		{
+			name: "imperative_subject - allowed word",
+			configYAML: `rules:
+  - name: imperative
+    type: imperative_subject
+    allow: [rendering]
+`,
+			message:        commitmsg.ParseCommitMessage("Rendering: fix flicker"),
+			wantViolations: 0,
+		},
+		{
+			name: "no_trailing_punctuation - trailing period",
+			configYAML: `rules:
+  - name: no-period
+    type: no_trailing_punctuation
+`,
+			message:        commitmsg.ParseCommitMessage("Add login form.  \n\nThe form ends a sentence."),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `Title ends with '.'`
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "no_trailing_punctuation - period in body only",
+			configYAML: `rules:
+  - name: no-period
+    type: no_trailing_punctuation
+`,
+			message:        commitmsg.ParseCommitMessage("Add login form\n\nThe form ends a sentence."),
+			wantViolations: 0,
+		},
+		{
+			name: "no_trailing_punctuation - custom punctuation",
+			configYAML: `rules:
+  - name: no-period
+    type: no_trailing_punctuation
+    punctuation: '.'
+`,
+			message:        commitmsg.ParseCommitMessage("Why does the login form flicker?"),
+			wantViolations: 0,
+		},
+		{
+			name: "require_issue_ref - reference in title",
+			configYAML: `rules:
+  - name: issue-ref
+    type: require_issue_ref
+`,
+			message:        commitmsg.ParseCommitMessage("ABC-123: add login form"),
+			wantViolations: 0,
+		},
+		{
+			name: "require_issue_ref - reference only in body",
+			configYAML: `rules:
+  - name: issue-ref
+    type: require_issue_ref
+    scope: footer
+`,
+			message:        commitmsg.ParseCommitMessage("Add login form\n\nEncode UTF-8 in the form.\n\nReviewed-by: Jane"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `No issue reference matching "[A-Z]+-\\d+" was found in footer`
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "require_issue_ref - reference in footer",
+			configYAML: `rules:
+  - name: issue-ref
+    type: require_issue_ref
+    scope: footer
+`,
+			message:        commitmsg.ParseCommitMessage("Add login form\n\nRefs: ABC-123"),
+			wantViolations: 0,
+		},
+		{
+			name: "require_issue_ref - custom prefix pattern as whole word",
+			configYAML: `rules:
+  - name: issue-ref
+    type: require_issue_ref
+    prefix_pattern: 'GH-\d+'
+`,
+			message:        commitmsg.ParseCommitMessage("Add login form (XGH-12)"),
+			wantViolations: 1,
+		},
+		{
+			name: "dotall - pattern spans body lines",
+			configYAML: `rules:
+  - name: no-debug-block
+    type: deny
+    scope: body
+    pattern: 'BEGIN DEBUG.*END DEBUG'
+    dotall: true
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add feature\n\nBEGIN DEBUG\nfmt.Println(x)\nEND DEBUG\n\nExplain."),
+			wantViolations: 1,
+		},
+		{
+			name: "dotall - dot does not match newlines by default",
+			configYAML: `rules:
+  - name: no-debug-block
+    type: deny
+    scope: body
+    pattern: 'BEGIN DEBUG.*END DEBUG'
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add feature\n\nBEGIN DEBUG\nfmt.Println(x)\nEND DEBUG\n\nExplain."),
+			wantViolations: 0,
+		},
+		{
+			name: "dotall - combined with ignore_case",
+			configYAML: `rules:
+  - name: no-debug-block
+    type: deny
+    scope: message
+    pattern: 'begin debug.*end debug'
+    ignore_case: true
+    dotall: true
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add feature\n\nBegin Debug\nfmt.Println(x)\nEnd Debug"),
+			wantViolations: 1,
+		},
+		{
+			name: "charset - allowed characters",
+			configYAML: `rules:
+  - name: ascii-title
+    type: charset
+    allow: ['[ -~]', '[äöüé]']
+`,
+			message:        commitmsg.ParseCommitMessage("fix: handle café menu\n\nBody with ✨ emoji."),
+			wantViolations: 0,
+		},
+		{
+			name: "charset - first disallowed character",
+			configYAML: `rules:
+  - name: ascii-title
+    type: charset
+    allow: ['[ -~]']
+`,
+			message:        commitmsg.ParseCommitMessage("fix: handle café ✨"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `Character 'é' (U+00E9) at offset 15 of title is not allowed`
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "charset - line breaks in message scope",
+			configYAML: `rules:
+  - name: ascii-message
+    type: charset
+    scope: message
+    allow: ['[ -~]']
+`,
+			message:        commitmsg.ParseCommitMessage("fix: handle menu\n\nLonger\nexplanation."),
+			wantViolations: 0,
+		},
+		{
+			name: "body_wrap - wrapped body",
+			configYAML: `rules:
+  - name: body-wrap
+    type: body_wrap
+    limit: 20
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add a long title\n\nShort lines,\nwrapped.\n\nRefs: #1"),
+			wantViolations: 0,
+		},
+		{
+			name: "body_wrap - first over-long line",
+			configYAML: `rules:
+  - name: body-wrap
+    type: body_wrap
+    limit: 20
+`,
+			message: commitmsg.ParseCommitMessage(
+				"feat: add form\n\nShort line.\nThis line is too long.\nToo long as well, too.\n\nRefs: #1",
+			),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := "Line 4 has 22 characters, maximum is 20"
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "body_wrap - ignored lines",
+			configYAML: `rules:
+  - name: body-wrap
+    type: body_wrap
+    limit: 20
+    ignore_pattern: 'https?://'
+`,
+			message: commitmsg.ParseCommitMessage(
+				"feat: add form\n\nSee https://example.com/a/very/long/path\n\nRefs: #1",
+			),
+			wantViolations: 0,
+		},
+		{
+			name: "body_wrap - footer and trailers are not checked",
+			configYAML: `rules:
+  - name: body-wrap
+    type: body_wrap
+    limit: 20
+`,
+			message: commitmsg.ParseCommitMessage(
+				"feat: add form\n\nShort line.\n\nCo-authored-by: Jane Doe <jane.doe@example.com>",
+			),
+			wantViolations: 0,
+		},
+		{
+			name: "body_wrap - line number counts leading and repeated empty lines",
+			configYAML: `rules:
+  - name: body-wrap
+    type: body_wrap
+    limit: 20
+`,
+			message: commitmsg.ParseCommitMessage(
+				"\nfeat: add form\n\n\nShort line.\n\nThis line is too long.\n\nRefs: #1",
+			),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := "Line 7 has 22 characters, maximum is 20"
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "trailer_block scope - freeform notes do not count",
+			configYAML: `rules:
+  - name: require-trailers
+    type: require
+    scope: trailer_block
+    pattern: '(?m)^Refs:'
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add form\n\nRefs: see the notes below.\nNotes are freeform."),
+			wantViolations: 1,
+		},
+		{
+			name: "footer_text scope - trailers are ignored",
+			configYAML: `rules:
+  - name: no-todo-notes
+    type: deny
+    scope: footer_text
+    pattern: 'TODO'
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add form\n\nFollow-up in a later change.\nRefs: TODO-12"),
+			wantViolations: 0,
+		},
+		{
+			name: "patterns - deny matches any pattern",
+			configYAML: `rules:
+  - name: no-wip
+    type: deny
+    scope: title
+    patterns: ['(?i)\bwip\b', '(?i)\btodo\b', '(?i)\bfixme\b']
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add form, TODO tests"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				if violations[0].MatchedPattern != `(?i)\btodo\b` || violations[0].MatchedText != "TODO" {
+					t.Errorf("expected match of the todo pattern, got %q: %q",
+						violations[0].MatchedPattern, violations[0].MatchedText)
+				}
+			},
+		},
+		{
+			name: "patterns - require needs all patterns by default",
+			configYAML: `rules:
+  - name: ticket-and-signoff
+    type: require
+    scope: message
+    patterns: ['#\d+', 'Signed-off-by:']
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add form\n\nRefs: #12"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				if violations[0].MatchedPattern != "Signed-off-by:" {
+					t.Errorf("expected the missing pattern to be reported, got %q", violations[0].MatchedPattern)
+				}
+			},
+		},
+		{
+			name: "patterns - require with match any",
+			configYAML: `rules:
+  - name: any-ref
+    type: require
+    scope: message
+    patterns: ['#\d+', '[A-Z]+-\d+']
+    match: any
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add form\n\nRefs: ABC-12"),
+			wantViolations: 0,
+		},
+		{
+			name: "patterns - deny with match all",
+			configYAML: `rules:
+  - name: no-wip-fixup
+    type: deny
+    scope: title
+    patterns: ['WIP', 'fixup']
+    match: all
+`,
+			message:        commitmsg.ParseCommitMessage("WIP: debugging"),
+			wantViolations: 0,
+		},
+		{
+			name: "blank_line_after_title - blank line after title",
+			configYAML: `rules:
+  - name: blank-line
+    type: blank_line_after_title
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add form\n\nAdd the login form."),
+			wantViolations: 0,
+		},
+		{
+			name: "blank_line_after_title - title only",
+			configYAML: `rules:
+  - name: blank-line
+    type: blank_line_after_title
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add form\n"),
+			wantViolations: 0,
+		},
+		{
+			name: "blank_line_after_title - body directly after title",
+			configYAML: `rules:
+  - name: blank-line
+    type: blank_line_after_title
+`,
+			message:        commitmsg.ParseCommitMessage("feat: add form\nAdd the login form.\n\nRefs: #42"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `Line 2 must be empty, got "Add the login form."`
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "cc_lowercase - lowercase type",
+			configYAML: `rules:
+  - name: cc-lowercase
+    type: cc_lowercase
+`,
+			message:        commitmsg.ParseCommitMessage("feat(api): add endpoint"),
+			wantViolations: 0,
+		},
+		{
+			name: "cc_lowercase - title not conventional commits shaped",
+			configYAML: `rules:
+  - name: cc-lowercase
+    type: cc_lowercase
+`,
+			message:        commitmsg.ParseCommitMessage("Add endpoint"),
+			wantViolations: 0,
+		},
+		{
+			name: "cc_lowercase - uppercase type",
+			configYAML: `rules:
+  - name: cc-lowercase
+    type: cc_lowercase
+`,
+			message:        commitmsg.ParseCommitMessage("FIX: handle empty input"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `Type "FIX" must be lowercase, e.g. "fix"`
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "max_words - title too long",
+			configYAML: `rules:
+  - name: title-max-words
+    type: max_words
+    limit: 5
+`,
+			message:        commitmsg.ParseCommitMessage("Add a very long title with   many words"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := "Word count of title is 8, maximum is 5"
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "max_words - title within limit",
+			configYAML: `rules:
+  - name: title-max-words
+    type: max_words
+    limit: 5
+`,
+			message:        commitmsg.ParseCommitMessage("Add short title"),
+			wantViolations: 0,
+		},
+		{
+			name: "min_words - body too short",
+			configYAML: `rules:
+  - name: body-min-words
+    type: min_words
+    scope: body
+    limit: 5
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature\n\nSee issue.\n\nRefs: #1"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := "Word count of body is 2, minimum is 5"
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "min_words - missing body is not checked",
+			configYAML: `rules:
+  - name: body-min-words
+    type: min_words
+    scope: body
+    limit: 5
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature"),
+			wantViolations: 0,
+		},
+		{
+			name: "require_trailer - missing trailer",
+			configYAML: `rules:
+  - name: pair-programming
+    type: require_trailer
+    trailer_key: Co-authored-by
+    title_pattern: '\[pair\]'
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature [pair]\n\nSigned-off-by: Jane Doe <jane@example.com>"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `No "Co-authored-by" trailer found`
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "require_trailer - title does not match title_pattern",
+			configYAML: `rules:
+  - name: pair-programming
+    type: require_trailer
+    trailer_key: Co-authored-by
+    title_pattern: '\[pair\]'
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature"),
+			wantViolations: 0,
+		},
+		{
+			name: "require_trailer - value matches value_pattern",
+			configYAML: `rules:
+  - name: pair-programming
+    type: require_trailer
+    trailer_key: Co-authored-by
+    value_pattern: '^.+ <[^@ ]+@[^@ ]+>$'
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature\n\nco-authored-by: Jane Doe <jane@example.com>"),
+			wantViolations: 0,
+		},
+		{
+			name: "require_trailer - value does not match value_pattern",
+			configYAML: `rules:
+  - name: pair-programming
+    type: require_trailer
+    trailer_key: Co-authored-by
+    value_pattern: '^.+ <[^@ ]+@[^@ ]+>$'
+`,
+			message: commitmsg.ParseCommitMessage(
+				"Add feature\n\nCo-authored-by: Jane Doe <jane@example.com>\nCo-authored-by: John",
+			),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `Co-authored-by trailer "John" does not match value_pattern "^.+ <[^@ ]+@[^@ ]+>$"`
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "body_section scope - require rule violated by one paragraph",
+			configYAML: `rules:
+  - name: paragraph-capital
+    type: require
+    scope: body_section
+    pattern: '^[A-Z]'
+`,
+			message: commitmsg.ParseCommitMessage(
+				"Add feature\n\nFirst paragraph.\n\nsecond paragraph.\nstill second.\n\nRefs: #1",
+			),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `Pattern "^[A-Z]" was not found in body section 2: "second paragraph.\nstill second." (require rule)`
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "body_section scope - deny rule passes for all paragraphs",
+			configYAML: `rules:
+  - name: no-lone-todo
+    type: deny
+    scope: body_section
+    pattern: '^TODO$'
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature\n\nFirst.\n\nSecond\nTODO\n\nRefs: #1"),
+			wantViolations: 0,
+		},
+		{
+			name: "max_body_lines - too many lines",
+			configYAML: `rules:
+  - name: body-max-lines
+    type: max_body_lines
+    limit: 2
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature\n\nLine one.\nLine two.\n\nLine three.\n\nRefs: #1"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := "Body has 3 non-empty lines, maximum is 2"
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "max_body_lines - blank lines between paragraphs are not counted",
+			configYAML: `rules:
+  - name: body-max-lines
+    type: max_body_lines
+    limit: 3
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature\n\nLine one.\n\nLine two.\n\nLine three.\n\nRefs: #1"),
+			wantViolations: 0,
+		},
+		{
+			name: "no_trailing_whitespace - trailing whitespace in body",
+			configYAML: `rules:
+  - name: no-trailing-whitespace
+    type: no_trailing_whitespace
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature\n\nFirst line.\nSecond line. \n\nRefs: #1"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `Line 4 ends with whitespace: "Second line. "`
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "no_trailing_whitespace - leading whitespace allowed by default",
+			configYAML: `rules:
+  - name: no-trailing-whitespace
+    type: no_trailing_whitespace
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature\n\n    indented code\n\nRefs: #1"),
+			wantViolations: 0,
+		},
+		{
+			name: "no_trailing_whitespace - leading whitespace forbidden",
+			configYAML: `rules:
+  - name: no-trailing-whitespace
+    type: no_trailing_whitespace
+    forbid_leading_whitespace: true
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature\n\n\tindented\n\nRefs: #1"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+				want := `Line 3 starts with whitespace: "\tindented"`
+				if violations[0].Detail != want {
+					t.Errorf("expected detail %q, got %q", want, violations[0].Detail)
+				}
+			},
+		},
+		{
+			name: "pattern_prefix - anchored pattern tolerates skip ci marker",
+			configYAML: `settings:
+  pattern_prefix: '^(?:\[skip ci\] )?'
+rules:
+  - name: cc-title
+    type: require
+    scope: title
+    pattern: 'feat|fix'
+`,
+			message:        commitmsg.ParseCommitMessage("[skip ci] fix: typo"),
+			wantViolations: 0,
+		},
+		{
+			name: "pattern_prefix - alternation stays anchored",
+			configYAML: `settings:
+  pattern_prefix: '^(?:\[skip ci\] )?'
+rules:
+  - name: cc-title
+    type: require
+    scope: title
+    pattern: 'feat|fix'
+`,
+			message:        commitmsg.ParseCommitMessage("Update docs to fix typo"),
+			wantViolations: 1,
+		},
+		{
+			name: "pattern_suffix - with ignore_case",
+			configYAML: `settings:
+  pattern_suffix: '$'
+rules:
+  - name: no-wip-end
+    type: deny
+    scope: title
+    ignore_case: true
+    pattern: 'wip'
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature WIP"),
+			wantViolations: 1,
+			checkViolation: func(t *testing.T, violations []commitmsg.RuleViolation) {
+				t.Helper()
+
+				// The report shows the configured pattern, not the wrapped one
+				if violations[0].Rule.Pattern != "wip" {
+					t.Errorf("expected configured pattern 'wip', got %q", violations[0].Rule.Pattern)
+				}
+			},
+		},
+		{
+			name: "raw_pattern - opts out of pattern_prefix",
+			configYAML: `settings:
+  pattern_prefix: '^'
+rules:
+  - name: prevent-wip
+    type: deny
+    scope: title
+    raw_pattern: true
+    pattern: 'WIP'
+`,
+			message:        commitmsg.ParseCommitMessage("Add feature WIP"),
+			wantViolations: 1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -417,3 +1691,125 @@ func runEvaluateRulesTest(t *testing.T, tt struct {
 		tt.checkViolation(t, violations)
 	}
 }
+
+func TestEvaluateRulesFailFast(t *testing.T) {
+	rules := createRulesFromYAML(t, `rules:
+  - name: issue-ref
+    type: require
+    scope: message
+    pattern: '#\d+'
+    severity: warning
+  - name: prevent-wip
+    type: deny
+    scope: title
+    pattern: '(?i)wip'
+  - name: conventional-commits
+    type: require
+    scope: title
+    pattern: '^(feat|fix)(\([a-z0-9-]+\))?!?: .+'
+`)
+	message := commitmsg.ParseCommitMessage("WIP debugging")
+
+	violations := commitmsg.EvaluateRules(rules, message)
+	if len(violations) != 3 {
+		t.Errorf("EvaluateRules() returned %d violations, want 3", len(violations))
+	}
+
+	violations = commitmsg.EvaluateRulesFailFast(rules, message)
+	if len(violations) != 2 {
+		t.Fatalf("EvaluateRulesFailFast() returned %d violations, want 2", len(violations))
+	}
+
+	// The warning before the first error is kept, the evaluation stops at the error
+	if violations[0].Rule.Name != "issue-ref" || violations[1].Rule.Name != "prevent-wip" {
+		t.Errorf("EvaluateRulesFailFast() returned violations of rules %q and %q, want issue-ref and prevent-wip",
+			violations[0].Rule.Name, violations[1].Rule.Name)
+	}
+}
+
+func TestEvaluateRulesMatchedText(t *testing.T) {
+	rules := createRulesFromYAML(t, `rules:
+  - name: prevent-wip
+    type: deny
+    scope: title
+    pattern: '(?i)\bwip\b'
+  - name: conventional-commits
+    type: require
+    scope: title
+    pattern: '^(feat|fix)(\([a-z0-9-]+\))?!?: .+'
+`)
+
+	violations := commitmsg.EvaluateRules(rules, commitmsg.ParseCommitMessage("Add login form (WIP)"))
+	if len(violations) != 2 {
+		t.Fatalf("EvaluateRules() returned %d violations, want 2", len(violations))
+	}
+
+	deny := violations[0]
+	if deny.MatchedText != "WIP" || deny.MatchOffset != 16 {
+		t.Errorf("deny violation matched %q at offset %d, want \"WIP\" at offset 16", deny.MatchedText, deny.MatchOffset)
+	}
+
+	require := violations[1]
+	if require.MatchedText != "" || require.MatchOffset != 0 {
+		t.Errorf("require violation matched %q at offset %d, want no match", require.MatchedText, require.MatchOffset)
+	}
+}
+
+func TestLintMessages(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, tmpDir, `rules:
+  - name: issue-ref
+    type: require
+    scope: message
+    pattern: '#\d+'
+    severity: warning
+  - name: prevent-wip
+    type: deny
+    scope: title
+    pattern: '(?i)wip'
+settings:
+  skip_fixup_commits: true
+`)
+
+	config, err := commitmsg.LoadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	messages := []string{
+		"feat: add login form (#12)",
+		"feat: add login form",
+		"WIP: debugging",
+		"fixup! WIP: debugging",
+	}
+
+	results := commitmsg.LintMessages(config, messages)
+	if len(results) != len(messages) {
+		t.Fatalf("LintMessages() returned %d results, want %d", len(results), len(messages))
+	}
+
+	tests := []struct {
+		wantSkipped    bool
+		wantViolations int
+		wantFailed     bool
+	}{
+		{wantSkipped: false, wantViolations: 0, wantFailed: false},
+		{wantSkipped: false, wantViolations: 1, wantFailed: false},
+		{wantSkipped: false, wantViolations: 2, wantFailed: true},
+		{wantSkipped: true, wantViolations: 0, wantFailed: false},
+	}
+
+	for i, tt := range tests {
+		result := results[i]
+
+		if result.Message != messages[i] {
+			t.Errorf("result %d: message = %q, want %q", i, result.Message, messages[i])
+		}
+
+		if result.Skipped != tt.wantSkipped || len(result.Violations) != tt.wantViolations ||
+			result.Failed() != tt.wantFailed {
+			t.Errorf("result %d: skipped = %t, violations = %d, failed = %t, want %t, %d, %t", i,
+				result.Skipped, len(result.Violations), result.Failed(), tt.wantSkipped, tt.wantViolations, tt.wantFailed)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package commitmsg
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// templateHoleRegex matches a template line consisting of a named hole: {{name}}.
+var templateHoleRegex = regexp.MustCompile(`^\{\{\s*([A-Za-z0-9_-]+)\s*\}\}$`)
+
+// templateSection is a header line of a template_match template, optionally
+// followed by a named hole that must be filled with content.
+type templateSection struct {
+	header string
+	hole   string
+}
+
+// parseTemplate parses a template into its sections. Each non-empty line is
+// either a header or a hole, and each hole must directly follow a header.
+func parseTemplate(template string) ([]templateSection, error) {
+	var sections []templateSection
+
+	for line := range strings.SplitSeq(strings.ReplaceAll(template, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		match := templateHoleRegex.FindStringSubmatch(line)
+		if match == nil {
+			sections = append(sections, templateSection{header: line, hole: ""})
+			continue
+		}
+
+		if len(sections) == 0 || sections[len(sections)-1].hole != "" {
+			return nil, fmt.Errorf("hole %q must follow a header line", match[1])
+		}
+
+		sections[len(sections)-1].hole = match[1]
+	}
+
+	if len(sections) == 0 {
+		return nil, errors.New("no header lines")
+	}
+
+	return sections, nil
+}
+
+// checkTemplateMatch checks that the message contains the header lines of the
+// template in order and that every hole of the template is filled, i.e. the
+// lines between its header and the next header (or the end of the message) are
+// not all empty.
+// Returns a description of the violation or an empty string.
+func checkTemplateMatch(rule Rule, message ParsedCommitMessage) string {
+	lines := strings.Split(message.Raw, "\n")
+
+	pos := 0
+	for i, section := range rule.templateSections {
+		headerIdx := indexOfLine(lines, section.header, pos)
+		if headerIdx < 0 {
+			return fmt.Sprintf("Section header %q is missing", section.header)
+		}
+
+		pos = headerIdx + 1
+
+		if section.hole == "" {
+			continue
+		}
+
+		// The hole ends at the next header of the template, if present
+		end := len(lines)
+		if i+1 < len(rule.templateSections) {
+			nextIdx := indexOfLine(lines, rule.templateSections[i+1].header, pos)
+			if nextIdx >= 0 {
+				end = nextIdx
+			}
+		}
+
+		if strings.TrimSpace(strings.Join(lines[pos:end], "\n")) == "" {
+			return fmt.Sprintf("Section %q has no content for {{%s}}", section.header, section.hole)
+		}
+	}
+
+	return ""
+}
+
+// indexOfLine returns the index of the first line at or after start that equals
+// want, ignoring surrounding whitespace, or -1 if there is none.
+func indexOfLine(lines []string, want string, start int) int {
+	for i := start; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == want {
+			return i
+		}
+	}
+
+	return -1
+}